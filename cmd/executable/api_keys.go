@@ -0,0 +1,77 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+const apiKeysUsage = `usage:
+  api-keys create <name> <tier>   create an API key; the key is only printed once
+  api-keys list                   list the API keys
+  api-keys delete <id>            revoke an API key
+  api-keys tiers                  list the tiers`
+
+// runAPIKeys runs the api-keys subcommand, which manages the API keys stored in the cache
+func runAPIKeys(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(apiKeysUsage)
+	}
+
+	s := &service.Service{Cache: newCache()}
+	defer s.Cache.Close()
+
+	switch {
+	case args[0] == "create" && len(args) == 3:
+		apiKey, key, err := s.CreateAPIKey(args[1], args[2])
+		if err != nil {
+			return fmt.Errorf("failed creating the API key: %w", err)
+		}
+		fmt.Fprintf(out, "id:   %s\nname: %s\ntier: %s\nkey:  %s\n", apiKey.ID, apiKey.Name, apiKey.Tier, key)
+		fmt.Fprintln(out, "the key is stored hashed and can't be shown again")
+
+	case args[0] == "list" && len(args) == 1:
+		apiKeys, err := s.ListAPIKeys()
+		if err != nil {
+			return fmt.Errorf("failed listing the API keys: %w", err)
+		}
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tNAME\tTIER\tCREATED AT")
+		for _, apiKey := range apiKeys {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", apiKey.ID, apiKey.Name, apiKey.Tier, apiKey.CreatedAt.Format(time.RFC3339))
+		}
+		return w.Flush()
+
+	case args[0] == "delete" && len(args) == 2:
+		if err := s.DeleteAPIKey(args[1]); err != nil {
+			return fmt.Errorf("failed deleting the API key: %w", err)
+		}
+		fmt.Fprintf(out, "deleted the API key %s\n", args[1])
+
+	case args[0] == "tiers" && len(args) == 1:
+		names := make([]string, 0, len(service.APITiers))
+		for name := range service.APITiers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "TIER\tREQUESTS PER MINUTE\tBATCH SIZE\tFEATURES")
+		for _, name := range names {
+			tier := service.APITiers[name]
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", tier.Name, tier.RequestsPerMinute, tier.BatchMaxItems, strings.Join(tier.Features, ","))
+		}
+		return w.Flush()
+
+	default:
+		return errors.New(apiKeysUsage)
+	}
+
+	return nil
+}
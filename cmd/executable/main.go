@@ -4,16 +4,27 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/go-redis/redis/v8"
 
 	"github.com/silviutroscot/istari-vision/pkg/fetcher"
 	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/notifier"
 	"github.com/silviutroscot/istari-vision/pkg/service"
 	"github.com/silviutroscot/istari-vision/pkg/webservice"
 )
 
 func main() {
+	// the api-keys subcommand manages the API keys of the programmatic clients instead of running the API
+	if len(os.Args) > 1 && os.Args[1] == "api-keys" {
+		if err := runAPIKeys(os.Args[2:], os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	fmt.Print("Hello, world")
 	if err := run(); err != nil {
 		log.Error("runtime error: %s", err.Error())
@@ -28,11 +39,15 @@ func getEnv(key, def string) string {
 	return value
 }
 
-func run() error {
-	cache := redis.NewClient(&redis.Options{
+func newCache() *redis.Client {
+	return redis.NewClient(&redis.Options{
 		Addr: getEnv("REDIS_ADDR", "localhost:6379"),
 		DB:   3, // todo: remove this
 	})
+}
+
+func run() error {
+	cache := newCache()
 
 	s := service.Service{
 		Cache:                       cache,
@@ -41,6 +56,33 @@ func run() error {
 		EgldStakingProvidersFetcher: &fetcher.EgldStakingProvidersElrond{ApiEndpoint: getEnv("FETCHER_ENDPOINT_EGLD_STAKING", fetcher.EgldStakingProvidersEndpoint)},
 	}
 
+	// the optional fetchers are disabled by setting their endpoint to an empty value, e.g. on a network the
+	// protocol is not deployed on
+	if endpoint := getEnv("FETCHER_ENDPOINT_LIQUID_STAKING", fetcher.LiquidStakingHatomEndpoint); endpoint != "" {
+		s.LiquidStakingFetcher = &fetcher.LiquidStakingFetcherHatom{ApiEndpoint: endpoint}
+	}
+	if endpoint := getEnv("FETCHER_ENDPOINT_WALLET", fetcher.WalletMultiversXEndpoint); endpoint != "" {
+		s.WalletFetcher = &fetcher.WalletFetcherMultiversX{ApiEndpoint: endpoint}
+	}
+
+	s.PublicURL = getEnv("PUBLIC_URL", "")
+	// the weekly digests are only sent if an SMTP server is configured
+	if smtpAddress := getEnv("SMTP_ADDR", ""); smtpAddress != "" {
+		s.Notifier = &notifier.SMTPNotifier{
+			Address:  smtpAddress,
+			From:     getEnv("SMTP_FROM", "digest@istari.vision"),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+		}
+	}
+
+	// HISTORY_CSV_IMPORT is the path of a dataset of historical prices and APRs to be imported for the backtests
+	if path := getEnv("HISTORY_CSV_IMPORT", ""); path != "" {
+		if err := importHistory(&s, path); err != nil {
+			return fmt.Errorf("failed importing the history: %w", err)
+		}
+	}
+
 	if getEnv("CACHE_WARMUP", "") == "1" {
 		if err := s.CacheWarmup(); err != nil {
 			return err
@@ -49,6 +91,10 @@ func run() error {
 
 	api := webservice.NewAPI(&s)
 	api.Address = os.Getenv("API_ADDRESS")
+	// TRUSTED_PROXIES are the comma separated addresses or CIDRs of the reverse proxies forwarding the client IPs
+	if trustedProxies := getEnv("TRUSTED_PROXIES", ""); trustedProxies != "" {
+		api.TrustedProxies = strings.Split(trustedProxies, ",")
+	}
 
 	if err := api.Setup(); err != nil {
 		return fmt.Errorf("failed api setup: %w", err)
@@ -59,5 +105,26 @@ func run() error {
 		panic("cron stopped")
 	}()
 
+	if s.Notifier != nil {
+		go func() {
+			s.DigestCron(context.Background())
+			panic("digest cron stopped")
+		}()
+	}
+
 	return api.Run()
-}
\ No newline at end of file
+}
+func importHistory(s *service.Service, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	days, err := service.LoadHistoryCSV(file)
+	if err != nil {
+		return err
+	}
+
+	return s.ImportHistory(days)
+}
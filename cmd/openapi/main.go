@@ -0,0 +1,19 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/webservice"
+)
+
+// openapi writes the OpenAPI specification of the API to stdout; `make openapi` stores it in docs/openapi.json
+func main() {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(webservice.OpenAPI()); err != nil {
+		log.Error("error writing the OpenAPI specification: %s", err)
+		os.Exit(1)
+	}
+}
@@ -22,6 +22,15 @@ export interface CalculateResponse {
         [key: string]: StrategyResult;
     };
 }
+export interface MarketUpdate {
+    prices: Prices;
+    mex_locked_rewards_apr: string;
+    mex_unlocked_rewards_apr: string;
+    staking_providers: {
+        [identity: string]: number;
+    };
+    updated_at: string;
+}
 export interface AppLogicState {
     stakeProviders: StakeProvider[];
     prices: Prices;
@@ -38,6 +47,10 @@ export declare class AppLogic extends HTMLElement {
     displayResult(name: string, result?: StrategyResult): void;
     fetchStakingProviders(): void;
     fetchPrices(): void;
+    subscribeMarketUpdates(): void;
+    updateStakingProvidersAPR(aprs: {
+        [identity: string]: number;
+    }): void;
     fetchSubmit(request: {
         [key: string]: any;
     }): void;
@@ -82,6 +82,30 @@ export class AppLogic extends HTMLElement {
                 console.error(err);
             });
     }
+    subscribeMarketUpdates() {
+        // the prices and APRs are pushed after each refresh of the API cache, instead of being polled
+        const events = new EventSource("https://istari-api.troscot.com/api/live");
+        events.addEventListener('market', e => {
+            const update = JSON.parse(e.data);
+            this._state.prices = update.prices;
+            this.updatePrices();
+            this.updateStakingProvidersAPR(update.staking_providers);
+        });
+    }
+    updateStakingProvidersAPR(aprs) {
+        const selectProviderEl = document.querySelector('select[name="egld-staking-provider"]');
+        this._state.stakeProviders.forEach(provider => {
+            if (aprs[provider.identity] !== undefined) {
+                provider.apr = aprs[provider.identity];
+            }
+        });
+        Array.from(selectProviderEl.options).forEach(option => {
+            const apr = aprs[option.value];
+            if (apr !== undefined) {
+                option.innerText = `apr[${apr}] - ${option.value}`;
+            }
+        });
+    }
     fetchSubmit(request) {
         const json = JSON.stringify(request);
         fetch("https://istari-api.troscot.com/api/calculate_profit", { method: "POST", body: json })
@@ -185,6 +209,7 @@ export class AppLogic extends HTMLElement {
         console.debug('connected', this._state);
         this.fetchPrices();
         this.fetchStakingProviders();
+        this.subscribeMarketUpdates();
         const formEl = document.querySelector('form');
         formEl.addEventListener('submit', (e) => {
             e.preventDefault();
@@ -0,0 +1,53 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+// Format is a file format the results can be exported to
+type Format string
+
+const (
+	FormatCSV  Format = "csv"
+	FormatXLSX Format = "xlsx"
+	FormatPDF  Format = "pdf"
+)
+
+// ContentTypes maps the formats to their MIME types
+var ContentTypes = map[Format]string{
+	FormatCSV:  "text/csv",
+	FormatXLSX: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	FormatPDF:  "application/pdf",
+}
+
+// Table is a named table of values, exported as a sheet of a workbook or a section of a document
+type Table struct {
+	Name   string
+	Header []string
+	Rows   [][]string
+}
+
+// WriteCSV writes the tables one after the other, each preceded by its name and followed by an empty line
+func WriteCSV(w io.Writer, tables []Table) error {
+	writer := csv.NewWriter(w)
+	for i, table := range tables {
+		if i > 0 {
+			if err := writer.Write([]string{}); err != nil {
+				return err
+			}
+		}
+		if err := writer.Write([]string{table.Name}); err != nil {
+			return err
+		}
+		if err := writer.Write(table.Header); err != nil {
+			return err
+		}
+		if err := writer.WriteAll(table.Rows); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
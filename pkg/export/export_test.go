@@ -0,0 +1,95 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testTables = []Table{
+	{Name: "Inputs", Header: []string{"Field", "Value"}, Rows: [][]string{{"egld-tokens-invested", "10"}, {"egld-staking-provider", "istari & co"}}},
+	{Name: "Results", Header: []string{"Strategy", "TotalBalanceInUsd"}, Rows: [][]string{{"egld_stake", "2800.0000000000"}}},
+}
+
+func TestWriteCSV(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteCSV(&buffer, testTables))
+
+	assert.Equal(t, "Inputs\nField,Value\negld-tokens-invested,10\negld-staking-provider,istari & co\n\n"+
+		"Results\nStrategy,TotalBalanceInUsd\negld_stake,2800.0000000000\n", buffer.String())
+}
+
+func TestWriteXLSX(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+	require.NoError(t, WriteXLSX(&buffer, testTables))
+
+	archive, err := zip.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	require.NoError(t, err)
+
+	files := make(map[string]string)
+	for _, file := range archive.File {
+		reader, err := file.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		files[file.Name] = string(content)
+	}
+
+	require.Contains(t, files, "[Content_Types].xml")
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Inputs" sheetId="1" r:id="rId1"/>`)
+	assert.Contains(t, files["xl/workbook.xml"], `<sheet name="Results" sheetId="2" r:id="rId2"/>`)
+
+	// the text is escaped and the numbers are numeric cells
+	assert.Contains(t, files["xl/worksheets/sheet1.xml"], `<c r="B3" t="inlineStr"><is><t>istari &amp; co</t></is></c>`)
+	assert.Contains(t, files["xl/worksheets/sheet2.xml"], `<c r="B2"><v>2800.0000000000</v></c>`)
+}
+
+func Test_columnName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "A", columnName(0))
+	assert.Equal(t, "Z", columnName(25))
+	assert.Equal(t, "AA", columnName(26))
+	assert.Equal(t, "BA", columnName(52))
+}
+
+func TestWritePDF(t *testing.T) {
+	t.Parallel()
+
+	// enough rows to need a second page
+	tables := append([]Table{}, testTables...)
+	long := Table{Name: "Timeline (daily)", Header: []string{"Day", "Balance"}}
+	for i := 0; i < 60; i++ {
+		long.Rows = append(long.Rows, []string{strconv.Itoa(i), "100"})
+	}
+	tables = append(tables, long)
+
+	var buffer bytes.Buffer
+	require.NoError(t, WritePDF(&buffer, "Istari Vision report", tables))
+	document := buffer.String()
+
+	assert.True(t, strings.HasPrefix(document, "%PDF-1.4\n"))
+	assert.True(t, strings.HasSuffix(document, "%%EOF\n"))
+	assert.Contains(t, document, "/Count 2")
+	assert.Contains(t, document, `(Timeline \(daily\)) Tj`)
+
+	// every offset of the cross-reference table points to its object
+	xref := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(document, -1)
+	require.NotEmpty(t, xref)
+	for i, match := range xref {
+		offset, _ := strconv.Atoi(match[1])
+		assert.True(t, strings.HasPrefix(document[offset:], fmt.Sprintf("%d 0 obj", i+1)), "object %d", i+1)
+	}
+}
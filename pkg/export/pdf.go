@@ -0,0 +1,134 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	// the pages are A4 landscape, in points
+	pdfPageWidth  = 842
+	pdfPageHeight = 595
+	pdfMargin     = 40
+
+	pdfTitleFontSize   = 16
+	pdfHeadingFontSize = 12
+	pdfFontSize        = 8
+	pdfLineHeight      = 13
+	// pdfCharWidth is the average width of a character of Helvetica, relative to the font size
+	pdfCharWidth = 0.55
+)
+
+// WritePDF writes a document with the title followed by the tables, on as many pages as needed
+func WritePDF(w io.Writer, title string, tables []Table) error {
+	var pages []*bytes.Buffer
+	var page *bytes.Buffer
+	y := 0.0
+
+	newPage := func() {
+		page = &bytes.Buffer{}
+		pages = append(pages, page)
+		y = pdfPageHeight - pdfMargin
+	}
+	text := func(font string, size float64, x float64, value string) {
+		fmt.Fprintf(page, "BT /%s %.0f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfEscape(value))
+	}
+	line := func(height float64) {
+		y -= height
+		if y < pdfMargin {
+			newPage()
+			y -= height
+		}
+	}
+
+	newPage()
+	line(pdfTitleFontSize)
+	text("F2", pdfTitleFontSize, pdfMargin, title)
+	line(pdfLineHeight)
+
+	for _, table := range tables {
+		line(pdfLineHeight * 1.5)
+		text("F2", pdfHeadingFontSize, pdfMargin, table.Name)
+
+		if len(table.Header) == 0 {
+			continue
+		}
+		columnWidth := float64(pdfPageWidth-2*pdfMargin) / float64(len(table.Header))
+		maxChars := int(columnWidth/(pdfFontSize*pdfCharWidth)) - 1
+
+		for i, row := range append([][]string{table.Header}, table.Rows...) {
+			line(pdfLineHeight)
+			font := "F1"
+			if i == 0 {
+				font = "F2"
+			}
+			for j, value := range row {
+				if len(value) > maxChars && maxChars > 1 {
+					value = value[:maxChars-1] + "~"
+				}
+				text(font, pdfFontSize, pdfMargin+float64(j)*columnWidth, value)
+			}
+		}
+	}
+
+	return writePDFObjects(w, pages)
+}
+
+// writePDFObjects writes the catalog, the fonts and the pages with their content streams, followed by the
+// cross-reference table
+func writePDFObjects(w io.Writer, pages []*bytes.Buffer) error {
+	var document bytes.Buffer
+	var offsets []int
+
+	object := func(content string) {
+		offsets = append(offsets, document.Len())
+		fmt.Fprintf(&document, "%d 0 obj\n%s\nendobj\n", len(offsets), content)
+	}
+
+	// the objects 1 to 4 are the catalog, the pages tree and the fonts; each page is followed by its content
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+
+	document.WriteString("%PDF-1.4\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	for i, page := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 6+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", page.Len(), page.String()))
+	}
+
+	xrefOffset := document.Len()
+	fmt.Fprintf(&document, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&document, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&document, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xrefOffset)
+
+	_, err := w.Write(document.Bytes())
+	return err
+}
+
+// pdfEscape returns the value as the content of a PDF literal string; the characters outside of ASCII are replaced,
+// as the standard fonts are not embedded
+func pdfEscape(value string) string {
+	var escaped strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			escaped.WriteRune('\\')
+			escaped.WriteRune(r)
+		case r < 32 || r > 126:
+			escaped.WriteRune('?')
+		default:
+			escaped.WriteRune(r)
+		}
+	}
+	return escaped.String()
+}
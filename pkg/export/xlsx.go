@@ -0,0 +1,135 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>
+%s</Types>`
+	xlsxSheetContentType = `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>
+`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets>
+%s</sheets>
+</workbook>`
+	xlsxWorkbookSheet = `<sheet name="%s" sheetId="%d" r:id="rId%d"/>
+`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+%s</Relationships>`
+	xlsxWorkbookSheetRel = `<Relationship Id="rId%d" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet%d.xml"/>
+`
+
+	// xlsxMaxSheetNameLength is the longest sheet name accepted by the spreadsheet applications
+	xlsxMaxSheetNameLength = 31
+)
+
+// WriteXLSX writes the tables as the sheets of a workbook; the values which are numbers are written as numeric cells
+func WriteXLSX(w io.Writer, tables []Table) error {
+	archive := zip.NewWriter(w)
+
+	var contentTypes, sheets, sheetRels strings.Builder
+	for i, table := range tables {
+		fmt.Fprintf(&contentTypes, xlsxSheetContentType, i+1)
+		fmt.Fprintf(&sheets, xlsxWorkbookSheet, xmlEscape(sheetName(table.Name)), i+1, i+1)
+		fmt.Fprintf(&sheetRels, xlsxWorkbookSheetRel, i+1, i+1)
+	}
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"[Content_Types].xml", fmt.Sprintf(xlsxContentTypes, contentTypes.String())},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, sheets.String())},
+		{"xl/_rels/workbook.xml.rels", fmt.Sprintf(xlsxWorkbookRels, sheetRels.String())},
+	}
+	for i, table := range tables {
+		files = append(files, struct {
+			name    string
+			content string
+		}{fmt.Sprintf("xl/worksheets/sheet%d.xml", i+1), worksheet(table)})
+	}
+
+	for _, file := range files {
+		writer, err := archive.Create(file.name)
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(writer, file.content); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
+
+// worksheet returns the XML of the sheet of the table, with the header as the first row
+func worksheet(table Table) string {
+	var sheet strings.Builder
+	sheet.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>` + "\n")
+	sheet.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+
+	rows := append([][]string{table.Header}, table.Rows...)
+	for i, row := range rows {
+		fmt.Fprintf(&sheet, `<row r="%d">`, i+1)
+		for j, value := range row {
+			reference := columnName(j) + strconv.Itoa(i+1)
+			// the header is always text, so numeric column names are not converted
+			if _, err := strconv.ParseFloat(value, 64); err == nil && i > 0 {
+				fmt.Fprintf(&sheet, `<c r="%s"><v>%s</v></c>`, reference, value)
+			} else {
+				fmt.Fprintf(&sheet, `<c r="%s" t="inlineStr"><is><t>%s</t></is></c>`, reference, xmlEscape(value))
+			}
+		}
+		sheet.WriteString(`</row>`)
+	}
+
+	sheet.WriteString(`</sheetData></worksheet>`)
+	return sheet.String()
+}
+
+// columnName returns the letters of the column with the given index, starting at 0 for A
+func columnName(index int) string {
+	name := ""
+	for index >= 0 {
+		name = string(rune('A'+index%26)) + name
+		index = index/26 - 1
+	}
+	return name
+}
+
+// sheetName returns the name without the characters forbidden in the sheet names, truncated to the maximum length
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`\/?*[]:`, r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if len(name) > xlsxMaxSheetNameLength {
+		name = name[:xlsxMaxSheetNameLength]
+	}
+	return name
+}
+
+func xmlEscape(value string) string {
+	var escaped strings.Builder
+	_ = xml.EscapeText(&escaped, []byte(value))
+	return escaped.String()
+}
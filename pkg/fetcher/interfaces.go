@@ -20,3 +20,13 @@ type EgldStakingProvidersFetcher interface {
 type MexEconomicsFetcher interface {
 	FetchMexEconomics() (economics MexEconomics, err error)
 }
+
+// LiquidStakingFetcher retrieves the exchange rate and APR of a liquid staking protocol
+type LiquidStakingFetcher interface {
+	FetchLiquidStaking() (LiquidStaking, error)
+}
+
+// WalletFetcher retrieves the balances and the delegations of an account
+type WalletFetcher interface {
+	FetchWallet(address string) (Wallet, error)
+}
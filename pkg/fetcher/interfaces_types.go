@@ -6,10 +6,128 @@ type EgldStakingProvider struct {
 	ServiceFee float64 `json:"serviceFee"`
 	APR        float64 `json:"apr"`
 	Identity   string  `json:"identity"`
+	// Address is the address of the delegation contract of the staking provider
+	Address string `json:"provider"`
 }
 
 type MexEconomics struct {
 	LockedRewardsAPR   *big.Float
 	UnlockedRewardsAPR *big.Float
 	Price              *big.Float
+
+	// Farms contains the APRs of all the farms available on the exchange, including the MEX staking one
+	Farms []MexFarm
+	// Pools contains the reserves of the liquidity pools available on the exchange
+	Pools []LiquidityPool
+	// MetastakingFarms contains the metastaking contracts which stake the LP farm tokens a second time
+	MetastakingFarms []MetastakingFarm
+}
+
+// LiquidStaking represents the state of a liquid staking protocol, which delegates EGLD and issues a liquid token whose
+// value in EGLD grows with the delegation rewards
+type LiquidStaking struct {
+	// Token is the identifier of the liquid token (e.g. SEGLD-3ad2d0)
+	Token string
+	// ExchangeRate is the amount of EGLD one liquid token can be redeemed for
+	ExchangeRate *big.Float
+	// APR is the percentage the exchange rate grows with in a year, after the protocol fees
+	APR *big.Float
+}
+
+// MexFarm represents a farm where the FarmingToken is staked to receive MEX rewards
+type MexFarm struct {
+	Address string
+	// FarmTokenName is the name of the token received when entering the farm (e.g. EGLDMEXLPStaked)
+	FarmTokenName string
+	// FarmingTokenIdentifier is the identifier of the token staked in the farm (e.g. the LP token EGLDMEX-1331c2)
+	FarmingTokenIdentifier string
+	// LockedRewardsAPR and UnlockedRewardsAPR are percentages
+	LockedRewardsAPR   *big.Float
+	UnlockedRewardsAPR *big.Float
+}
+
+// LiquidityPool represents a constant product pool of two tokens
+type LiquidityPool struct {
+	Address string
+	// LPTokenIdentifier is the identifier of the token received for providing liquidity to the pool
+	LPTokenIdentifier     string
+	FirstTokenIdentifier  string
+	SecondTokenIdentifier string
+	// FirstTokenReserve and SecondTokenReserve are the amounts of tokens in the pool, with the decimals applied
+	FirstTokenReserve  *big.Float
+	SecondTokenReserve *big.Float
+	// FeePercent is the percentage of each swap paid as fee to the pool (e.g. 0.3)
+	FeePercent *big.Float
+}
+
+// MetastakingFarm represents a contract where the LP farm tokens of an EGLD paired pool are staked, so that the EGLD
+// part of the liquidity is delegated and earns the delegation rewards on top of the farm rewards
+type MetastakingFarm struct {
+	Address string
+	// PairAddress is the address of the liquidity pool whose LP farm tokens are accepted
+	PairAddress string
+	// LPFarmAddress is the address of the farm where the LP tokens are staked
+	LPFarmAddress string
+	// DualYieldTokenIdentifier is the identifier of the token received for metastaking
+	DualYieldTokenIdentifier string
+	// UnbondingEpochs is the number of epochs (days) the tokens are locked for after exiting metastaking
+	UnbondingEpochs int
+}
+
+// FarmByName returns the farm with the given farm token name
+func (e *MexEconomics) FarmByName(farmTokenName string) (MexFarm, bool) {
+	for _, farm := range e.Farms {
+		if farm.FarmTokenName == farmTokenName {
+			return farm, true
+		}
+	}
+	return MexFarm{}, false
+}
+
+// PoolByLPToken returns the liquidity pool which issues the given LP token
+func (e *MexEconomics) PoolByLPToken(lpTokenIdentifier string) (LiquidityPool, bool) {
+	for _, pool := range e.Pools {
+		if pool.LPTokenIdentifier == lpTokenIdentifier {
+			return pool, true
+		}
+	}
+	return LiquidityPool{}, false
+}
+
+// MetastakingFarmFor returns the metastaking contract for the given liquidity pool and farm addresses
+func (e *MexEconomics) MetastakingFarmFor(pairAddress, lpFarmAddress string) (MetastakingFarm, bool) {
+	for _, metastakingFarm := range e.MetastakingFarms {
+		if metastakingFarm.PairAddress == pairAddress && metastakingFarm.LPFarmAddress == lpFarmAddress {
+			return metastakingFarm, true
+		}
+	}
+	return MetastakingFarm{}, false
+}
+
+// Wallet represents the holdings of an account
+type Wallet struct {
+	Address     string
+	EgldBalance *big.Float
+	// Tokens contains the ESDT balances, and the Meta ESDT balances (e.g. LKMEX) summed by collection
+	Tokens      []WalletToken
+	Delegations []Delegation
+}
+
+// WalletToken represents the balance of a token held by an account
+type WalletToken struct {
+	// Identifier is the identifier of the token, or the collection of the Meta ESDTs (e.g. LKMEX-aab910)
+	Identifier string
+	Balance    *big.Float
+	// ValueInUsd is nil if the API doesn't know the price of the token
+	ValueInUsd *big.Float
+}
+
+// Delegation represents the EGLD delegated by an account to a staking provider
+type Delegation struct {
+	// Contract is the address of the delegation contract of the staking provider
+	Contract         string
+	ActiveStake      *big.Float
+	ClaimableRewards *big.Float
+	// Unbonding is the EGLD undelegated, either still unbonding or ready to be withdrawn
+	Unbonding *big.Float
 }
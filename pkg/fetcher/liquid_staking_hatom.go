@@ -0,0 +1,89 @@
+package fetcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+// LiquidStakingFetcherHatom retrieves the state of the Hatom liquid staking protocol, which issues sEGLD for the
+// delegated EGLD
+type LiquidStakingFetcherHatom struct {
+	ApiEndpoint string
+}
+
+const liquidStakingHatomQuery = `{
+  "query": "query {liquidStaking {token exchangeRate apr}}",
+  "variables": {}
+}`
+
+func (lf *LiquidStakingFetcherHatom) FetchLiquidStaking() (LiquidStaking, error) {
+	liquidStaking := LiquidStaking{
+		ExchangeRate: new(big.Float),
+		APR:          new(big.Float),
+	}
+
+	req, err := http.NewRequest(http.MethodPost, lf.ApiEndpoint, strings.NewReader(liquidStakingHatomQuery))
+	if err != nil {
+		log.Error("error creating the request for liquid staking to endpoint %s: %s", lf.ApiEndpoint, err)
+		return liquidStaking, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		log.Error("error sending the POST request for liquid staking to endpoint %s: %s", lf.ApiEndpoint, err)
+		return liquidStaking, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("error in the liquid staking response from endpoint %s: response status code %d",
+			lf.ApiEndpoint, res.StatusCode)
+		log.Error("%s", err)
+		return liquidStaking, err
+	}
+
+	var response struct {
+		Data struct {
+			LiquidStaking struct {
+				Token        string `json:"token"`
+				ExchangeRate string `json:"exchangeRate"`
+				APR          string `json:"apr"`
+			} `json:"liquidStaking"`
+		} `json:"data"`
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		log.Error("error decoding the liquid staking JSON response: %s", err)
+		return liquidStaking, err
+	}
+
+	liquidStaking.Token = response.Data.LiquidStaking.Token
+	if liquidStaking.Token == "" {
+		err = fmt.Errorf("no liquid staking token in the response from endpoint %s", lf.ApiEndpoint)
+		log.Error("%s", err)
+		return liquidStaking, err
+	}
+
+	_, _, err = liquidStaking.ExchangeRate.Parse(response.Data.LiquidStaking.ExchangeRate, 10)
+	if err != nil {
+		log.Error("error parsing the liquid staking exchange rate: %s", err)
+		return liquidStaking, err
+	}
+
+	_, _, err = liquidStaking.APR.Parse(response.Data.LiquidStaking.APR, 10)
+	if err != nil {
+		log.Error("error parsing the liquid staking APR: %s", err)
+		return liquidStaking, err
+	}
+	// multiply the APR by 100 as it is not percentage
+	liquidStaking.APR.Mul(liquidStaking.APR, BigFloatOneHundred)
+
+	return liquidStaking, nil
+}
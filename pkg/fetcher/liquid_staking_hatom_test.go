@@ -0,0 +1,96 @@
+package fetcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_LiquidStakingFetcherHatom_FetchLiquidStaking(t *testing.T) {
+	t.Parallel()
+
+	t.Run("offline", func(t *testing.T) {
+		mockHandlerLogic := &mockHandler{
+			responseFunc: func(r *http.Request) ([]byte, int, error) {
+				if r.URL.Query().Get("empty") != "" {
+					return []byte(`{"data": {"liquidStaking": null}}`), http.StatusOK, nil
+				}
+
+				mockResponse := `{
+    "data": {
+        "liquidStaking": {
+            "token": "SEGLD-3ad2d0",
+            "exchangeRate": "1.0423711548",
+            "apr": "0.0815"
+        }
+    }
+}`
+				return []byte(mockResponse), http.StatusOK, nil
+			},
+		}
+		handler := http.NewServeMux()
+		handler.Handle("/graphql", mockHandlerLogic)
+
+		t.Run("ok", func(t *testing.T) {
+			server := httptest.NewUnstartedServer(handler)
+			server.Start()
+			defer server.Close()
+
+			fetcher := LiquidStakingFetcherHatom{
+				ApiEndpoint: server.URL + "/graphql",
+			}
+			liquidStaking, err := fetcher.FetchLiquidStaking()
+			require.NoError(t, err)
+
+			assert.Equal(t, "SEGLD-3ad2d0", liquidStaking.Token)
+			assert.Equal(t, "1.042371155", liquidStaking.ExchangeRate.String())
+			assert.Equal(t, "8.15", liquidStaking.APR.Text('f', 2))
+		})
+
+		t.Run("err_empty", func(t *testing.T) {
+			server := httptest.NewUnstartedServer(handler)
+			server.Start()
+			defer server.Close()
+
+			fetcher := LiquidStakingFetcherHatom{
+				ApiEndpoint: server.URL + "/graphql?empty=1",
+			}
+
+			_, err := fetcher.FetchLiquidStaking()
+			require.Error(t, err)
+			assert.Containsf(t, err.Error(), "no liquid staking token", "expected missing token error")
+		})
+
+		t.Run("err_response", func(t *testing.T) {
+			server := httptest.NewUnstartedServer(handler)
+			server.Start()
+			defer server.Close()
+
+			fetcher := LiquidStakingFetcherHatom{
+				ApiEndpoint: server.URL + "/graphql?errorCode=400",
+			}
+
+			_, err := fetcher.FetchLiquidStaking()
+			require.Error(t, err)
+			assert.Containsf(t, err.Error(), "response status code 400", "expected status code 400 error")
+		})
+
+		t.Run("err_conn", func(t *testing.T) {
+			server := httptest.NewUnstartedServer(handler)
+			server.Start()
+
+			fetcher := LiquidStakingFetcherHatom{
+				ApiEndpoint: server.URL + "/graphql",
+			}
+
+			server.Close()
+			_, err := fetcher.FetchLiquidStaking()
+
+			require.Error(t, err)
+			assert.Containsf(t, err.Error(), "connect: connection refused", "expected connection error")
+		})
+	})
+}
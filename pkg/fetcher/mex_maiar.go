@@ -16,7 +16,7 @@ type MexEconomicsFetcherMaiar struct {
 }
 
 const mexEconomicsMaiarQuery = `{
-  "query": "query {farms {lockedRewardsAPR unlockedRewardsAPR farmingToken{identifier name} farmToken{name} farmedTokenPriceUSD farmedToken {identifier name}}}",
+  "query": "query {farms {address lockedRewardsAPR unlockedRewardsAPR farmingToken{identifier name} farmToken{name} farmedTokenPriceUSD farmedToken {identifier name}} pairs {address firstToken{identifier decimals} secondToken{identifier decimals} liquidityPoolToken{identifier} info{reserves0 reserves1} totalFeePercent} stakingProxies {address pairAddress lpFarmAddress stakingMinUnboundEpochs dualYieldToken{identifier}}}",
   "variables": {}
 }`
 
@@ -59,6 +59,7 @@ func (mf *MexEconomicsFetcherMaiar) FetchMexEconomics() (MexEconomics, error) {
 	var response struct {
 		Data struct {
 			Farms []struct {
+				Address            string `json:"address"`
 				LockedRewardsAPR   string `json:"lockedRewardsAPR"`
 				UnlockedRewardsAPR string `json:"unlockedRewardsAPR"`
 				FarmingToken       struct {
@@ -74,6 +75,34 @@ func (mf *MexEconomicsFetcherMaiar) FetchMexEconomics() (MexEconomics, error) {
 					Name       string `json:"name"`
 				} `json:"farmedToken"`
 			} `json:"farms"`
+			Pairs []struct {
+				Address    string `json:"address"`
+				FirstToken struct {
+					Identifier string `json:"identifier"`
+					Decimals   int    `json:"decimals"`
+				} `json:"firstToken"`
+				SecondToken struct {
+					Identifier string `json:"identifier"`
+					Decimals   int    `json:"decimals"`
+				} `json:"secondToken"`
+				LiquidityPoolToken struct {
+					Identifier string `json:"identifier"`
+				} `json:"liquidityPoolToken"`
+				Info struct {
+					Reserves0 string `json:"reserves0"`
+					Reserves1 string `json:"reserves1"`
+				} `json:"info"`
+				TotalFeePercent float64 `json:"totalFeePercent"`
+			} `json:"pairs"`
+			StakingProxies []struct {
+				Address                 string `json:"address"`
+				PairAddress             string `json:"pairAddress"`
+				LpFarmAddress           string `json:"lpFarmAddress"`
+				StakingMinUnboundEpochs int    `json:"stakingMinUnboundEpochs"`
+				DualYieldToken          struct {
+					Identifier string `json:"identifier"`
+				} `json:"dualYieldToken"`
+			} `json:"stakingProxies"`
 		} `json:"data"`
 	}
 
@@ -89,6 +118,21 @@ func (mf *MexEconomicsFetcherMaiar) FetchMexEconomics() (MexEconomics, error) {
 	}
 
 	for _, farm := range response.Data.Farms {
+		// keep the APRs of every farm so the liquidity pools farming strategies can use them
+		lockedAPR, _, lockedErr := big.ParseFloat(farm.LockedRewardsAPR, 10, 0, big.ToNearestEven)
+		unlockedAPR, _, unlockedErr := big.ParseFloat(farm.UnlockedRewardsAPR, 10, 0, big.ToNearestEven)
+		if lockedErr == nil && unlockedErr == nil {
+			economics.Farms = append(economics.Farms, MexFarm{
+				Address:                farm.Address,
+				FarmTokenName:          farm.FarmToken.Name,
+				FarmingTokenIdentifier: farm.FarmingToken.Identifier,
+				LockedRewardsAPR:       lockedAPR.Mul(lockedAPR, BigFloatOneHundred),
+				UnlockedRewardsAPR:     unlockedAPR.Mul(unlockedAPR, BigFloatOneHundred),
+			})
+		} else {
+			log.Debug("skipping the APRs of farm %s which could not be parsed", farm.FarmToken.Name)
+		}
+
 		if farm.FarmToken.Name == tokenName {
 			_, _, err = economics.Price.Parse(farm.FarmedTokenPriceUSD, 10)
 			if err != nil {
@@ -113,5 +157,40 @@ func (mf *MexEconomicsFetcherMaiar) FetchMexEconomics() (MexEconomics, error) {
 		}
 	}
 
+	for _, pair := range response.Data.Pairs {
+		firstReserve, err := parseTokenAmount(pair.Info.Reserves0, pair.FirstToken.Decimals)
+		if err != nil {
+			log.Debug("skipping the liquidity pool %s with invalid reserves: %s", pair.Address, err)
+			continue
+		}
+
+		secondReserve, err := parseTokenAmount(pair.Info.Reserves1, pair.SecondToken.Decimals)
+		if err != nil {
+			log.Debug("skipping the liquidity pool %s with invalid reserves: %s", pair.Address, err)
+			continue
+		}
+
+		economics.Pools = append(economics.Pools, LiquidityPool{
+			Address:               pair.Address,
+			LPTokenIdentifier:     pair.LiquidityPoolToken.Identifier,
+			FirstTokenIdentifier:  pair.FirstToken.Identifier,
+			SecondTokenIdentifier: pair.SecondToken.Identifier,
+			FirstTokenReserve:     firstReserve,
+			SecondTokenReserve:    secondReserve,
+			// the API returns the fee as a fraction (e.g. 0.003)
+			FeePercent: new(big.Float).Mul(big.NewFloat(pair.TotalFeePercent), BigFloatOneHundred),
+		})
+	}
+
+	for _, proxy := range response.Data.StakingProxies {
+		economics.MetastakingFarms = append(economics.MetastakingFarms, MetastakingFarm{
+			Address:                  proxy.Address,
+			PairAddress:              proxy.PairAddress,
+			LPFarmAddress:            proxy.LpFarmAddress,
+			DualYieldTokenIdentifier: proxy.DualYieldToken.Identifier,
+			UnbondingEpochs:          proxy.StakingMinUnboundEpochs,
+		})
+	}
+
 	return economics, nil
 }
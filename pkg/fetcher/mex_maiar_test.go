@@ -42,6 +42,7 @@ func Test_MexEconomicsFetcherMaiar_GetMexEconomics(t *testing.T) {
     "data": {
         "farms": [
             {
+                "address": "erd1qqqqqqqqqqqqqpgqye633y7k0zd7nedfnp3m48h24qygm5jl2jpslxallh",
                 "unlockedRewardsAPR": "89.718773317113517867",
 				"lockedRewardsAPR": "389.541783567255098943",
                 "farmingToken": {
@@ -89,6 +90,57 @@ func Test_MexEconomicsFetcherMaiar_GetMexEconomics(t *testing.T) {
                     "name": "MEX"
                 }
             }
+        ],
+        "pairs": [
+            {
+                "address": "erd1qqqqqqqqqqqqqpgqa0fsfshnff4n76jhcye6k7uvd7qacsq42jpsp6shh2",
+                "firstToken": {
+                    "identifier": "WEGLD-bd4d79",
+                    "decimals": 18
+                },
+                "secondToken": {
+                    "identifier": "MEX-455c57",
+                    "decimals": 18
+                },
+                "liquidityPoolToken": {
+                    "identifier": "EGLDMEX-1331c2"
+                },
+                "info": {
+                    "reserves0": "125000000000000000000000",
+                    "reserves1": "175000000000000000000000000000"
+                },
+                "totalFeePercent": 0.003
+            },
+            {
+                "address": "erd1qqqqqqqqqqqqqpgqeel2kumf0r8ffyhth7pqdujjat9nx0862jpsg2pqaq",
+                "firstToken": {
+                    "identifier": "WEGLD-bd4d79",
+                    "decimals": 18
+                },
+                "secondToken": {
+                    "identifier": "USDC-c76f1f",
+                    "decimals": 6
+                },
+                "liquidityPoolToken": {
+                    "identifier": "EGLDUSDC-594e5e"
+                },
+                "info": {
+                    "reserves0": "not-a-number",
+                    "reserves1": "1000000"
+                },
+                "totalFeePercent": 0.003
+            }
+        ],
+        "stakingProxies": [
+            {
+                "address": "erd1qqqqqqqqqqqqqpgq2ymdr66nk5hx32j2tqdeqv9ajm9dj9uu2jps3dtv6v",
+                "pairAddress": "erd1qqqqqqqqqqqqqpgqa0fsfshnff4n76jhcye6k7uvd7qacsq42jpsp6shh2",
+                "lpFarmAddress": "erd1qqqqqqqqqqqqqpgqye633y7k0zd7nedfnp3m48h24qygm5jl2jpslxallh",
+                "stakingMinUnboundEpochs": 10,
+                "dualYieldToken": {
+                    "identifier": "METAUTKLK-112f52"
+                }
+            }
         ]
     }
 }`
@@ -114,6 +166,27 @@ func Test_MexEconomicsFetcherMaiar_GetMexEconomics(t *testing.T) {
 			assert.Equal(t, "857.7608885", economics.UnlockedRewardsAPR.String())
 			assert.Equal(t, "10293.13066", economics.LockedRewardsAPR.String())
 			assert.Equal(t, "0.0001994020661", economics.Price.String())
+
+			require.Len(t, economics.Farms, 3)
+			farm, ok := economics.FarmByName("EGLDMEXLPStaked")
+			require.True(t, ok, "expected the EGLDMEXLPStaked farm to be found")
+			assert.Equal(t, "EGLDMEX-1331c2", farm.FarmingTokenIdentifier)
+			assert.Equal(t, "8971.877332", farm.UnlockedRewardsAPR.String())
+			assert.Equal(t, "38954.17836", farm.LockedRewardsAPR.String())
+
+			// the pool with invalid reserves is skipped
+			require.Len(t, economics.Pools, 1)
+			pool, ok := economics.PoolByLPToken("EGLDMEX-1331c2")
+			require.True(t, ok, "expected the EGLDMEX-1331c2 pool to be found")
+			assert.Equal(t, "WEGLD-bd4d79", pool.FirstTokenIdentifier)
+			assert.Equal(t, "125000", pool.FirstTokenReserve.String())
+			assert.Equal(t, "1.75e+11", pool.SecondTokenReserve.String())
+			assert.Equal(t, "0.30", pool.FeePercent.Text('f', 2))
+
+			metastakingFarm, ok := economics.MetastakingFarmFor(pool.Address, farm.Address)
+			require.True(t, ok, "expected the EGLD-MEX metastaking farm to be found")
+			assert.Equal(t, 10, metastakingFarm.UnbondingEpochs)
+			assert.Equal(t, "METAUTKLK-112f52", metastakingFarm.DualYieldTokenIdentifier)
 		})
 
 		t.Run("err_response", func(t *testing.T) {
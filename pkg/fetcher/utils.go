@@ -1,6 +1,7 @@
 package fetcher
 
 import (
+	"fmt"
 	"math/big"
 	"net/http"
 	"time"
@@ -16,6 +17,14 @@ const (
 
 	// MexMaiarFetcherEndpoint endpoint to fetch the MEX price and the APR for locked and unlocked staking
 	MexMaiarFetcherEndpoint = "https://testnet-exchange-graph.elrond.com/graphql"
+
+	// WalletMultiversXEndpoint endpoint of the mainnet API to fetch the balances and the delegations of the accounts;
+	// it is overridden by FETCHER_ENDPOINT_WALLET, e.g. with the API of the testnet
+	WalletMultiversXEndpoint = "https://api.multiversx.com"
+
+	// LiquidStakingHatomEndpoint endpoint to fetch the exchange rate and the APR of the sEGLD liquid staking token on
+	// the mainnet; it is overridden by FETCHER_ENDPOINT_LIQUID_STAKING
+	LiquidStakingHatomEndpoint = "https://mainnet-api.hatom.com/graphql"
 )
 
 var (
@@ -32,3 +41,16 @@ var httpClient = &http.Client{
 	},
 	Timeout: time.Second * 15,
 }
+
+// parseTokenAmount converts an on-chain integer amount to a decimal amount, by applying the token decimals
+func parseTokenAmount(amount string, decimals int) (*big.Float, error) {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid token amount '%s'", amount)
+	}
+
+	divisor := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+
+	result := new(big.Float).SetInt(value)
+	return result.Quo(result, new(big.Float).SetInt(divisor)), nil
+}
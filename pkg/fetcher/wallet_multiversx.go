@@ -0,0 +1,180 @@
+package fetcher
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+const (
+	// egldDecimals is the number of decimals of the EGLD amounts returned by the API
+	egldDecimals = 18
+
+	// walletPageSize is the largest number of tokens retrieved for an account
+	walletPageSize = 1000
+
+	// bech32Charset contains the characters allowed in the data part of an address
+	bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+)
+
+// WalletFetcherMultiversX retrieves the balances and the delegations of an account from the MultiversX API
+type WalletFetcherMultiversX struct {
+	ApiEndpoint string
+}
+
+// IsValidAddress returns true if the address has the format of an erd1 address
+func IsValidAddress(address string) bool {
+	if len(address) != 62 || !strings.HasPrefix(address, "erd1") {
+		return false
+	}
+	for _, c := range address[4:] {
+		if !strings.ContainsRune(bech32Charset, c) {
+			return false
+		}
+	}
+	return true
+}
+
+func (wf *WalletFetcherMultiversX) FetchWallet(address string) (Wallet, error) {
+	wallet := Wallet{Address: address}
+
+	if !IsValidAddress(address) {
+		return wallet, fmt.Errorf("invalid address '%s'", address)
+	}
+	accountEndpoint := wf.ApiEndpoint + "/accounts/" + address
+
+	var account struct {
+		Balance string `json:"balance"`
+	}
+	if err := wf.get(accountEndpoint, &account); err != nil {
+		return wallet, err
+	}
+
+	var err error
+	wallet.EgldBalance, err = parseTokenAmount(account.Balance, egldDecimals)
+	if err != nil {
+		log.Error("error parsing the EGLD balance of %s: %s", address, err)
+		return wallet, err
+	}
+
+	type tokenResponse struct {
+		Identifier string   `json:"identifier"`
+		Collection string   `json:"collection"`
+		Balance    string   `json:"balance"`
+		Decimals   int      `json:"decimals"`
+		ValueUsd   *float64 `json:"valueUsd"`
+	}
+
+	var tokens, metaTokens []tokenResponse
+	if err := wf.get(fmt.Sprintf("%s/tokens?size=%d", accountEndpoint, walletPageSize), &tokens); err != nil {
+		return wallet, err
+	}
+	if err := wf.get(fmt.Sprintf("%s/nfts?type=MetaESDT&size=%d", accountEndpoint, walletPageSize), &metaTokens); err != nil {
+		return wallet, err
+	}
+
+	// the Meta ESDTs are summed by collection, as each lock creates a new nonce
+	tokenIndexes := make(map[string]int)
+	for _, token := range append(tokens, metaTokens...) {
+		identifier := token.Identifier
+		if token.Collection != "" {
+			identifier = token.Collection
+		}
+
+		balance, err := parseTokenAmount(token.Balance, token.Decimals)
+		if err != nil {
+			log.Error("error parsing the balance of %s of %s: %s", token.Identifier, address, err)
+			return wallet, err
+		}
+
+		idx, ok := tokenIndexes[identifier]
+		if !ok {
+			idx = len(wallet.Tokens)
+			tokenIndexes[identifier] = idx
+			wallet.Tokens = append(wallet.Tokens, WalletToken{Identifier: identifier, Balance: new(big.Float)})
+		}
+
+		walletToken := &wallet.Tokens[idx]
+		walletToken.Balance.Add(walletToken.Balance, balance)
+		if token.ValueUsd != nil {
+			if walletToken.ValueInUsd == nil {
+				walletToken.ValueInUsd = new(big.Float)
+			}
+			walletToken.ValueInUsd.Add(walletToken.ValueInUsd, big.NewFloat(*token.ValueUsd))
+		}
+	}
+
+	var delegations []struct {
+		Contract            string `json:"contract"`
+		UserActiveStake     string `json:"userActiveStake"`
+		ClaimableRewards    string `json:"claimableRewards"`
+		UserUnBondable      string `json:"userUnBondable"`
+		UserUndelegatedList []struct {
+			Amount string `json:"amount"`
+		} `json:"userUndelegatedList"`
+	}
+	if err := wf.get(accountEndpoint+"/delegation", &delegations); err != nil {
+		return wallet, err
+	}
+
+	for _, delegation := range delegations {
+		amounts := []string{delegation.UserActiveStake, delegation.ClaimableRewards, delegation.UserUnBondable}
+		for _, undelegated := range delegation.UserUndelegatedList {
+			amounts = append(amounts, undelegated.Amount)
+		}
+
+		values := make([]*big.Float, len(amounts))
+		for i, amount := range amounts {
+			// the API omits the empty amounts
+			if amount == "" {
+				amount = "0"
+			}
+			values[i], err = parseTokenAmount(amount, egldDecimals)
+			if err != nil {
+				log.Error("error parsing the delegation of %s to %s: %s", address, delegation.Contract, err)
+				return wallet, err
+			}
+		}
+
+		unbonding := new(big.Float)
+		for _, value := range values[2:] {
+			unbonding.Add(unbonding, value)
+		}
+
+		wallet.Delegations = append(wallet.Delegations, Delegation{
+			Contract:         delegation.Contract,
+			ActiveStake:      values[0],
+			ClaimableRewards: values[1],
+			Unbonding:        unbonding,
+		})
+	}
+
+	return wallet, nil
+}
+
+// get retrieves the endpoint and decodes the JSON response into result
+func (wf *WalletFetcherMultiversX) get(endpoint string, result interface{}) error {
+	res, err := httpClient.Get(endpoint)
+	if err != nil {
+		log.Error("error retrieving the wallet from endpoint %s: %s", endpoint, err)
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		err = fmt.Errorf("error retrieving the wallet from endpoint %s: response status code %d", endpoint, res.StatusCode)
+		log.Error("%s", err)
+		return err
+	}
+
+	if err := json.NewDecoder(res.Body).Decode(result); err != nil {
+		log.Error("error decoding the wallet JSON response from endpoint %s: %s", endpoint, err)
+		return err
+	}
+
+	return nil
+}
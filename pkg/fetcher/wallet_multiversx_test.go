@@ -0,0 +1,112 @@
+package fetcher
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testWalletAddress        = "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+	testWalletMissingAddress = "erd1spyavw0956vq68xj8y4tenjpq2wd5a9p2c6j8gsz7ztyrnpxrruqzu66jx"
+)
+
+func Test_WalletFetcherMultiversX_FetchWallet(t *testing.T) {
+	t.Parallel()
+
+	t.Run("offline", func(t *testing.T) {
+		// the responses recorded from the MultiversX API, trimmed to the fields used
+		responses := map[string]string{
+			"/accounts/" + testWalletAddress: `{"address": "` + testWalletAddress + `", "balance": "1500000000000000000", "nonce": 12}`,
+			"/accounts/" + testWalletAddress + "/tokens": `[
+    {"identifier": "MEX-455c57", "name": "MEX", "balance": "2000000000000000000000000", "decimals": 18, "valueUsd": 14.2},
+    {"identifier": "EGLDMEX-0be9e5", "name": "EGLDMEXLP", "balance": "3500000000000000000", "decimals": 18, "valueUsd": 512.75}
+]`,
+			"/accounts/" + testWalletAddress + "/nfts": `[
+    {"identifier": "LKMEX-aab910-0f1a", "collection": "LKMEX-aab910", "balance": "1000000000000000000000", "decimals": 18},
+    {"identifier": "LKMEX-aab910-1b2c", "collection": "LKMEX-aab910", "balance": "500000000000000000000", "decimals": 18}
+]`,
+			"/accounts/" + testWalletAddress + "/delegation": `[
+    {
+        "address": "` + testWalletAddress + `",
+        "contract": "erd1qqqqqqqqqqqqqqqpqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqqhllllsajxzat",
+        "userUnBondable": "0",
+        "userActiveStake": "10000000000000000000",
+        "claimableRewards": "250000000000000000",
+        "userUndelegatedList": [{"amount": "1000000000000000000", "seconds": 86400}]
+    }
+]`,
+		}
+
+		mockHandlerLogic := &mockHandler{
+			responseFunc: func(r *http.Request) ([]byte, int, error) {
+				response, ok := responses[r.URL.Path]
+				if !ok {
+					return []byte(`{"statusCode": 404, "message": "Not Found"}`), http.StatusNotFound, nil
+				}
+				return []byte(response), http.StatusOK, nil
+			},
+		}
+		handler := http.NewServeMux()
+		handler.Handle("/accounts/", mockHandlerLogic)
+
+		t.Run("ok", func(t *testing.T) {
+			server := httptest.NewUnstartedServer(handler)
+			server.Start()
+			defer server.Close()
+
+			fetcher := WalletFetcherMultiversX{ApiEndpoint: server.URL}
+			wallet, err := fetcher.FetchWallet(testWalletAddress)
+			require.NoError(t, err)
+
+			assert.Equal(t, "1.5", wallet.EgldBalance.Text('f', 1))
+
+			require.Len(t, wallet.Tokens, 3)
+			assert.Equal(t, "MEX-455c57", wallet.Tokens[0].Identifier)
+			assert.Equal(t, "2000000", wallet.Tokens[0].Balance.Text('f', 0))
+			assert.Equal(t, "512.75", wallet.Tokens[1].ValueInUsd.Text('f', 2))
+			// the locked MEX is summed by collection
+			assert.Equal(t, "LKMEX-aab910", wallet.Tokens[2].Identifier)
+			assert.Equal(t, "1500", wallet.Tokens[2].Balance.Text('f', 0))
+			assert.Nil(t, wallet.Tokens[2].ValueInUsd)
+
+			require.Len(t, wallet.Delegations, 1)
+			assert.Equal(t, "10.00", wallet.Delegations[0].ActiveStake.Text('f', 2))
+			assert.Equal(t, "0.25", wallet.Delegations[0].ClaimableRewards.Text('f', 2))
+			assert.Equal(t, "1.00", wallet.Delegations[0].Unbonding.Text('f', 2))
+		})
+
+		t.Run("err_response", func(t *testing.T) {
+			server := httptest.NewUnstartedServer(handler)
+			server.Start()
+			defer server.Close()
+
+			fetcher := WalletFetcherMultiversX{ApiEndpoint: server.URL}
+			_, err := fetcher.FetchWallet(testWalletMissingAddress)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), "response status code 404")
+		})
+
+		t.Run("err_address", func(t *testing.T) {
+			fetcher := WalletFetcherMultiversX{ApiEndpoint: "http://localhost"}
+			_, err := fetcher.FetchWallet("erd1invalid")
+
+			require.Error(t, err)
+			assert.True(t, strings.HasPrefix(err.Error(), "invalid address"))
+		})
+	})
+}
+
+func Test_IsValidAddress(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsValidAddress(testWalletAddress))
+	assert.False(t, IsValidAddress("erd1"+strings.Repeat("b", 58)))
+	assert.False(t, IsValidAddress("abc1"+testWalletAddress[4:]))
+	assert.False(t, IsValidAddress(testWalletAddress[:61]))
+}
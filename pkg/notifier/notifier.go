@@ -0,0 +1,15 @@
+package notifier
+
+// Message is an email sent to a user
+type Message struct {
+	To      string
+	Subject string
+	// HTMLBody is the rendered HTML content of the email
+	HTMLBody string
+}
+
+// Notifier delivers messages to the users, transport agnostic
+// note: having it as an interface makes it much easier to write tests for it and makes it more future proof
+type Notifier interface {
+	Notify(message Message) error
+}
@@ -0,0 +1,56 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+// SMTPNotifier sends the messages as HTML emails through an SMTP server
+type SMTPNotifier struct {
+	// Address is the host:port of the SMTP server
+	Address string
+	From    string
+	// Username and Password are optional; the server is used without authentication if Username is empty
+	Username string
+	Password string
+}
+
+func (n *SMTPNotifier) Notify(message Message) error {
+	if strings.ContainsAny(message.To, "\r\n") || strings.ContainsAny(message.Subject, "\r\n") {
+		return fmt.Errorf("invalid message headers for recipient '%s'", message.To)
+	}
+
+	var auth smtp.Auth
+	if n.Username != "" {
+		host, _, err := net.SplitHostPort(n.Address)
+		if err != nil {
+			log.Error("error parsing the SMTP server address %s: %s", n.Address, err)
+			return err
+		}
+		auth = smtp.PlainAuth("", n.Username, n.Password, host)
+	}
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", n.From)
+	fmt.Fprintf(&body, "To: %s\r\n", message.To)
+	fmt.Fprintf(&body, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", message.Subject))
+	fmt.Fprintf(&body, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/html; charset=\"utf-8\"\r\n")
+	body.WriteString("\r\n")
+	body.WriteString(strings.ReplaceAll(message.HTMLBody, "\n", "\r\n"))
+
+	if err := smtp.SendMail(n.Address, auth, n.From, []string{message.To}, body.Bytes()); err != nil {
+		log.Error("error sending the email to %s through %s: %s", message.To, n.Address, err)
+		return err
+	}
+
+	return nil
+}
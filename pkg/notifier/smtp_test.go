@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// smtpSink is a local SMTP server which accepts every message and records its data
+type smtpSink struct {
+	listener net.Listener
+	messages chan string
+}
+
+func newSMTPSink(t *testing.T) *smtpSink {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	sink := &smtpSink{listener: listener, messages: make(chan string, 1)}
+	go sink.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+
+	return sink
+}
+
+func (s *smtpSink) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *smtpSink) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	write := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	write("220 sink ready")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+
+		command := strings.ToUpper(strings.TrimSpace(line))
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			write("250 sink")
+		case strings.HasPrefix(command, "DATA"):
+			write("354 send the data")
+			var data strings.Builder
+			for {
+				dataLine, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if dataLine == ".\r\n" {
+					break
+				}
+				data.WriteString(dataLine)
+			}
+			s.messages <- data.String()
+			write("250 queued")
+		case strings.HasPrefix(command, "QUIT"):
+			write("221 bye")
+			return
+		default:
+			write("250 ok")
+		}
+	}
+}
+
+func TestSMTPNotifier_Notify(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ok", func(t *testing.T) {
+		sink := newSMTPSink(t)
+
+		notifier := SMTPNotifier{Address: sink.listener.Addr().String(), From: "digest@istari.vision"}
+		err := notifier.Notify(Message{
+			To:       "user@example.com",
+			Subject:  "Your weekly digest",
+			HTMLBody: "<p>EGLD is at 250 USD</p>",
+		})
+		require.NoError(t, err)
+
+		message := <-sink.messages
+		assert.Contains(t, message, "To: user@example.com\r\n")
+		assert.Contains(t, message, "Subject: Your weekly digest\r\n")
+		assert.Contains(t, message, "Content-Type: text/html")
+		assert.Contains(t, message, "<p>EGLD is at 250 USD</p>")
+	})
+
+	t.Run("err_headers", func(t *testing.T) {
+		notifier := SMTPNotifier{Address: "127.0.0.1:0", From: "digest@istari.vision"}
+		err := notifier.Notify(Message{To: "user@example.com\r\nBcc: other@example.com", Subject: "digest"})
+		assert.Error(t, err)
+	})
+
+	t.Run("err_conn", func(t *testing.T) {
+		sink := newSMTPSink(t)
+		address := sink.listener.Addr().String()
+		_ = sink.listener.Close()
+
+		notifier := SMTPNotifier{Address: address, From: "digest@istari.vision"}
+		err := notifier.Notify(Message{To: "user@example.com", Subject: "digest"})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,302 @@
+package openapi
+
+import (
+	"encoding"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Version is the version of the OpenAPI specification the documents follow
+const Version = "3.0.3"
+
+// Document is an OpenAPI document, restricted to the parts describing the API
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+	// Security lists the alternative security requirements of all the operations
+	Security []SecurityRequirement `json:"security,omitempty"`
+}
+
+// Info is the metadata of the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// PathItem maps the lowercase HTTP methods of a path to their operations
+type PathItem map[string]*Operation
+
+// Operation describes a single method of a path
+type Operation struct {
+	OperationID string              `json:"operationId"`
+	Summary     string              `json:"summary,omitempty"`
+	Description string              `json:"description,omitempty"`
+	Tags        []string            `json:"tags,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+// Parameter is a path, query or header parameter of an operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody is the JSON body of an operation
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a response of an operation; Content is empty for the responses without a body
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// Header is a header of a response
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// MediaType is the schema of a body in a given content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Components holds the schemas and the security schemes referenced by the operations
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme is a way to authenticate the requests, e.g. an API key header
+type SecurityScheme struct {
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Name        string `json:"name,omitempty"`
+	In          string `json:"in,omitempty"`
+}
+
+// SecurityRequirement maps the names of the security schemes required together to their scopes; an empty requirement
+// makes the authentication optional
+type SecurityRequirement map[string][]string
+
+// Schema is a JSON schema, as supported by OpenAPI
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	bigFloatType      = reflect.TypeOf(big.Float{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// Generator builds the schemas of Go types, as serialized by encoding/json, and collects the named structs as
+// components
+type Generator struct {
+	schemas map[string]*Schema
+	// names maps the registered struct types to their component name
+	names map[reflect.Type]string
+}
+
+// NewGenerator returns a Generator without any component
+func NewGenerator() *Generator {
+	return &Generator{
+		schemas: map[string]*Schema{},
+		names:   map[reflect.Type]string{},
+	}
+}
+
+// Components returns the components of the schemas generated so far
+func (g *Generator) Components() Components {
+	return Components{Schemas: g.schemas}
+}
+
+// Schema returns the schema of the type of value; the named structs are referenced from the components
+func (g *Generator) Schema(value interface{}) *Schema {
+	return g.schema(reflect.TypeOf(value))
+}
+
+func (g *Generator) schema(t reflect.Type) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == bigFloatType:
+		return &Schema{Type: "string", Format: "decimal"}
+	case reflect.PtrTo(t).Implements(textMarshalerType):
+		return &Schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		// encoding/json serializes the byte slices as base64 strings
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + g.register(t)}
+	default:
+		// interfaces can hold any value
+		return &Schema{}
+	}
+}
+
+// register adds the schema of the named struct to the components, and returns its name
+func (g *Generator) register(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := exportedName(t.Name())
+	for _, other := range g.names {
+		if other == name {
+			// two packages declare a struct with the same name
+			name = exportedName(packageName(t)) + name
+			break
+		}
+	}
+
+	// the name is registered before the fields, for the recursive types
+	g.names[t] = name
+	g.schemas[name] = g.structSchema(t)
+
+	return name
+}
+
+// structSchema returns the schema of the fields of the struct, applying the json tags
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	g.addFields(schema, t)
+	return schema
+}
+
+func (g *Generator) addFields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options := parseTag(tag)
+
+		if field.Anonymous && name == "" {
+			fieldType := field.Type
+			if fieldType.Kind() == reflect.Ptr {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				g.addFields(schema, fieldType)
+				continue
+			}
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		schema.Properties[name] = g.schema(field.Type)
+		if !strings.Contains(options, "omitempty") {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+}
+
+// parseTag splits the json tag into the name and the options
+func parseTag(tag string) (string, string) {
+	if i := strings.Index(tag, ","); i != -1 {
+		return tag[:i], tag[i+1:]
+	}
+	return tag, ""
+}
+
+// exportedName returns the name with an uppercase first letter
+func exportedName(name string) string {
+	if name == "" {
+		return name
+	}
+	runes := []rune(name)
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// packageName returns the last element of the import path of the type
+func packageName(t reflect.Type) string {
+	path := t.PkgPath()
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+// PathParameters returns the parameters of a path in the gin format (e.g. /scenarios/:id), and the path in the
+// OpenAPI format (e.g. /scenarios/{id})
+func PathParameters(path string) (string, []Parameter) {
+	var parameters []Parameter
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			name := segment[1:]
+			parameters = append(parameters, Parameter{
+				Name:     name,
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+			segments[i] = fmt.Sprintf("{%s}", name)
+		}
+	}
+
+	return strings.Join(segments, "/"), parameters
+}
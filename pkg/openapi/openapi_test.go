@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEmbedded struct {
+	Embedded string `json:"embedded"`
+}
+
+type testChild struct {
+	Name string
+}
+
+type testPayload struct {
+	testEmbedded
+	Amount     string               `json:"amount"`
+	Optional   string               `json:"optional,omitempty"`
+	Ignored    string               `json:"-"`
+	Days       int                  `json:"days"`
+	Rate       float64              `json:"rate"`
+	Price      *big.Float           `json:"price"`
+	At         time.Time            `json:"at"`
+	Tags       []string             `json:"tags"`
+	Children   map[string]testChild `json:"children"`
+	Next       *testPayload         `json:"next,omitempty"`
+	unexported string
+}
+
+func TestGenerator_Schema(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	generator := NewGenerator()
+	schema := generator.Schema(testPayload{})
+	assert.Equal(t, "#/components/schemas/TestPayload", schema.Ref)
+
+	components := generator.Components()
+	require.Contains(t, components.Schemas, "TestPayload")
+	require.Contains(t, components.Schemas, "TestChild")
+
+	payload := components.Schemas["TestPayload"]
+	assert.Equal(t, []string{"embedded", "amount", "days", "rate", "price", "at", "tags", "children"}, payload.Required)
+	assert.NotContains(t, payload.Properties, "Ignored")
+	assert.NotContains(t, payload.Properties, "unexported")
+	assert.Equal(t, &Schema{Type: "string"}, payload.Properties["optional"])
+	assert.Equal(t, &Schema{Type: "integer", Format: "int32"}, payload.Properties["days"])
+	assert.Equal(t, &Schema{Type: "number", Format: "double"}, payload.Properties["rate"])
+	assert.Equal(t, &Schema{Type: "string", Format: "decimal"}, payload.Properties["price"])
+	assert.Equal(t, &Schema{Type: "string", Format: "date-time"}, payload.Properties["at"])
+	assert.Equal(t, &Schema{Type: "array", Items: &Schema{Type: "string"}}, payload.Properties["tags"])
+	assert.Equal(t, &Schema{Type: "object", AdditionalProperties: &Schema{Ref: "#/components/schemas/TestChild"}}, payload.Properties["children"])
+	assert.Equal(t, &Schema{Ref: "#/components/schemas/TestPayload"}, payload.Properties["next"])
+
+	assert.Equal(t, []string{"Name"}, components.Schemas["TestChild"].Required)
+}
+
+func TestPathParameters(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	path, parameters := PathParameters("/api/digests/:id/unsubscribe")
+	assert.Equal(t, "/api/digests/{id}/unsubscribe", path)
+	require.Len(t, parameters, 1)
+	assert.Equal(t, "id", parameters[0].Name)
+	assert.Equal(t, "path", parameters[0].In)
+	assert.True(t, parameters[0].Required)
+
+	path, parameters = PathParameters("/api/prices")
+	assert.Equal(t, "/api/prices", path)
+	assert.Empty(t, parameters)
+}
@@ -0,0 +1,556 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+// AlertKind is the condition checked by an alert rule
+type AlertKind string
+
+const (
+	AlertEgldPriceBelow   AlertKind = "egld-price-below"
+	AlertEgldPriceAbove   AlertKind = "egld-price-above"
+	AlertProviderAPRBelow AlertKind = "provider-apr-below"
+	// AlertMexLockedAPRChange triggers when the MEX locked APR moves by more than the threshold, in percentage points,
+	// from its value when the rule last triggered
+	AlertMexLockedAPRChange AlertKind = "mex-locked-apr-change"
+
+	// alertRulesCacheKey is the Redis hash storing the alert rules, keyed by their ID
+	alertRulesCacheKey = "alert_rules"
+	// alertStatesCacheKey is the Redis hash storing the last observation of each alert rule, keyed by its ID
+	alertStatesCacheKey = "alert_states"
+	// alertDeliveriesCacheKeyPrefix is the prefix of the Redis lists storing the deliveries of each alert rule
+	alertDeliveriesCacheKeyPrefix = "alert_deliveries_"
+	// maxAlertDeliveries is the number of deliveries kept in the log of each alert rule
+	maxAlertDeliveries = 100
+	// MaxAlertRules is the number of alert rules which can be registered
+	MaxAlertRules = 1000
+
+	// alertDeliveryWorkers is the number of webhooks delivered concurrently
+	alertDeliveryWorkers = 8
+	// alertDeliveryQueueSize is the number of triggered events waiting to be delivered; the events triggered while the
+	// queue is full are dropped
+	alertDeliveryQueueSize = 1000
+
+	// webhookAttempts is the number of times a webhook is sent before giving up
+	webhookAttempts = 3
+	// WebhookSignatureHeader carries the hex HMAC-SHA256 of the timestamp, a dot and the body, keyed by the secret of
+	// the rule
+	WebhookSignatureHeader = "X-Istari-Signature"
+	// WebhookTimestampHeader carries the Unix time the webhook was signed at
+	WebhookTimestampHeader = "X-Istari-Timestamp"
+)
+
+var (
+	// ErrInvalidAlertRule is returned when an alert rule can't be registered
+	ErrInvalidAlertRule = errors.New("invalid alert rule")
+	// ErrAlertRuleNotFound is returned when the alert rule doesn't exist
+	ErrAlertRuleNotFound = errors.New("alert rule not found")
+	// ErrAlertRulesLimit is returned when MaxAlertRules are already registered
+	ErrAlertRulesLimit = errors.New("too many alert rules")
+
+	// errWebhookAddressNotAllowed is returned when a webhook would be sent to an address which is not publicly
+	// routable, such as the loopback, the private networks or the cloud metadata endpoints
+	errWebhookAddressNotAllowed = errors.New("webhook address not allowed")
+
+	// webhookRetryBackoff is the delay before the first retry of a webhook, doubled for each retry
+	webhookRetryBackoff = 2 * time.Second
+
+	// webhookClient sends the webhooks; the timeout keeps a slow receiver from delaying the other deliveries. The
+	// addresses are checked when dialing, after the DNS resolution, so a webhook host can't be rebound to an internal
+	// address after its rule was validated; the proxy is disabled for the same reason
+	webhookClient = &http.Client{
+		Timeout: 10 * time.Second,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         (&net.Dialer{Timeout: 5 * time.Second, Control: webhookDialControl}).DialContext,
+			TLSHandshakeTimeout: 5 * time.Second,
+			MaxIdleConns:        alertDeliveryWorkers,
+		},
+	}
+)
+
+// AlertRule is a condition on the market data, whose events are delivered to a webhook
+type AlertRule struct {
+	ID        string
+	Kind      AlertKind
+	Threshold *big.Float
+	// StakingProvider is the identity of the provider checked by AlertProviderAPRBelow
+	StakingProvider string
+	WebhookURL      string
+	// Secret signs the webhooks, and authorizes reading the deliveries and deleting the rule
+	Secret    string
+	CreatedAt time.Time
+}
+
+// AlertState is the last observation of an alert rule
+type AlertState struct {
+	// Value is the last observed value for the threshold rules, and the reference value for the change rules
+	Value *big.Float
+	// Active is true while the condition of a threshold rule holds, so that it only triggers when it starts holding
+	Active bool
+}
+
+// AlertEvent is the payload of the webhooks
+type AlertEvent struct {
+	RuleID          string    `json:"rule_id"`
+	Kind            AlertKind `json:"kind"`
+	StakingProvider string    `json:"staking_provider,omitempty"`
+	Threshold       string    `json:"threshold"`
+	Value           string    `json:"value"`
+	PreviousValue   string    `json:"previous_value,omitempty"`
+	TriggeredAt     time.Time `json:"triggered_at"`
+}
+
+// AlertDelivery is an entry of the delivery log of an alert rule
+type AlertDelivery struct {
+	Event     AlertEvent
+	Attempts  int
+	Delivered bool
+	// StatusCode is the status of the last attempt, 0 if no response was received
+	StatusCode int
+	Error      string `json:",omitempty"`
+}
+
+// Validate returns an error if the rule can't be checked or delivered
+func (r *AlertRule) Validate() error {
+	switch r.Kind {
+	case AlertEgldPriceBelow, AlertEgldPriceAbove, AlertMexLockedAPRChange:
+	case AlertProviderAPRBelow:
+		if r.StakingProvider == "" {
+			return fmt.Errorf("%w: the staking provider is required for '%s'", ErrInvalidAlertRule, r.Kind)
+		}
+	default:
+		return fmt.Errorf("%w: unknown kind '%s'", ErrInvalidAlertRule, r.Kind)
+	}
+
+	if r.Threshold == nil || r.Threshold.Sign() <= 0 {
+		return fmt.Errorf("%w: the threshold must be positive", ErrInvalidAlertRule)
+	}
+
+	webhookURL, err := url.Parse(r.WebhookURL)
+	if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Hostname() == "" {
+		return fmt.Errorf("%w: the webhook URL must be an absolute HTTP(S) URL", ErrInvalidAlertRule)
+	}
+
+	// the host names are checked again when the webhooks are delivered, once they are resolved
+	host := strings.ToLower(strings.TrimSuffix(webhookURL.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: the webhook URL must be publicly reachable", ErrInvalidAlertRule)
+	}
+	if ip := net.ParseIP(host); ip != nil && !isPublicIP(ip) {
+		return fmt.Errorf("%w: the webhook URL must be publicly reachable", ErrInvalidAlertRule)
+	}
+
+	return nil
+}
+
+// isPublicIP returns false for the loopback, private, link-local (including the cloud metadata endpoints),
+// multicast and unspecified addresses
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified()
+}
+
+// webhookDialControl refuses the connections of the webhooks to the addresses which are not public
+func webhookDialControl(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if ip := net.ParseIP(host); ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", errWebhookAddressNotAllowed, host)
+	}
+	return nil
+}
+
+// observe returns the value checked by the rule, and false if it is not available
+func (r *AlertRule) observe(egldStakingProviders []fetcher.EgldStakingProvider, economics Economics) (*big.Float, bool) {
+	switch r.Kind {
+	case AlertEgldPriceBelow, AlertEgldPriceAbove:
+		price, _, err := big.ParseFloat(economics.Prices.EGLD, 10, 0, big.ToNearestEven)
+		return price, err == nil
+	case AlertProviderAPRBelow:
+		for _, provider := range egldStakingProviders {
+			if provider.Identity == r.StakingProvider {
+				return big.NewFloat(provider.APR), true
+			}
+		}
+	case AlertMexLockedAPRChange:
+		apr := economics.mexEconomics.LockedRewardsAPR
+		return apr, apr != nil
+	}
+
+	return nil, false
+}
+
+// evaluate returns the event triggered by the observed value, or nil, and the new state of the rule
+func (r *AlertRule) evaluate(state AlertState, value *big.Float, now time.Time) (*AlertEvent, AlertState) {
+	event := &AlertEvent{
+		RuleID:          r.ID,
+		Kind:            r.Kind,
+		StakingProvider: r.StakingProvider,
+		Threshold:       r.Threshold.Text('f', FloatingPointAccuracy),
+		Value:           value.Text('f', FloatingPointAccuracy),
+		TriggeredAt:     now,
+	}
+	if state.Value != nil {
+		event.PreviousValue = state.Value.Text('f', FloatingPointAccuracy)
+	}
+
+	if r.Kind == AlertMexLockedAPRChange {
+		// the first observation is the reference value
+		if state.Value == nil {
+			return nil, AlertState{Value: value}
+		}
+
+		change := new(big.Float).Sub(value, state.Value)
+		if change.Abs(change).Cmp(r.Threshold) <= 0 {
+			return nil, state
+		}
+		return event, AlertState{Value: value}
+	}
+
+	var active bool
+	switch r.Kind {
+	case AlertEgldPriceBelow, AlertProviderAPRBelow:
+		active = value.Cmp(r.Threshold) < 0
+	case AlertEgldPriceAbove:
+		active = value.Cmp(r.Threshold) > 0
+	}
+
+	newState := AlertState{Value: value, Active: active}
+	if !active || state.Active {
+		return nil, newState
+	}
+	return event, newState
+}
+
+// CreateAlertRule validates and stores the rule under a new ID
+func (s *Service) CreateAlertRule(rule *AlertRule) error {
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	var err error
+	rule.ID, err = randomString(8, hex.EncodeToString)
+	if err != nil {
+		log.Error("error generating the alert rule ID: %s", err)
+		return err
+	}
+	if rule.Secret == "" {
+		rule.Secret, err = randomString(32, hex.EncodeToString)
+		if err != nil {
+			log.Error("error generating the alert rule secret: %s", err)
+			return err
+		}
+	}
+	rule.CreatedAt = time.Now().UTC().Truncate(time.Second)
+
+	data, err := json.Marshal(rule)
+	if err != nil {
+		log.Error("error marshalling the alert rule to JSON: %s", err)
+		return err
+	}
+
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	count, err := s.Cache.HLen(ctx, alertRulesCacheKey).Result()
+	if err != nil {
+		log.Error("error counting the alert rules in the cache: %s", err)
+		return err
+	}
+	if count >= MaxAlertRules {
+		return fmt.Errorf("%w: at most %d rules can be registered", ErrAlertRulesLimit, MaxAlertRules)
+	}
+
+	if _, err := s.Cache.HSet(ctx, alertRulesCacheKey, rule.ID, data).Result(); err != nil {
+		log.Error("error storing the alert rule in the cache: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// GetAlertRule returns the alert rule with the given ID
+func (s *Service) GetAlertRule(id string) (*AlertRule, error) {
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	data, err := s.Cache.HGet(ctx, alertRulesCacheKey, id).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, fmt.Errorf("%w: '%s'", ErrAlertRuleNotFound, id)
+		}
+		log.Error("error retrieving the alert rule %s from the cache: %s", id, err)
+		return nil, err
+	}
+
+	var rule AlertRule
+	if err := json.Unmarshal([]byte(data), &rule); err != nil {
+		log.Error("error unmarshalling the alert rule %s: %s", id, err)
+		return nil, err
+	}
+
+	return &rule, nil
+}
+
+// DeleteAlertRule deletes the alert rule, its state and its delivery log
+func (s *Service) DeleteAlertRule(id string) error {
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	_, err := s.Cache.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, alertRulesCacheKey, id)
+		pipe.HDel(ctx, alertStatesCacheKey, id)
+		pipe.Del(ctx, alertDeliveriesCacheKeyPrefix+id)
+		return nil
+	})
+	if err != nil {
+		log.Error("error deleting the alert rule %s from the cache: %s", id, err)
+		return err
+	}
+
+	return nil
+}
+
+// GetAlertDeliveries returns the delivery log of the alert rule, the most recent delivery first
+func (s *Service) GetAlertDeliveries(id string) ([]AlertDelivery, error) {
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	values, err := s.Cache.LRange(ctx, alertDeliveriesCacheKeyPrefix+id, 0, -1).Result()
+	if err != nil {
+		log.Error("error retrieving the deliveries of the alert rule %s from the cache: %s", id, err)
+		return nil, err
+	}
+
+	deliveries := make([]AlertDelivery, 0, len(values))
+	for _, value := range values {
+		var delivery AlertDelivery
+		if err := json.Unmarshal([]byte(value), &delivery); err != nil {
+			log.Error("error unmarshalling a delivery of the alert rule %s: %s", id, err)
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, nil
+}
+
+// CheckAlerts evaluates all the alert rules against the cached market data and queues the triggered events for
+// delivery, without waiting for the webhooks; it is called after each cache refresh
+func (s *Service) CheckAlerts() error {
+	economics, err := s.GetEconomics()
+	if err != nil {
+		log.Error("error retrieving the economics to check the alerts: %s", err)
+		return err
+	}
+
+	providers, err := s.GetStakingProviders()
+	if err != nil {
+		log.Error("error retrieving the staking providers to check the alerts: %s", err)
+		return err
+	}
+
+	ctx, cc := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cc()
+
+	rules, err := s.Cache.HGetAll(ctx, alertRulesCacheKey).Result()
+	if err != nil {
+		log.Error("error retrieving the alert rules from the cache: %s", err)
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	states, err := s.Cache.HGetAll(ctx, alertStatesCacheKey).Result()
+	if err != nil {
+		log.Error("error retrieving the alert states from the cache: %s", err)
+		return err
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	newStates := make([]interface{}, 0, 2*len(rules))
+
+	for id, data := range rules {
+		var rule AlertRule
+		if err := json.Unmarshal([]byte(data), &rule); err != nil {
+			log.Error("error unmarshalling the alert rule %s: %s", id, err)
+			continue
+		}
+
+		value, ok := rule.observe(providers, economics)
+		if !ok {
+			continue
+		}
+
+		var state AlertState
+		if stateData, ok := states[id]; ok {
+			if err := json.Unmarshal([]byte(stateData), &state); err != nil {
+				log.Error("error unmarshalling the state of the alert rule %s: %s", id, err)
+			}
+		}
+
+		event, newState := rule.evaluate(state, value, now)
+
+		stateData, err := json.Marshal(newState)
+		if err != nil {
+			log.Error("error marshalling the state of the alert rule %s: %s", id, err)
+			continue
+		}
+		newStates = append(newStates, id, stateData)
+
+		if event != nil {
+			s.queueAlertDelivery(rule, *event)
+		}
+	}
+
+	if len(newStates) > 0 {
+		if _, err := s.Cache.HSet(ctx, alertStatesCacheKey, newStates...).Result(); err != nil {
+			log.Error("error storing the alert states in the cache: %s", err)
+		}
+	}
+
+	return nil
+}
+
+// pendingAlertDelivery is a triggered event waiting to be delivered to the webhook of its rule
+type pendingAlertDelivery struct {
+	rule  AlertRule
+	event AlertEvent
+}
+
+// queueAlertDelivery queues the event for the delivery workers, which are started by the first call; the event is
+// dropped if the queue is full, so a slow webhook never blocks the cache refreshes
+func (s *Service) queueAlertDelivery(rule AlertRule, event AlertEvent) {
+	s.alertDeliveriesOnce.Do(func() {
+		s.alertDeliveries = make(chan pendingAlertDelivery, alertDeliveryQueueSize)
+		for i := 0; i < alertDeliveryWorkers; i++ {
+			go s.deliverAlerts()
+		}
+	})
+
+	select {
+	case s.alertDeliveries <- pendingAlertDelivery{rule: rule, event: event}:
+	default:
+		log.Error("error queueing the alert %s for delivery: the queue is full", rule.ID)
+	}
+}
+
+// deliverAlerts delivers the queued events and records their deliveries
+func (s *Service) deliverAlerts() {
+	for pending := range s.alertDeliveries {
+		s.recordAlertDelivery(pending.rule.ID, deliverWebhook(webhookClient, pending.rule, pending.event))
+	}
+}
+
+// recordAlertDelivery adds the delivery to the log of the alert rule, dropping the oldest deliveries
+func (s *Service) recordAlertDelivery(id string, delivery AlertDelivery) {
+	data, err := json.Marshal(delivery)
+	if err != nil {
+		log.Error("error marshalling the delivery of the alert rule %s: %s", id, err)
+		return
+	}
+
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	key := alertDeliveriesCacheKeyPrefix + id
+	_, err = s.Cache.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.LPush(ctx, key, data)
+		pipe.LTrim(ctx, key, 0, maxAlertDeliveries-1)
+		return nil
+	})
+	if err != nil {
+		log.Error("error storing the delivery of the alert rule %s: %s", id, err)
+	}
+}
+
+// deliverWebhook posts the signed event to the webhook of the rule, retrying with an exponential backoff on
+// connection errors, 429 and 5xx responses
+func deliverWebhook(client *http.Client, rule AlertRule, event AlertEvent) AlertDelivery {
+	delivery := AlertDelivery{Event: event}
+
+	body, err := json.Marshal(event)
+	if err != nil {
+		delivery.Error = err.Error()
+		return delivery
+	}
+
+	backoff := webhookRetryBackoff
+	for delivery.Attempts < webhookAttempts {
+		if delivery.Attempts > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		delivery.Attempts++
+
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		req, err := http.NewRequest(http.MethodPost, rule.WebhookURL, bytes.NewReader(body))
+		if err != nil {
+			delivery.Error = err.Error()
+			return delivery
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(WebhookTimestampHeader, timestamp)
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(rule.Secret, timestamp, body))
+
+		res, err := client.Do(req)
+		if err != nil {
+			delivery.StatusCode = 0
+			delivery.Error = err.Error()
+			if errors.Is(err, errWebhookAddressNotAllowed) {
+				break
+			}
+			continue
+		}
+		res.Body.Close()
+
+		delivery.StatusCode = res.StatusCode
+		if res.StatusCode >= 200 && res.StatusCode < 300 {
+			delivery.Delivered = true
+			delivery.Error = ""
+			return delivery
+		}
+
+		delivery.Error = fmt.Sprintf("response status code %d", res.StatusCode)
+		if res.StatusCode != http.StatusTooManyRequests && res.StatusCode < 500 {
+			return delivery
+		}
+	}
+
+	log.Error("error delivering the alert %s to %s after %d attempts: %s", rule.ID, rule.WebhookURL, delivery.Attempts, delivery.Error)
+	return delivery
+}
+
+// SignWebhook returns the signature of a webhook body, so the receivers can verify it with the secret of their rule
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,168 @@
+package service
+
+import (
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAlertRule_evaluate(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	now := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("threshold rule triggers once when crossed", func(t *testing.T) {
+		rule := AlertRule{ID: "a", Kind: AlertEgldPriceBelow, Threshold: big.NewFloat(100)}
+
+		event, state := rule.evaluate(AlertState{}, big.NewFloat(120), now)
+		assert.Nil(t, event)
+
+		event, state = rule.evaluate(state, big.NewFloat(95), now)
+		require.NotNil(t, event)
+		assert.Equal(t, "95.0000000000", event.Value)
+		assert.Equal(t, "120.0000000000", event.PreviousValue)
+
+		// the price stays below the threshold
+		event, state = rule.evaluate(state, big.NewFloat(90), now)
+		assert.Nil(t, event)
+
+		// the price goes back above and below again
+		_, state = rule.evaluate(state, big.NewFloat(110), now)
+		event, _ = rule.evaluate(state, big.NewFloat(99), now)
+		assert.NotNil(t, event)
+	})
+
+	t.Run("change rule triggers against the reference value", func(t *testing.T) {
+		rule := AlertRule{ID: "b", Kind: AlertMexLockedAPRChange, Threshold: big.NewFloat(5)}
+
+		event, state := rule.evaluate(AlertState{}, big.NewFloat(100), now)
+		assert.Nil(t, event)
+
+		// small moves accumulate until the threshold is exceeded
+		event, state = rule.evaluate(state, big.NewFloat(103), now)
+		assert.Nil(t, event)
+		event, state = rule.evaluate(state, big.NewFloat(94), now)
+		require.NotNil(t, event)
+		assert.Equal(t, "100.0000000000", event.PreviousValue)
+		assert.Equal(t, "94", state.Value.String())
+	})
+}
+
+func TestAlertRule_Validate(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	valid := AlertRule{Kind: AlertProviderAPRBelow, StakingProvider: "istari", Threshold: big.NewFloat(8), WebhookURL: "https://example.com/hook"}
+	assert.NoError(t, valid.Validate())
+
+	noProvider := valid
+	noProvider.StakingProvider = ""
+	assert.Error(t, noProvider.Validate())
+
+	invalidURL := valid
+	invalidURL.WebhookURL = "ftp://example.com"
+	assert.Error(t, invalidURL.Validate())
+
+	invalidKind := valid
+	invalidKind.Kind = "egld-price-equal"
+	assert.Error(t, invalidKind.Validate())
+
+	for _, webhookURL := range []string{
+		"http://localhost:8080/hook",
+		"http://127.0.0.1/hook",
+		"http://10.0.0.1/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data",
+		"http://[::1]/hook",
+		"http://[fd00::1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		internalURL := valid
+		internalURL.WebhookURL = webhookURL
+		assert.ErrorIs(t, internalURL.Validate(), ErrInvalidAlertRule, webhookURL)
+	}
+}
+
+func Test_deliverWebhook(t *testing.T) {
+	webhookRetryBackoff = time.Millisecond
+
+	event := AlertEvent{RuleID: "a", Kind: AlertEgldPriceBelow, Threshold: "100", Value: "95"}
+
+	t.Run("signed delivery after a retry", func(t *testing.T) {
+		var (
+			mutex    sync.Mutex
+			requests int
+		)
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			requests++
+			if requests == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			body, _ := io.ReadAll(r.Body)
+			if r.Header.Get(WebhookSignatureHeader) != SignWebhook("secret", r.Header.Get(WebhookTimestampHeader), body) {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			var received AlertEvent
+			if err := json.Unmarshal(body, &received); err != nil || received.Value != "95" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		delivery := deliverWebhook(receiver.Client(), AlertRule{ID: "a", WebhookURL: receiver.URL, Secret: "secret"}, event)
+		assert.True(t, delivery.Delivered, delivery.Error)
+		assert.Equal(t, 2, delivery.Attempts)
+		assert.Equal(t, http.StatusNoContent, delivery.StatusCode)
+	})
+
+	t.Run("client errors are not retried", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer receiver.Close()
+
+		delivery := deliverWebhook(receiver.Client(), AlertRule{ID: "a", WebhookURL: receiver.URL, Secret: "secret"}, event)
+		assert.False(t, delivery.Delivered)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Equal(t, "response status code 410", delivery.Error)
+	})
+
+	t.Run("unreachable receiver", func(t *testing.T) {
+		receiver := httptest.NewServer(http.NotFoundHandler())
+		receiver.Close()
+
+		delivery := deliverWebhook(receiver.Client(), AlertRule{ID: "a", WebhookURL: receiver.URL, Secret: "secret"}, event)
+		assert.False(t, delivery.Delivered)
+		assert.Equal(t, webhookAttempts, delivery.Attempts)
+		assert.Equal(t, 0, delivery.StatusCode)
+	})
+
+	t.Run("internal receivers are refused when dialing", func(t *testing.T) {
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer receiver.Close()
+
+		delivery := deliverWebhook(webhookClient, AlertRule{ID: "a", WebhookURL: receiver.URL, Secret: "secret"}, event)
+		assert.False(t, delivery.Delivered)
+		assert.Equal(t, 1, delivery.Attempts)
+		assert.Contains(t, delivery.Error, errWebhookAddressNotAllowed.Error())
+	})
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+const (
+	// apiKeysCacheKey is the Redis hash storing the API keys, keyed by the hash of the key
+	apiKeysCacheKey = "api_keys"
+	// apiKeyIDsCacheKey is the Redis hash mapping the IDs of the API keys to the hash of the key
+	apiKeyIDsCacheKey = "api_key_ids"
+
+	// apiKeyPrefix is the prefix of the API keys, which makes them recognizable, e.g. by secret scanners
+	apiKeyPrefix = "iv_"
+)
+
+// the features of the API which are only available to some tiers
+const (
+	// FeatureBatch is the batch profit calculation
+	FeatureBatch = "batch"
+	// FeatureAlerts is the registration of alert rules, whose webhooks are sent by the service
+	FeatureAlerts = "alerts"
+)
+
+// AnonymousTier is the tier of the clients without an API key
+const AnonymousTier = "anonymous"
+
+// APITier defines the limits and the features of the clients
+type APITier struct {
+	Name string `json:"name"`
+	// RequestsPerMinute is the number of requests a client can make in a minute
+	RequestsPerMinute int64 `json:"requests_per_minute"`
+	// BatchMaxItems is the largest number of payloads in a batch, and BatchConcurrency is the number of payloads of a
+	// batch calculated at the same time
+	BatchMaxItems    int `json:"batch_max_items"`
+	BatchConcurrency int `json:"batch_concurrency"`
+	// Features are the features which are only available to some tiers
+	Features []string `json:"features"`
+}
+
+// HasFeature returns true if the feature is available to the tier
+func (t APITier) HasFeature(feature string) bool {
+	for _, tierFeature := range t.Features {
+		if tierFeature == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// APITiers are the tiers of the clients, keyed by their name
+var APITiers = map[string]APITier{
+	AnonymousTier: {Name: AnonymousTier, RequestsPerMinute: 200},
+	"basic": {
+		Name: "basic", RequestsPerMinute: 600, BatchMaxItems: 100, BatchConcurrency: 4,
+		Features: []string{FeatureBatch, FeatureAlerts},
+	},
+	"partner": {
+		Name: "partner", RequestsPerMinute: 3000, BatchMaxItems: 1000, BatchConcurrency: 16,
+		Features: []string{FeatureBatch, FeatureAlerts},
+	},
+}
+
+var (
+	// ErrInvalidAPIKey is returned when an API key is created with invalid fields
+	ErrInvalidAPIKey = errors.New("invalid API key")
+	// ErrAPIKeyNotFound is returned when the API key or its ID doesn't exist
+	ErrAPIKeyNotFound = errors.New("API key not found")
+)
+
+// APIKey is the record of an API key; the key itself is only returned when it is created, and is stored hashed
+type APIKey struct {
+	ID string `json:"id"`
+	// Name identifies the client, e.g. the name of the partner
+	Name      string    `json:"name"`
+	Tier      string    `json:"tier"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// hashAPIKey returns the hash the API key is stored with; the keys are random, so a fast hash can't be reversed by
+// brute force and keeps the lookup of every request cheap
+func hashAPIKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(hash[:])
+}
+
+// CreateAPIKey creates an API key for the client, and returns its record and the key
+func (s *Service) CreateAPIKey(name, tier string) (*APIKey, string, error) {
+	if name == "" {
+		return nil, "", fmt.Errorf("%w: the name is required", ErrInvalidAPIKey)
+	}
+	if _, ok := APITiers[tier]; !ok || tier == AnonymousTier {
+		return nil, "", fmt.Errorf("%w: unknown tier '%s'", ErrInvalidAPIKey, tier)
+	}
+
+	var err error
+	apiKey := &APIKey{
+		Name:      name,
+		Tier:      tier,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	apiKey.ID, err = randomString(8, hex.EncodeToString)
+	if err != nil {
+		log.Error("error generating the API key ID: %s", err)
+		return nil, "", err
+	}
+	key, err := randomString(32, hex.EncodeToString)
+	if err != nil {
+		log.Error("error generating the API key: %s", err)
+		return nil, "", err
+	}
+	key = apiKeyPrefix + key
+
+	data, err := json.Marshal(apiKey)
+	if err != nil {
+		log.Error("error marshalling the API key to JSON: %s", err)
+		return nil, "", err
+	}
+
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	keyHash := hashAPIKey(key)
+	_, err = s.Cache.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HSet(ctx, apiKeysCacheKey, keyHash, data)
+		pipe.HSet(ctx, apiKeyIDsCacheKey, apiKey.ID, keyHash)
+		return nil
+	})
+	if err != nil {
+		log.Error("error storing the API key in the cache: %s", err)
+		return nil, "", err
+	}
+
+	return apiKey, key, nil
+}
+
+// GetAPIKey returns the record of the API key
+func (s *Service) GetAPIKey(key string) (*APIKey, error) {
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	data, err := s.Cache.HGet(ctx, apiKeysCacheKey, hashAPIKey(key)).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, ErrAPIKeyNotFound
+		}
+		log.Error("error retrieving the API key from the cache: %s", err)
+		return nil, err
+	}
+
+	var apiKey APIKey
+	if err := json.Unmarshal([]byte(data), &apiKey); err != nil {
+		log.Error("error unmarshalling the API key: %s", err)
+		return nil, err
+	}
+
+	return &apiKey, nil
+}
+
+// ListAPIKeys returns the records of all the API keys, the oldest first
+func (s *Service) ListAPIKeys() ([]APIKey, error) {
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	keys, err := s.Cache.HGetAll(ctx, apiKeysCacheKey).Result()
+	if err != nil {
+		log.Error("error retrieving the API keys from the cache: %s", err)
+		return nil, err
+	}
+
+	apiKeys := make([]APIKey, 0, len(keys))
+	for keyHash, data := range keys {
+		var apiKey APIKey
+		if err := json.Unmarshal([]byte(data), &apiKey); err != nil {
+			log.Error("error unmarshalling the API key %s: %s", keyHash, err)
+			continue
+		}
+		apiKeys = append(apiKeys, apiKey)
+	}
+	sort.Slice(apiKeys, func(i, j int) bool {
+		if !apiKeys[i].CreatedAt.Equal(apiKeys[j].CreatedAt) {
+			return apiKeys[i].CreatedAt.Before(apiKeys[j].CreatedAt)
+		}
+		return apiKeys[i].ID < apiKeys[j].ID
+	})
+
+	return apiKeys, nil
+}
+
+// DeleteAPIKey revokes the API key with the given ID
+func (s *Service) DeleteAPIKey(id string) error {
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	keyHash, err := s.Cache.HGet(ctx, apiKeyIDsCacheKey, id).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return fmt.Errorf("%w: '%s'", ErrAPIKeyNotFound, id)
+		}
+		log.Error("error retrieving the API key %s from the cache: %s", id, err)
+		return err
+	}
+
+	_, err = s.Cache.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.HDel(ctx, apiKeysCacheKey, keyHash)
+		pipe.HDel(ctx, apiKeyIDsCacheKey, id)
+		return nil
+	})
+	if err != nil {
+		log.Error("error deleting the API key %s from the cache: %s", id, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITier_HasFeature(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	assert.False(t, APITiers[AnonymousTier].HasFeature(FeatureBatch))
+	assert.True(t, APITiers["basic"].HasFeature(FeatureBatch))
+	assert.True(t, APITiers["partner"].HasFeature(FeatureBatch))
+	assert.False(t, APITiers[AnonymousTier].HasFeature(FeatureAlerts))
+	assert.True(t, APITiers["basic"].HasFeature(FeatureAlerts))
+
+	// every tier is keyed by its name
+	for name, tier := range APITiers {
+		assert.Equal(t, name, tier.Name)
+	}
+}
+
+func TestService_CreateAPIKey(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// the invalid keys are rejected before reaching the cache
+	s := &Service{}
+	for _, tt := range []struct {
+		name, tier string
+	}{
+		{"", "basic"},
+		{"agency", "unknown"},
+		{"agency", AnonymousTier},
+	} {
+		_, _, err := s.CreateAPIKey(tt.name, tt.tier)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	}
+}
+
+func Test_hashAPIKey(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	assert.Equal(t, "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08", hashAPIKey("test"))
+	assert.NotEqual(t, hashAPIKey("iv_a"), hashAPIKey("iv_b"))
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"math/big"
+	"time"
+)
+
+// The staking rewards of EGLD come from the protocol inflation, which decreases every year since the genesis, shared by
+// the staked EGLD. The APR received for each EGLD staked is then proportional to inflationRate / stakingRatio, so the
+// APR of today is projected for the following years by scaling it with the inflation schedule and the assumed staking
+// ratio.
+
+var (
+	// EgldGenesisDate is the date the MultiversX mainnet started, when the first inflation year begins
+	EgldGenesisDate = time.Date(2020, time.July, 30, 0, 0, 0, 0, time.UTC)
+
+	// EgldInflationSchedule contains the yearly inflation rate of EGLD as percentage, for each year since the genesis;
+	// there is no inflation after the last year
+	EgldInflationSchedule = []float64{10.84, 9.70, 8.56, 7.42, 6.27, 5.13, 3.99, 2.85, 1.71, 0.57}
+
+	// DefaultEgldStakingRatio is the percentage of the EGLD supply assumed to be staked if the input doesn't provide it
+	DefaultEgldStakingRatio = big.NewFloat(60)
+
+	minEgldStakingRatio = big.NewFloat(1)
+)
+
+// APRPeriod represents an interval of the investment during which the projected APR is constant
+type APRPeriod struct {
+	// StartDay and EndDay are the days of the investment between which the period lasts
+	StartDay  int
+	EndDay    int
+	StartDate time.Time
+	EndDate   time.Time
+	// APR is the projected APR as percentage
+	APR *big.Float
+	// InflationRate is the yearly inflation rate of the protocol as percentage
+	InflationRate *big.Float
+	// StakingRatio is the assumed percentage of the supply which is staked
+	StakingRatio *big.Float
+}
+
+// APRCurve is the projection of the APR over the investment, as consecutive periods starting with day 0
+type APRCurve []APRPeriod
+
+// egldInflationRate returns the inflation rate as percentage at the given date
+func egldInflationRate(date time.Time) *big.Float {
+	year := inflationYear(date)
+	if year < 0 || year >= len(EgldInflationSchedule) {
+		return &big.Float{}
+	}
+	return big.NewFloat(EgldInflationSchedule[year])
+}
+
+// inflationYear returns the index of the inflation year since the genesis which contains the given date
+func inflationYear(date time.Time) int {
+	year := date.Year() - EgldGenesisDate.Year()
+	if date.Before(EgldGenesisDate.AddDate(year, 0, 0)) {
+		year--
+	}
+	return year
+}
+
+// ProjectEgldAPR returns the APR curve over the investment of the input, starting from its EgldAPR. The investment is
+// split at every inflation year change, and the staking ratio changes by EgldStakingRatioChangePerYear percentage
+// points every year. If the current inflation is 0 the APR can't be scaled, so it is kept constant and no curve is
+// returned.
+func ProjectEgldAPR(input *StrategiesInput) APRCurve {
+	if input.EgldAPR == nil || input.InvestmentDurationInDays <= 0 {
+		return nil
+	}
+
+	initialStakingRatio := input.EgldStakingRatio
+	if initialStakingRatio == nil || initialStakingRatio.Sign() <= 0 {
+		initialStakingRatio = DefaultEgldStakingRatio
+	}
+	initialInflation := egldInflationRate(input.StartDate)
+	if initialInflation.Sign() <= 0 {
+		return nil
+	}
+
+	var curve APRCurve
+	startDay := 0
+	for startDay < input.InvestmentDurationInDays {
+		periodStart := input.StartDate.AddDate(0, 0, startDay)
+
+		// the period lasts until the next inflation year or the end of the investment
+		nextInflationYear := EgldGenesisDate.AddDate(inflationYear(periodStart)+1, 0, 0)
+		endDay := startDay + int(nextInflationYear.Sub(periodStart).Hours()/24)
+		if endDay > input.InvestmentDurationInDays {
+			endDay = input.InvestmentDurationInDays
+		}
+
+		// stakingRatio = initialStakingRatio + change * startDay / 365, kept in [minEgldStakingRatio, 100]
+		stakingRatio := &big.Float{}
+		if input.EgldStakingRatioChangePerYear != nil {
+			stakingRatio.Mul(input.EgldStakingRatioChangePerYear, big.NewFloat(float64(startDay)))
+			stakingRatio.Quo(stakingRatio, BigFloatDaysInYear)
+		}
+		stakingRatio.Add(stakingRatio, initialStakingRatio)
+		if stakingRatio.Cmp(minEgldStakingRatio) < 0 {
+			stakingRatio.Copy(minEgldStakingRatio)
+		} else if stakingRatio.Cmp(BigFloatOneHundred) > 0 {
+			stakingRatio.Copy(BigFloatOneHundred)
+		}
+
+		inflation := egldInflationRate(periodStart)
+
+		// APR = initialAPR * (inflation / initialInflation) * (initialStakingRatio / stakingRatio)
+		apr := &big.Float{}
+		apr.Mul(input.EgldAPR, inflation)
+		apr.Quo(apr, initialInflation)
+		apr.Mul(apr, initialStakingRatio)
+		apr.Quo(apr, stakingRatio)
+
+		curve = append(curve, APRPeriod{
+			StartDay:      startDay,
+			EndDay:        endDay,
+			StartDate:     periodStart,
+			EndDate:       input.StartDate.AddDate(0, 0, endDay),
+			APR:           apr,
+			InflationRate: inflation,
+			StakingRatio:  stakingRatio,
+		})
+		startDay = endDay
+	}
+
+	return curve
+}
+
+// RewardsRate returns the rewards received between fromDay and toDay for each token staked, as the sum of the APR of
+// each period multiplied by the fraction of the year it lasts; fallbackAPR is used for the days the curve doesn't
+// cover
+func (c APRCurve) RewardsRate(fromDay, toDay int, fallbackAPR *big.Float) *big.Float {
+	rate := &big.Float{}
+	day := fromDay
+	for _, period := range c {
+		if day >= toDay {
+			break
+		}
+		if period.EndDay <= day {
+			continue
+		}
+
+		periodEnd := period.EndDay
+		if periodEnd > toDay {
+			periodEnd = toDay
+		}
+		rate.Add(rate, periodRewardsRate(period.APR, periodEnd-day))
+		day = periodEnd
+	}
+
+	if day < toDay {
+		rate.Add(rate, periodRewardsRate(fallbackAPR, toDay-day))
+	}
+
+	return rate
+}
+
+// periodRewardsRate returns apr * days / 365 / 100
+func periodRewardsRate(apr *big.Float, days int) *big.Float {
+	rate := &big.Float{}
+	rate.Mul(apr, big.NewFloat(float64(days)))
+	rate.Quo(rate, BigFloatDaysInYear)
+	return rate.Quo(rate, BigFloatOneHundred)
+}
+
+// egldAPRCurve returns the APR curve of the input if the token is EGLD, as the APR of the other tokens is constant
+func egldAPRCurve(tokenType TokenType, input *StrategiesInput) APRCurve {
+	if tokenType != TokenTypeEgld {
+		return nil
+	}
+	return input.EgldAPRCurve
+}
+
+// from returns the curve starting at the given day, with the days counted from it
+func (c APRCurve) from(day int) APRCurve {
+	var curve APRCurve
+	for _, period := range c {
+		if period.EndDay <= day {
+			continue
+		}
+
+		shifted := period
+		if shifted.StartDay < day {
+			shifted.StartDate = shifted.StartDate.AddDate(0, 0, day-shifted.StartDay)
+			shifted.StartDay = day
+		}
+		shifted.StartDay -= day
+		shifted.EndDay -= day
+		curve = append(curve, shifted)
+	}
+	return curve
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProjectEgldAPR(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	startDate := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("the APR follows the inflation schedule", func(t *testing.T) {
+		input := &StrategiesInput{EgldAPR: big.NewFloat(10), InvestmentDurationInDays: 730, StartDate: startDate}
+
+		curve := ProjectEgldAPR(input)
+		require.Len(t, curve, 3)
+
+		// the second inflation year ends on 2022-07-30
+		assert.Equal(t, 0, curve[0].StartDay)
+		assert.Equal(t, 151, curve[0].EndDay)
+		assert.Equal(t, "2022-07-30", curve[0].EndDate.Format(DateFormat))
+		assert.Equal(t, "10.0000", curve[0].APR.Text('f', 4))
+		assert.Equal(t, "9.70", curve[0].InflationRate.Text('f', 2))
+
+		assert.Equal(t, 516, curve[1].EndDay)
+		assert.Equal(t, "8.8247", curve[1].APR.Text('f', 4))
+
+		assert.Equal(t, 730, curve[2].EndDay)
+		assert.Equal(t, "2024-02-29", curve[2].EndDate.Format(DateFormat))
+		assert.Equal(t, "7.6495", curve[2].APR.Text('f', 4))
+	})
+
+	t.Run("the APR decreases when the staking ratio increases", func(t *testing.T) {
+		input := &StrategiesInput{
+			EgldAPR:                       big.NewFloat(10),
+			EgldStakingRatio:              big.NewFloat(50),
+			EgldStakingRatioChangePerYear: big.NewFloat(10),
+			InvestmentDurationInDays:      730,
+			StartDate:                     time.Date(2022, time.July, 30, 0, 0, 0, 0, time.UTC),
+		}
+
+		curve := ProjectEgldAPR(input)
+		require.Len(t, curve, 2)
+		assert.Equal(t, "10.0000", curve[0].APR.Text('f', 4))
+
+		// the staking ratio is 60% after one year, when the inflation drops from 8.56% to 7.42%
+		assert.Equal(t, 365, curve[1].StartDay)
+		assert.Equal(t, "60.00", curve[1].StakingRatio.Text('f', 2))
+		assert.Equal(t, "7.2235", curve[1].APR.Text('f', 4))
+	})
+
+	t.Run("no inflation left", func(t *testing.T) {
+		input := &StrategiesInput{
+			EgldAPR:                  big.NewFloat(10),
+			InvestmentDurationInDays: 365,
+			StartDate:                time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		// the APR is constant
+		assert.Empty(t, ProjectEgldAPR(input))
+	})
+}
+
+func TestAPRCurve_RewardsRate(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	curve := APRCurve{
+		{StartDay: 0, EndDay: 73, APR: big.NewFloat(10)},
+		{StartDay: 73, EndDay: 146, APR: big.NewFloat(5)},
+	}
+
+	// 10% for 73 days and 5% for 73 days
+	assert.Equal(t, "0.0300", curve.RewardsRate(0, 146, big.NewFloat(0)).Text('f', 4))
+	assert.Equal(t, "0.0100", curve.RewardsRate(73, 146, big.NewFloat(0)).Text('f', 4))
+
+	// the fallback APR is used after the end of the curve
+	assert.Equal(t, "0.0300", curve.RewardsRate(73, 219, big.NewFloat(10)).Text('f', 4))
+}
+
+func TestService_StakeStrategy_APRCurve(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	service := Service{}
+	input := &StrategiesInput{
+		EgldTokensInvested:       big.NewFloat(100),
+		EgldTargetPrice:          big.NewFloat(100),
+		EgldAPR:                  big.NewFloat(10),
+		InvestmentDurationInDays: 730,
+		StartDate:                time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	constantResult, err := service.StakeStrategy(TokenTypeEgld, input, big.NewFloat(100))
+	require.NoError(t, err)
+	assert.Empty(t, constantResult.AssumedAPRCurve)
+
+	input.EgldAPRCurve = ProjectEgldAPR(input)
+	projectedResult, err := service.StakeStrategy(TokenTypeEgld, input, big.NewFloat(100))
+	require.NoError(t, err)
+
+	assert.Len(t, projectedResult.AssumedAPRCurve, 3)
+	assert.Equal(t, -1, projectedResult.ProfitInEgld.Cmp(constantResult.ProfitInEgld),
+		"expected the projected rewards %v to be lower than the constant APR rewards %v",
+		projectedResult.ProfitInEgld, constantResult.ProfitInEgld)
+
+	redelegateInput := *input
+	redelegateInput.RedelegationIntervalInDays = 7
+	redelegateResult, err := service.RedelegateStrategy(TokenTypeEgld, &redelegateInput, big.NewFloat(100))
+	require.NoError(t, err)
+	assert.Equal(t, 1, redelegateResult.ProfitInEgld.Cmp(projectedResult.ProfitInEgld),
+		"expected the redelegated rewards %v to be larger than the staking rewards %v",
+		redelegateResult.ProfitInEgld, projectedResult.ProfitInEgld)
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+// BacktestPeriod returns the dates between which the history of the backtest of the input has to be loaded: the days
+// of the investment and, as the recording may miss its first day, the week before it
+func BacktestPeriod(input *StrategiesInput) (time.Time, time.Time) {
+	return input.StartDate.AddDate(0, 0, -historyLookbackInDays), input.StartDate.AddDate(0, 0, input.InvestmentDurationInDays)
+}
+
+// Backtest returns what the strategies would have earned for the investment of the input started at its StartDate in
+// the past, replaying the recorded history: the prices at the start and at the end of the investment replace the
+// current and the target prices, the recorded APRs of the staking provider replace its current APR day by day, and the
+// MEX APRs are averaged over the investment. The strategies which need the pools and farms state, which is not
+// recorded, are skipped.
+func (s *Service) Backtest(input *StrategiesInput, history *History) (map[string]StrategyResultJSON, error) {
+	result := make(map[string]StrategyResultJSON)
+
+	endDate := input.StartDate.AddDate(0, 0, input.InvestmentDurationInDays)
+	if input.StartDate.IsZero() || endDate.After(startOfDay(time.Now())) {
+		return result, fmt.Errorf("%w: the backtested investment must end before today", ErrInsufficientHistory)
+	}
+
+	startDay, ok := history.Day(input.StartDate)
+	if !ok {
+		return result, fmt.Errorf("%w: no day recorded at %s", ErrInsufficientHistory, input.StartDate.Format(DateFormat))
+	}
+	endDay, ok := history.Day(endDate)
+	if !ok {
+		return result, fmt.Errorf("%w: no day recorded at %s", ErrInsufficientHistory, endDate.Format(DateFormat))
+	}
+
+	startAPR, ok := startDay.ProviderAPR(input.StakingProvider)
+	if !ok {
+		return result, fmt.Errorf("%w: no APR recorded for %s at %s", ErrInsufficientHistory, input.StakingProvider, startDay.Date)
+	}
+
+	aprCurve, mexAPRLocked, mexAPRUnlocked, err := replayAPRs(input, history)
+	if err != nil {
+		return result, err
+	}
+
+	economics := Economics{
+		Prices: Prices{
+			EGLD: startDay.EgldPrice.Text('f', -1),
+			MEX:  startDay.MexPrice.Text('f', -1),
+		},
+		mexEconomics: fetcher.MexEconomics{
+			Price:              startDay.MexPrice,
+			LockedRewardsAPR:   mexAPRLocked,
+			UnlockedRewardsAPR: mexAPRUnlocked,
+		},
+	}
+	startAPRFloat, _ := startAPR.Float64()
+	providers := []fetcher.EgldStakingProvider{{Identity: input.StakingProvider, APR: startAPRFloat}}
+
+	input.EgldTargetPrice = endDay.EgldPrice
+	input.MexTargetPrice = endDay.MexPrice
+	input.History = history
+
+	strategies, err := s.Registry().Select(input.Strategies)
+	if err != nil {
+		log.Error("error selecting the strategies to be backtested: %s", err)
+		return result, err
+	}
+
+	egldInitialPrice, mexInitialPrice, err := s.prepareStrategiesInput(input, providers, economics)
+	if err != nil {
+		return result, err
+	}
+	input.EgldAPRCurve = aprCurve
+
+	results, err := s.runStrategies(strategies, input, economics, egldInitialPrice, mexInitialPrice)
+	if err != nil {
+		return result, err
+	}
+
+	for key, strategyResult := range results {
+		result[key] = strategyResult.MarshallToJSON()
+	}
+
+	return result, nil
+}
+
+// replayAPRs returns the curve of the APRs of the staking provider recorded for each day of the investment, and the
+// average MEX locked and unlocked APRs
+func replayAPRs(input *StrategiesInput, history *History) (APRCurve, *big.Float, *big.Float, error) {
+	var curve APRCurve
+	mexAPRLocked, mexAPRUnlocked := &big.Float{}, &big.Float{}
+
+	for dayIndex := 0; dayIndex < input.InvestmentDurationInDays; dayIndex++ {
+		date := input.StartDate.AddDate(0, 0, dayIndex)
+		day, ok := history.Day(date)
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("%w: no day recorded at %s", ErrInsufficientHistory, date.Format(DateFormat))
+		}
+
+		apr, ok := day.ProviderAPR(input.StakingProvider)
+		if !ok {
+			return nil, nil, nil, fmt.Errorf("%w: no APR recorded for %s at %s", ErrInsufficientHistory, input.StakingProvider, day.Date)
+		}
+
+		if day.MexAPRLocked != nil {
+			mexAPRLocked.Add(mexAPRLocked, day.MexAPRLocked)
+		}
+		if day.MexAPRUnlocked != nil {
+			mexAPRUnlocked.Add(mexAPRUnlocked, day.MexAPRUnlocked)
+		}
+
+		// the consecutive days with the same APR are merged in one period
+		if last := len(curve) - 1; last >= 0 && curve[last].APR.Cmp(apr) == 0 {
+			curve[last].EndDay = dayIndex + 1
+			curve[last].EndDate = date.AddDate(0, 0, 1)
+			continue
+		}
+		curve = append(curve, APRPeriod{
+			StartDay:  dayIndex,
+			EndDay:    dayIndex + 1,
+			StartDate: date,
+			EndDate:   date.AddDate(0, 0, 1),
+			APR:       apr,
+		})
+	}
+
+	if input.InvestmentDurationInDays > 0 {
+		days := big.NewFloat(float64(input.InvestmentDurationInDays))
+		mexAPRLocked.Quo(mexAPRLocked, days)
+		mexAPRUnlocked.Quo(mexAPRUnlocked, days)
+	}
+
+	return curve, mexAPRLocked, mexAPRUnlocked, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Backtest(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// test setup
+	service := Service{}
+	startDate := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	// the EGLD price rises from 100 to 200 USD and the APR doubles after two weeks
+	var days []HistoricalDay
+	for i := 0; i <= 28; i++ {
+		apr := big.NewFloat(36.5)
+		if i >= 14 {
+			apr = big.NewFloat(73)
+		}
+		days = append(days, HistoricalDay{
+			Date:           startDate.AddDate(0, 0, i).Format(DateFormat),
+			EgldPrice:      big.NewFloat(100 + 100*float64(i)/28),
+			MexPrice:       big.NewFloat(0.0002),
+			EgldAPR:        map[string]*big.Float{AnyStakingProvider: apr},
+			MexAPRLocked:   big.NewFloat(100),
+			MexAPRUnlocked: big.NewFloat(40),
+		})
+	}
+	history := NewHistory(days)
+
+	newInput := func() *StrategiesInput {
+		return &StrategiesInput{
+			EgldTokensInvested:          big.NewFloat(10),
+			MexTokensInvested:           &big.Float{},
+			PercentageOfPortfolioInEgld: big.NewFloat(100),
+			PercentageOfPortfolioInMex:  &big.Float{},
+			EgldTargetPrice:             &big.Float{},
+			MexTargetPrice:              &big.Float{},
+			EgldAPR:                     &big.Float{},
+			MexAPRLocked:                &big.Float{},
+			MexAPRUnlocked:              &big.Float{},
+			InvestmentDurationInDays:    28,
+			RedelegationIntervalInDays:  7,
+			StakingProvider:             "istari",
+			StartDate:                   startDate,
+			Strategies:                  []string{"hold", "stake"},
+		}
+	}
+
+	t.Run("replayed prices and APRs", func(t *testing.T) {
+		results, err := service.Backtest(newInput(), history)
+		require.NoError(t, err)
+
+		assert.Equal(t, "2000.0000000000", results["egld_hold"].TotalBalanceInUsd)
+
+		// 0.1% per day for 14 days and 0.2% per day for 14 days
+		stake := results["egld_stake"]
+		assert.Equal(t, "10.4200000000", stake.TotalBalanceInEgld)
+		require.Len(t, stake.AssumedAPRCurve, 2)
+		assert.Equal(t, "2022-03-15", stake.AssumedAPRCurve[1].StartDate)
+	})
+
+	t.Run("contributions at the recorded prices", func(t *testing.T) {
+		input := newInput()
+		input.Strategies = []string{"hold"}
+		input.Contribution = &Contribution{TokenType: TokenTypeEgld, Amount: big.NewFloat(100), AmountInUsd: true, Frequency: ContributionWeekly}
+
+		results, err := service.Backtest(input, history)
+		require.NoError(t, err)
+
+		// 100 USD are invested at 125, 150 and 175 USD on the days 7, 14 and 21
+		assert.Equal(t, "12.0380952381", results["egld_hold"].TotalContributedInTokens)
+	})
+
+	t.Run("insufficient history", func(t *testing.T) {
+		input := newInput()
+		input.InvestmentDurationInDays = 60
+
+		_, err := service.Backtest(input, history)
+		assert.True(t, errors.Is(err, ErrInsufficientHistory), "expected ErrInsufficientHistory, got %v", err)
+
+		input = newInput()
+		input.StartDate = startOfDay(time.Now())
+
+		_, err = service.Backtest(input, history)
+		assert.True(t, errors.Is(err, ErrInsufficientHistory), "expected ErrInsufficientHistory, got %v", err)
+	})
+}
+
+func TestBacktestPeriod(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	input := &StrategiesInput{
+		InvestmentDurationInDays: 28,
+		StartDate:                time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	// the period covers the last day recorded before the start the first day can fall back to
+	from, to := BacktestPeriod(input)
+	assert.Equal(t, "2022-02-22", from.Format(DateFormat))
+	assert.Equal(t, "2022-03-29", to.Format(DateFormat))
+
+	history := NewHistory([]HistoricalDay{{Date: "2022-02-23"}})
+	day, ok := history.Day(input.StartDate)
+	require.True(t, ok)
+	assert.False(t, day.Date < from.Format(DateFormat))
+}
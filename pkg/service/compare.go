@@ -0,0 +1,138 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+// MaxCompareVariants is the largest number of variants compared at once
+const MaxCompareVariants = 10
+
+// ErrInvalidComparison is returned when the compared variants are invalid
+var ErrInvalidComparison = errors.New("invalid comparison")
+
+// CompareVariant is a named configuration of the strategies to be compared
+type CompareVariant struct {
+	Name  string
+	Input *StrategiesInput
+}
+
+// ComparisonRowJSON is the result of a strategy for a variant, ranked against the results of all the variants
+type ComparisonRowJSON struct {
+	Variant string
+	// Strategy is the key of the strategy result, e.g. egld_stake
+	Strategy          string
+	TotalBalanceInUsd string
+	ROI               string
+	// Rank is the position of the row ordered by ROI, starting at 1
+	Rank int
+	// DeltaBalanceInUsd and DeltaROI are the differences with the same strategy of the baseline variant; they are
+	// empty if the baseline doesn't have the strategy
+	DeltaBalanceInUsd string `json:",omitempty"`
+	DeltaROI          string `json:",omitempty"`
+}
+
+// ComparisonJSON is the result of the comparison of the variants
+type ComparisonJSON struct {
+	Baseline string
+	// Rows contains the results of all the strategies of all the variants, ordered by rank
+	Rows []ComparisonRowJSON
+	// Results maps the names of the variants to their complete results
+	Results map[string]map[string]StrategyResultJSON
+}
+
+// Compare calculates the strategies of every variant against the same market data, and ranks their results by ROI,
+// as the variants may invest different amounts; the deltas are computed against the baseline variant, or the first
+// variant if baseline is empty
+func (s *Service) Compare(variants []CompareVariant, baseline string, egldStakingProviders []fetcher.EgldStakingProvider, economics Economics) (*ComparisonJSON, error) {
+	if len(variants) == 0 || len(variants) > MaxCompareVariants {
+		return nil, fmt.Errorf("%w: between 1 and %d variants can be compared", ErrInvalidComparison, MaxCompareVariants)
+	}
+	if baseline == "" {
+		baseline = variants[0].Name
+	}
+
+	names := make(map[string]bool, len(variants))
+	for _, variant := range variants {
+		if variant.Name == "" || names[variant.Name] {
+			return nil, fmt.Errorf("%w: the variants must have unique non-empty names", ErrInvalidComparison)
+		}
+		names[variant.Name] = true
+	}
+	if !names[baseline] {
+		return nil, fmt.Errorf("%w: unknown baseline variant '%s'", ErrInvalidComparison, baseline)
+	}
+
+	type row struct {
+		variant, strategy string
+		result            *StrategyResult
+	}
+
+	comparison := &ComparisonJSON{
+		Baseline: baseline,
+		Results:  make(map[string]map[string]StrategyResultJSON, len(variants)),
+	}
+	var rows []row
+	baselineResults := make(map[string]*StrategyResult)
+
+	for _, variant := range variants {
+		strategies, err := s.Registry().Select(variant.Input.Strategies)
+		if err != nil {
+			log.Error("error selecting the strategies of the variant %s: %s", variant.Name, err)
+			return nil, fmt.Errorf("variant '%s': %w", variant.Name, err)
+		}
+
+		egldInitialPrice, mexInitialPrice, err := s.prepareStrategiesInput(variant.Input, egldStakingProviders, economics)
+		if err != nil {
+			return nil, fmt.Errorf("variant '%s': %w", variant.Name, err)
+		}
+
+		results, err := s.runStrategies(strategies, variant.Input, economics, egldInitialPrice, mexInitialPrice)
+		if err != nil {
+			return nil, fmt.Errorf("variant '%s': %w", variant.Name, err)
+		}
+
+		keys := make([]string, 0, len(results))
+		for key := range results {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		comparison.Results[variant.Name] = make(map[string]StrategyResultJSON, len(results))
+		for _, key := range keys {
+			result := results[key]
+			comparison.Results[variant.Name][key] = result.MarshallToJSON()
+			rows = append(rows, row{variant: variant.Name, strategy: key, result: result})
+			if variant.Name == baseline {
+				baselineResults[key] = result
+			}
+		}
+	}
+
+	// the rows with the same ROI keep the order of the variants and of the strategies keys
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].result.ROI.Cmp(rows[j].result.ROI) > 0
+	})
+
+	for i, r := range rows {
+		comparisonRow := ComparisonRowJSON{
+			Variant:           r.variant,
+			Strategy:          r.strategy,
+			TotalBalanceInUsd: r.result.TotalBalanceInUsd.Text('f', FloatingPointAccuracy),
+			ROI:               r.result.ROI.Text('f', FloatingPointAccuracy),
+			Rank:              i + 1,
+		}
+		if baselineResult, ok := baselineResults[r.strategy]; ok {
+			comparisonRow.DeltaBalanceInUsd = new(big.Float).Sub(r.result.TotalBalanceInUsd, baselineResult.TotalBalanceInUsd).Text('f', FloatingPointAccuracy)
+			comparisonRow.DeltaROI = new(big.Float).Sub(r.result.ROI, baselineResult.ROI).Text('f', FloatingPointAccuracy)
+		}
+		comparison.Rows = append(comparison.Rows, comparisonRow)
+	}
+
+	return comparison, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Compare(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// test setup
+	service := Service{}
+	economics := Economics{
+		Prices: Prices{EGLD: "250"},
+		mexEconomics: fetcher.MexEconomics{
+			LockedRewardsAPR:   big.NewFloat(100),
+			UnlockedRewardsAPR: big.NewFloat(40),
+			Price:              big.NewFloat(0.0002),
+		},
+	}
+	providers := []fetcher.EgldStakingProvider{{APR: 12, Identity: "istari"}, {APR: 8, Identity: "other"}}
+
+	newInput := func(provider string, egldTokens float64) *StrategiesInput {
+		return &StrategiesInput{
+			EgldTokensInvested:          big.NewFloat(egldTokens),
+			MexTokensInvested:           &big.Float{},
+			PercentageOfPortfolioInEgld: big.NewFloat(100),
+			PercentageOfPortfolioInMex:  &big.Float{},
+			EgldTargetPrice:             big.NewFloat(250),
+			MexTargetPrice:              big.NewFloat(0.0002),
+			EgldAPR:                     &big.Float{},
+			MexAPRLocked:                &big.Float{},
+			MexAPRUnlocked:              &big.Float{},
+			InvestmentDurationInDays:    365,
+			RedelegationIntervalInDays:  7,
+			StakingProvider:             provider,
+			StartDate:                   time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Strategies:                  []string{"stake"},
+		}
+	}
+
+	t.Run("ranking and deltas", func(t *testing.T) {
+		comparison, err := service.Compare([]CompareVariant{
+			{Name: "other", Input: newInput("other", 10)},
+			{Name: "istari", Input: newInput("istari", 20)},
+		}, "", providers, economics)
+		require.NoError(t, err)
+
+		assert.Equal(t, "other", comparison.Baseline)
+		require.Len(t, comparison.Rows, 2)
+		assert.Len(t, comparison.Results, 2)
+
+		// 12% APR on 20 EGLD ranks before 8% APR on 10 EGLD
+		best := comparison.Rows[0]
+		assert.Equal(t, "istari", best.Variant)
+		assert.Equal(t, "egld_stake", best.Strategy)
+		assert.Equal(t, 1, best.Rank)
+		assert.Equal(t, "12.0000000000", best.ROI)
+		assert.Equal(t, "2900.0000000000", best.DeltaBalanceInUsd)
+		assert.Equal(t, "4.0000000000", best.DeltaROI)
+
+		assert.Equal(t, "0.0000000000", comparison.Rows[1].DeltaROI)
+	})
+
+	t.Run("invalid variants", func(t *testing.T) {
+		_, err := service.Compare(nil, "", providers, economics)
+		assert.True(t, errors.Is(err, ErrInvalidComparison), "expected ErrInvalidComparison, got %v", err)
+
+		_, err = service.Compare([]CompareVariant{
+			{Name: "a", Input: newInput("istari", 10)},
+			{Name: "a", Input: newInput("other", 10)},
+		}, "", providers, economics)
+		assert.True(t, errors.Is(err, ErrInvalidComparison), "expected ErrInvalidComparison, got %v", err)
+
+		_, err = service.Compare([]CompareVariant{{Name: "a", Input: newInput("istari", 10)}}, "b", providers, economics)
+		assert.True(t, errors.Is(err, ErrInvalidComparison), "expected ErrInvalidComparison, got %v", err)
+	})
+}
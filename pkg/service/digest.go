@@ -0,0 +1,505 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"math/big"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/notifier"
+)
+
+const (
+	// digestSubscriptionsCacheKey is the Redis hash storing the digest subscriptions, keyed by their ID
+	digestSubscriptionsCacheKey = "digest_subscriptions"
+	// digestSentCacheKeyPrefix is the prefix of the keys marking the weeks whose digests were sent
+	digestSentCacheKeyPrefix = "digest_sent_"
+
+	// DigestWeekday and DigestHour are when the weekly digests are sent, in UTC
+	DigestWeekday = time.Monday
+	DigestHour    = 8
+
+	// DigestConfirmationTTL is how long a subscription waits for the confirmation of its email address before it is
+	// dropped
+	DigestConfirmationTTL = 48 * time.Hour
+	// MaxDigestSubscriptionsPerEmail is the number of subscriptions of an email address, confirmed or waiting for
+	// their confirmation
+	MaxDigestSubscriptionsPerEmail = 5
+)
+
+var (
+	// ErrInvalidDigestSubscription is returned when a digest subscription can't be registered
+	ErrInvalidDigestSubscription = errors.New("invalid digest subscription")
+	// ErrDigestSubscriptionNotFound is returned when the digest subscription doesn't exist
+	ErrDigestSubscriptionNotFound = errors.New("digest subscription not found")
+	// ErrDigestUnavailable is returned when the service has no notifier
+	ErrDigestUnavailable = errors.New("the digests are unavailable")
+	// ErrDigestSubscriptionsLimit is returned when the email address has MaxDigestSubscriptionsPerEmail subscriptions
+	ErrDigestSubscriptionsLimit = errors.New("too many digest subscriptions")
+
+	//go:embed templates/digest.html templates/digest_confirmation.html
+	digestTemplates            embed.FS
+	digestTemplate             = template.Must(template.ParseFS(digestTemplates, "templates/digest.html"))
+	digestConfirmationTemplate = template.Must(template.ParseFS(digestTemplates, "templates/digest_confirmation.html"))
+)
+
+// DigestSubscription is a weekly digest of a saved scenario sent to an email address
+type DigestSubscription struct {
+	ID         string
+	Email      string
+	ScenarioID string
+	// Token authorizes confirming and unsubscribing, from the links of the emails
+	Token string
+	// Confirmed is set when the link of the confirmation email is followed; only the confirmed subscriptions receive
+	// the digests
+	Confirmed bool
+	CreatedAt time.Time
+}
+
+// expired returns true if the subscription was not confirmed within DigestConfirmationTTL
+func (d *DigestSubscription) expired(now time.Time) bool {
+	return !d.Confirmed && now.Sub(d.CreatedAt) > DigestConfirmationTTL
+}
+
+// DigestConfirmation is the content of the email confirming a digest subscription
+type DigestConfirmation struct {
+	ScenarioID     string
+	ConfirmURL     string
+	ExpiresInHours int
+}
+
+// DigestStrategy compares the saved and the current projection of a strategy of the scenario
+type DigestStrategy struct {
+	Strategy string
+	// SavedBalanceInUsd is empty if the strategy was not calculated when the scenario was saved
+	SavedBalanceInUsd   string
+	CurrentBalanceInUsd string
+	ROI                 string
+}
+
+// Digest is the content of the weekly digest of a scenario
+type Digest struct {
+	ScenarioID string
+	EgldPrice  string
+	// EgldTargetPrice is empty if the scenario has no target price
+	EgldTargetPrice         string
+	PriceToTargetPercentage string
+	StakingProvider         string
+	CurrentAPR              string
+	// SavedAPR is the APR of the staking provider when the scenario was saved; APRDrift is the change since then
+	SavedAPR   string
+	APRDrift   string
+	Strategies []DigestStrategy
+
+	ScenarioURL    string
+	UnsubscribeURL string
+}
+
+// SubscribeDigest registers a weekly digest of the saved scenario for the email address, and sends the email with the
+// link confirming it; the digests are only sent once the subscription is confirmed
+func (s *Service) SubscribeDigest(email, scenarioID string) (*DigestSubscription, error) {
+	if s.Notifier == nil {
+		return nil, ErrDigestUnavailable
+	}
+
+	address, err := mail.ParseAddress(email)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid email address '%s'", ErrInvalidDigestSubscription, email)
+	}
+
+	scenario, err := s.GetScenario(scenarioID)
+	if err != nil {
+		if errors.Is(err, ErrScenarioNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidDigestSubscription, err)
+		}
+		return nil, err
+	}
+	if scenario.Input == nil {
+		return nil, fmt.Errorf("%w: the scenario '%s' can't be re-run", ErrInvalidDigestSubscription, scenarioID)
+	}
+
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	subscriptions, err := s.Cache.HGetAll(ctx, digestSubscriptionsCacheKey).Result()
+	if err != nil {
+		log.Error("error retrieving the digest subscriptions from the cache: %s", err)
+		return nil, err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	if countDigestSubscriptions(subscriptions, address.Address, now) >= MaxDigestSubscriptionsPerEmail {
+		return nil, fmt.Errorf("%w: at most %d subscriptions per email address", ErrDigestSubscriptionsLimit, MaxDigestSubscriptionsPerEmail)
+	}
+
+	subscription := &DigestSubscription{
+		Email:      address.Address,
+		ScenarioID: scenarioID,
+		CreatedAt:  now,
+	}
+	subscription.ID, err = randomString(8, hex.EncodeToString)
+	if err != nil {
+		log.Error("error generating the digest subscription ID: %s", err)
+		return nil, err
+	}
+	subscription.Token, err = randomString(16, hex.EncodeToString)
+	if err != nil {
+		log.Error("error generating the digest subscription token: %s", err)
+		return nil, err
+	}
+
+	if err := s.storeDigestSubscription(ctx, subscription); err != nil {
+		return nil, err
+	}
+
+	message, err := RenderDigestConfirmation(subscription, s.PublicURL)
+	if err != nil {
+		log.Error("error rendering the confirmation of the digest subscription %s: %s", subscription.ID, err)
+		return nil, err
+	}
+	if err := s.Notifier.Notify(message); err != nil {
+		log.Error("error sending the confirmation of the digest subscription %s: %s", subscription.ID, err)
+		if _, err := s.Cache.HDel(ctx, digestSubscriptionsCacheKey, subscription.ID).Result(); err != nil {
+			log.Error("error deleting the digest subscription %s from the cache: %s", subscription.ID, err)
+		}
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+// ConfirmDigest confirms the digest subscription if the token is the one it was registered with
+func (s *Service) ConfirmDigest(id, token string) error {
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	subscription, err := s.authorizedDigestSubscription(ctx, id, token)
+	if err != nil {
+		return err
+	}
+	if subscription.Confirmed {
+		return nil
+	}
+
+	subscription.Confirmed = true
+	return s.storeDigestSubscription(ctx, subscription)
+}
+
+// UnsubscribeDigest deletes the digest subscription if the token is the one it was registered with
+func (s *Service) UnsubscribeDigest(id, token string) error {
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	if _, err := s.authorizedDigestSubscription(ctx, id, token); err != nil {
+		return err
+	}
+
+	if _, err := s.Cache.HDel(ctx, digestSubscriptionsCacheKey, id).Result(); err != nil {
+		log.Error("error deleting the digest subscription %s from the cache: %s", id, err)
+		return err
+	}
+
+	return nil
+}
+
+// authorizedDigestSubscription returns the digest subscription if the token is the one it was registered with; the
+// subscriptions which were not confirmed in time are reported as missing
+func (s *Service) authorizedDigestSubscription(ctx context.Context, id, token string) (*DigestSubscription, error) {
+	data, err := s.Cache.HGet(ctx, digestSubscriptionsCacheKey, id).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, fmt.Errorf("%w: '%s'", ErrDigestSubscriptionNotFound, id)
+		}
+		log.Error("error retrieving the digest subscription %s from the cache: %s", id, err)
+		return nil, err
+	}
+
+	var subscription DigestSubscription
+	if err := json.Unmarshal([]byte(data), &subscription); err != nil {
+		log.Error("error unmarshalling the digest subscription %s: %s", id, err)
+		return nil, err
+	}
+
+	// an invalid token is reported as a missing subscription, so the IDs can't be probed
+	if subtle.ConstantTimeCompare([]byte(token), []byte(subscription.Token)) != 1 || subscription.expired(time.Now()) {
+		return nil, fmt.Errorf("%w: '%s'", ErrDigestSubscriptionNotFound, id)
+	}
+
+	return &subscription, nil
+}
+
+// storeDigestSubscription stores the digest subscription under its ID
+func (s *Service) storeDigestSubscription(ctx context.Context, subscription *DigestSubscription) error {
+	data, err := json.Marshal(subscription)
+	if err != nil {
+		log.Error("error marshalling the digest subscription to JSON: %s", err)
+		return err
+	}
+
+	if _, err := s.Cache.HSet(ctx, digestSubscriptionsCacheKey, subscription.ID, data).Result(); err != nil {
+		log.Error("error storing the digest subscription in the cache: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// countDigestSubscriptions returns the number of subscriptions of the email address among the stored ones, without
+// the subscriptions which were not confirmed in time
+func countDigestSubscriptions(subscriptions map[string]string, email string, now time.Time) int {
+	count := 0
+	for id, data := range subscriptions {
+		var subscription DigestSubscription
+		if err := json.Unmarshal([]byte(data), &subscription); err != nil {
+			log.Error("error unmarshalling the digest subscription %s: %s", id, err)
+			continue
+		}
+		if strings.EqualFold(subscription.Email, email) && !subscription.expired(now) {
+			count++
+		}
+	}
+	return count
+}
+
+// RenderDigestConfirmation returns the email with the link confirming the digest subscription
+func RenderDigestConfirmation(subscription *DigestSubscription, publicURL string) (notifier.Message, error) {
+	confirmation := DigestConfirmation{
+		ScenarioID: subscription.ScenarioID,
+		ConfirmURL: fmt.Sprintf("%s/api/digests/%s/confirm?token=%s", publicURL,
+			url.PathEscape(subscription.ID), url.QueryEscape(subscription.Token)),
+		ExpiresInHours: int(DigestConfirmationTTL.Hours()),
+	}
+
+	var body bytes.Buffer
+	if err := digestConfirmationTemplate.Execute(&body, confirmation); err != nil {
+		return notifier.Message{}, err
+	}
+
+	return notifier.Message{
+		To:       subscription.Email,
+		Subject:  "Confirm your weekly Istari Vision digest",
+		HTMLBody: body.String(),
+	}, nil
+}
+
+// BuildDigest compares the scenario with the current market data: the EGLD price against the target price, the APR
+// of the staking provider against its APR when the scenario was saved, and the projection re-run with the current
+// data against the saved one
+func (s *Service) BuildDigest(subscription *DigestSubscription, scenario *Scenario, egldStakingProviders []fetcher.EgldStakingProvider, economics Economics) (*Digest, error) {
+	if scenario.Input == nil {
+		return nil, fmt.Errorf("the scenario '%s' can't be re-run", scenario.ID)
+	}
+	input := *scenario.Input
+
+	digest := &Digest{
+		ScenarioID:      scenario.ID,
+		EgldPrice:       economics.Prices.EGLD,
+		StakingProvider: input.StakingProvider,
+		ScenarioURL:     fmt.Sprintf("%s/api/scenarios/%s?rerun=true", s.PublicURL, url.PathEscape(scenario.ID)),
+		UnsubscribeURL: fmt.Sprintf("%s/api/digests/%s/unsubscribe?token=%s", s.PublicURL,
+			url.PathEscape(subscription.ID), url.QueryEscape(subscription.Token)),
+	}
+
+	egldPrice, _, err := big.ParseFloat(economics.Prices.EGLD, 10, 0, big.ToNearestEven)
+	if err != nil {
+		log.Error("error converting EGLD price string to float: %s", err)
+		return nil, err
+	}
+	if input.EgldTargetPrice != nil && input.EgldTargetPrice.Sign() > 0 {
+		digest.EgldTargetPrice = input.EgldTargetPrice.Text('f', 2)
+		percentage := new(big.Float).Quo(egldPrice, input.EgldTargetPrice)
+		digest.PriceToTargetPercentage = percentage.Mul(percentage, BigFloatOneHundred).Text('f', 2)
+	}
+
+	for _, provider := range egldStakingProviders {
+		if provider.Identity == input.StakingProvider {
+			digest.CurrentAPR = big.NewFloat(provider.APR).Text('f', 2)
+			for _, savedProvider := range scenario.Snapshot.StakingProviders {
+				if savedProvider.Identity == input.StakingProvider {
+					digest.SavedAPR = big.NewFloat(savedProvider.APR).Text('f', 2)
+					digest.APRDrift = big.NewFloat(provider.APR-savedProvider.APR).Text('f', 2)
+				}
+			}
+		}
+	}
+
+	results, err := s.CalculateStrategies(&input, egldStakingProviders, economics)
+	if err != nil {
+		return nil, err
+	}
+
+	var savedResponse struct {
+		Results map[string]StrategyResultJSON `json:"results"`
+	}
+	if err := json.Unmarshal(scenario.Response, &savedResponse); err != nil {
+		log.Error("error unmarshalling the response of the scenario %s: %s", scenario.ID, err)
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(results))
+	for key := range results {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		strategy := DigestStrategy{
+			Strategy:            key,
+			CurrentBalanceInUsd: roundedAmount(results[key].TotalBalanceInUsd),
+			ROI:                 roundedAmount(results[key].ROI),
+		}
+		if saved, ok := savedResponse.Results[key]; ok {
+			strategy.SavedBalanceInUsd = roundedAmount(saved.TotalBalanceInUsd)
+		}
+		digest.Strategies = append(digest.Strategies, strategy)
+	}
+
+	return digest, nil
+}
+
+// RenderDigest returns the email of the digest
+func RenderDigest(email string, digest *Digest) (notifier.Message, error) {
+	var body bytes.Buffer
+	if err := digestTemplate.Execute(&body, digest); err != nil {
+		return notifier.Message{}, err
+	}
+
+	return notifier.Message{
+		To:       email,
+		Subject:  fmt.Sprintf("Your weekly Istari Vision digest: EGLD at %s USD", roundedAmount(digest.EgldPrice)),
+		HTMLBody: body.String(),
+	}, nil
+}
+
+// SendDigests sends the digests of all the subscriptions; a failing digest doesn't prevent sending the others
+func (s *Service) SendDigests() error {
+	if s.Notifier == nil {
+		return ErrDigestUnavailable
+	}
+
+	economics, err := s.GetEconomics()
+	if err != nil {
+		log.Error("error retrieving the economics for the digests: %s", err)
+		return err
+	}
+
+	providers, err := s.GetStakingProviders()
+	if err != nil {
+		log.Error("error retrieving the staking providers for the digests: %s", err)
+		return err
+	}
+
+	ctx, cc := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cc()
+
+	subscriptions, err := s.Cache.HGetAll(ctx, digestSubscriptionsCacheKey).Result()
+	if err != nil {
+		log.Error("error retrieving the digest subscriptions from the cache: %s", err)
+		return err
+	}
+
+	for id, data := range subscriptions {
+		var subscription DigestSubscription
+		if err := json.Unmarshal([]byte(data), &subscription); err != nil {
+			log.Error("error unmarshalling the digest subscription %s: %s", id, err)
+			continue
+		}
+
+		// the subscriptions which were not confirmed in time are dropped, and the pending ones are skipped
+		if subscription.expired(time.Now()) {
+			if _, err := s.Cache.HDel(ctx, digestSubscriptionsCacheKey, id).Result(); err != nil {
+				log.Error("error deleting the digest subscription %s from the cache: %s", id, err)
+			}
+			continue
+		}
+		if !subscription.Confirmed {
+			continue
+		}
+
+		scenario, err := s.GetScenario(subscription.ScenarioID)
+		if err != nil {
+			// the subscriptions of the expired scenarios are dropped
+			if errors.Is(err, ErrScenarioNotFound) {
+				if _, err := s.Cache.HDel(ctx, digestSubscriptionsCacheKey, id).Result(); err != nil {
+					log.Error("error deleting the digest subscription %s from the cache: %s", id, err)
+				}
+			}
+			continue
+		}
+
+		digest, err := s.BuildDigest(&subscription, scenario, providers, economics)
+		if err != nil {
+			log.Error("error building the digest of the subscription %s: %s", id, err)
+			continue
+		}
+
+		message, err := RenderDigest(subscription.Email, digest)
+		if err != nil {
+			log.Error("error rendering the digest of the subscription %s: %s", id, err)
+			continue
+		}
+
+		if err := s.Notifier.Notify(message); err != nil {
+			log.Error("error sending the digest of the subscription %s: %s", id, err)
+		}
+	}
+
+	return nil
+}
+
+// DigestCron sends the weekly digests on DigestWeekday after DigestHour; each week is marked as sent in the cache, so
+// the digests are sent once even if the service restarts or runs in several instances
+func (s *Service) DigestCron(ctx context.Context) {
+	t := time.NewTicker(time.Minute * 15)
+	for {
+		select {
+		case now := <-t.C:
+			now = now.UTC()
+			if now.Weekday() != DigestWeekday || now.Hour() < DigestHour {
+				continue
+			}
+
+			year, week := now.ISOWeek()
+			key := fmt.Sprintf("%s%d-%02d", digestSentCacheKeyPrefix, year, week)
+			cacheCtx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+			first, err := s.Cache.SetNX(cacheCtx, key, now.Format(time.RFC3339), 8*24*time.Hour).Result()
+			cc()
+			if err != nil {
+				log.Error("error marking the digests of the week %d-%02d as sent: %s", year, week, err)
+				continue
+			}
+			if !first {
+				continue
+			}
+
+			if err := s.SendDigests(); err != nil {
+				log.Error("error sending the digests: %s", err)
+			}
+		case <-ctx.Done():
+			t.Stop()
+			return
+		}
+	}
+}
+
+// roundedAmount returns the decimal string rounded to 2 decimals, or unchanged if it is not a number
+func roundedAmount(value string) string {
+	amount, _, err := big.ParseFloat(value, 10, 0, big.ToNearestEven)
+	if err != nil {
+		return value
+	}
+	return amount.Text('f', 2)
+}
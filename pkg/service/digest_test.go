@@ -0,0 +1,116 @@
+package service
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_BuildDigest(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// test setup
+	service := Service{PublicURL: "https://istari.vision"}
+	economics := Economics{
+		Prices: Prices{EGLD: "200"},
+		mexEconomics: fetcher.MexEconomics{
+			LockedRewardsAPR:   big.NewFloat(100),
+			UnlockedRewardsAPR: big.NewFloat(40),
+			Price:              big.NewFloat(0.0002),
+		},
+	}
+	providers := []fetcher.EgldStakingProvider{{APR: 10, Identity: "istari"}}
+
+	input := &StrategiesInput{
+		EgldTokensInvested:          big.NewFloat(10),
+		MexTokensInvested:           &big.Float{},
+		PercentageOfPortfolioInEgld: big.NewFloat(100),
+		PercentageOfPortfolioInMex:  &big.Float{},
+		EgldTargetPrice:             big.NewFloat(250),
+		MexTargetPrice:              big.NewFloat(0.0002),
+		EgldAPR:                     &big.Float{},
+		MexAPRLocked:                &big.Float{},
+		MexAPRUnlocked:              &big.Float{},
+		InvestmentDurationInDays:    365,
+		RedelegationIntervalInDays:  7,
+		StakingProvider:             "istari",
+		StartDate:                   time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Strategies:                  []string{"stake"},
+	}
+
+	// the scenario is read from the cache as JSON
+	data, err := json.Marshal(&Scenario{
+		ID:       "abcdefgh",
+		Input:    input,
+		Snapshot: MarketSnapshot{Prices: Prices{EGLD: "250"}, StakingProviders: []fetcher.EgldStakingProvider{{APR: 12, Identity: "istari"}}},
+		Response: json.RawMessage(`{"results": {"egld_stake": {"TotalBalanceInUsd": "2800.0000000000"}}}`),
+	})
+	require.NoError(t, err)
+	var scenario Scenario
+	require.NoError(t, json.Unmarshal(data, &scenario))
+
+	subscription := &DigestSubscription{ID: "0123", Email: "user@example.com", ScenarioID: "abcdefgh", Token: "token"}
+
+	digest, err := service.BuildDigest(subscription, &scenario, providers, economics)
+	require.NoError(t, err)
+
+	assert.Equal(t, "250.00", digest.EgldTargetPrice)
+	assert.Equal(t, "80.00", digest.PriceToTargetPercentage)
+	assert.Equal(t, "10.00", digest.CurrentAPR)
+	assert.Equal(t, "12.00", digest.SavedAPR)
+	assert.Equal(t, "-2.00", digest.APRDrift)
+	require.Len(t, digest.Strategies, 1)
+	assert.Equal(t, "2800.00", digest.Strategies[0].SavedBalanceInUsd)
+	// 11 EGLD at the target price of 250 USD
+	assert.Equal(t, "2750.00", digest.Strategies[0].CurrentBalanceInUsd)
+	assert.Equal(t, "https://istari.vision/api/digests/0123/unsubscribe?token=token", digest.UnsubscribeURL)
+
+	message, err := RenderDigest(subscription.Email, digest)
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", message.To)
+	assert.Equal(t, "Your weekly Istari Vision digest: EGLD at 200.00 USD", message.Subject)
+	assert.Contains(t, message.HTMLBody, "<td style=\"padding: 4px;\">egld_stake</td>")
+	assert.Contains(t, message.HTMLBody, "-2.00 points")
+	assert.Contains(t, message.HTMLBody, "href=\"https://istari.vision/api/digests/0123/unsubscribe?token=token\"")
+}
+
+func TestDigestConfirmation(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	now := time.Date(2031, time.January, 10, 12, 0, 0, 0, time.UTC)
+	subscription := &DigestSubscription{ID: "0123", Email: "user@example.com", ScenarioID: "abcdefgh", Token: "token", CreatedAt: now}
+
+	message, err := RenderDigestConfirmation(subscription, "https://istari.vision")
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", message.To)
+	assert.Contains(t, message.HTMLBody, "href=\"https://istari.vision/api/digests/0123/confirm?token=token\"")
+	assert.Contains(t, message.HTMLBody, "within\n    48 hours")
+
+	// the pending subscriptions expire, the confirmed ones don't
+	assert.False(t, subscription.expired(now.Add(DigestConfirmationTTL)))
+	assert.True(t, subscription.expired(now.Add(DigestConfirmationTTL+time.Second)))
+	confirmed := *subscription
+	confirmed.Confirmed = true
+	assert.False(t, confirmed.expired(now.Add(30*24*time.Hour)))
+
+	// the expired subscriptions and the ones of other addresses are not counted
+	stored := map[string]string{}
+	for id, s := range map[string]DigestSubscription{
+		"a": {Email: "user@example.com", Confirmed: true, CreatedAt: now.Add(-30 * 24 * time.Hour)},
+		"b": {Email: "User@Example.com", CreatedAt: now.Add(-time.Hour)},
+		"c": {Email: "user@example.com", CreatedAt: now.Add(-DigestConfirmationTTL - time.Hour)},
+		"d": {Email: "other@example.com", Confirmed: true, CreatedAt: now},
+	} {
+		data, err := json.Marshal(s)
+		require.NoError(t, err)
+		stored[id] = string(data)
+	}
+	assert.Equal(t, 2, countDigestSubscriptions(stored, "user@example.com", now))
+}
@@ -0,0 +1,230 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+const (
+	// historyCacheKey is the Redis hash storing the recorded days, keyed by their date
+	historyCacheKey = "history_daily"
+
+	// AnyStakingProvider is the key of the EGLD APR applying to every staking provider, used by the imported datasets
+	// which don't record the APR of each provider
+	AnyStakingProvider = "*"
+
+	// historyLookbackInDays is the number of days a missing day falls back to the last recorded day within
+	historyLookbackInDays = 7
+)
+
+// ErrInsufficientHistory is returned when the recorded history doesn't cover the backtested period
+var ErrInsufficientHistory = errors.New("insufficient history")
+
+// HistoricalDay contains the market data recorded for a day
+type HistoricalDay struct {
+	Date      string
+	EgldPrice *big.Float
+	MexPrice  *big.Float
+	// EgldAPR maps the identity of the staking providers to their APR; AnyStakingProvider applies to all of them
+	EgldAPR        map[string]*big.Float
+	MexAPRLocked   *big.Float
+	MexAPRUnlocked *big.Float
+}
+
+// ProviderAPR returns the APR of the staking provider recorded for the day
+func (d HistoricalDay) ProviderAPR(identity string) (*big.Float, bool) {
+	if apr, ok := d.EgldAPR[identity]; ok {
+		return apr, true
+	}
+	apr, ok := d.EgldAPR[AnyStakingProvider]
+	return apr, ok
+}
+
+// History is a set of recorded days
+type History struct {
+	days map[string]HistoricalDay
+}
+
+// NewHistory returns a History containing the given days
+func NewHistory(days []HistoricalDay) *History {
+	history := &History{days: make(map[string]HistoricalDay, len(days))}
+	for _, day := range days {
+		history.days[day.Date] = day
+	}
+	return history
+}
+
+// Day returns the day recorded at the given date, or the last day recorded before it within a week, as the
+// recording may miss a few days
+func (h *History) Day(date time.Time) (HistoricalDay, bool) {
+	for i := 0; i < historyLookbackInDays; i++ {
+		if day, ok := h.days[date.AddDate(0, 0, -i).Format(DateFormat)]; ok {
+			return day, true
+		}
+	}
+	return HistoricalDay{}, false
+}
+
+// Price returns the recorded price of the token at the given date
+func (h *History) Price(tokenType TokenType, date time.Time) (*big.Float, bool) {
+	day, ok := h.Day(date)
+	if !ok {
+		return nil, false
+	}
+
+	price := day.EgldPrice
+	if tokenType == TokenTypeMex {
+		price = day.MexPrice
+	}
+	return price, price != nil
+}
+
+// LoadHistoryCSV parses a dataset with the header 'date,egld_price,mex_price,egld_apr,mex_apr_locked,mex_apr_unlocked',
+// with the dates formatted as DateFormat and the APRs as percentages; the EGLD APR applies to every staking provider
+func LoadHistoryCSV(reader io.Reader) ([]HistoricalDay, error) {
+	records, err := csv.NewReader(reader).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	expectedHeader := []string{"date", "egld_price", "mex_price", "egld_apr", "mex_apr_locked", "mex_apr_unlocked"}
+	if len(records) == 0 || strings.Join(records[0], ",") != strings.Join(expectedHeader, ",") {
+		return nil, fmt.Errorf("the history dataset must start with the header '%s'", strings.Join(expectedHeader, ","))
+	}
+
+	days := make([]HistoricalDay, 0, len(records)-1)
+	for i, record := range records[1:] {
+		if _, err := time.Parse(DateFormat, record[0]); err != nil {
+			return nil, fmt.Errorf("invalid date on line %d: %w", i+2, err)
+		}
+
+		values := make([]*big.Float, len(record)-1)
+		for j, field := range record[1:] {
+			values[j], _, err = big.ParseFloat(field, 10, 0, big.ToNearestEven)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s on line %d: %w", expectedHeader[j+1], i+2, err)
+			}
+		}
+
+		days = append(days, HistoricalDay{
+			Date:           record[0],
+			EgldPrice:      values[0],
+			MexPrice:       values[1],
+			EgldAPR:        map[string]*big.Float{AnyStakingProvider: values[2]},
+			MexAPRLocked:   values[3],
+			MexAPRUnlocked: values[4],
+		})
+	}
+
+	return days, nil
+}
+
+// ImportHistory stores the days in the recorded history, replacing the days already recorded at the same dates
+func (s *Service) ImportHistory(days []HistoricalDay) error {
+	if len(days) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, 0, 2*len(days))
+	for _, day := range days {
+		data, err := json.Marshal(&day)
+		if err != nil {
+			log.Error("error marshalling the historical day %s to JSON: %s", day.Date, err)
+			return err
+		}
+		values = append(values, day.Date, data)
+	}
+
+	ctx, cc := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cc()
+
+	_, err := s.Cache.HSet(ctx, historyCacheKey, values...).Result()
+	if err != nil {
+		log.Error("error storing the history in the cache: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// RecordHistory stores the current prices and APRs as the historical day of today
+func (s *Service) RecordHistory() error {
+	economics, err := s.GetEconomics()
+	if err != nil {
+		log.Error("error retrieving the economics to be recorded: %s", err)
+		return err
+	}
+
+	providers, err := s.GetStakingProviders()
+	if err != nil {
+		log.Error("error retrieving the staking providers to be recorded: %s", err)
+		return err
+	}
+
+	egldPrice, _, err := big.ParseFloat(economics.Prices.EGLD, 10, 0, big.ToNearestEven)
+	if err != nil {
+		log.Error("error converting EGLD price string to float: %s", err)
+		return err
+	}
+
+	day := HistoricalDay{
+		Date:           startOfDay(time.Now()).Format(DateFormat),
+		EgldPrice:      egldPrice,
+		MexPrice:       economics.mexEconomics.Price,
+		EgldAPR:        make(map[string]*big.Float, len(providers)),
+		MexAPRLocked:   economics.mexEconomics.LockedRewardsAPR,
+		MexAPRUnlocked: economics.mexEconomics.UnlockedRewardsAPR,
+	}
+	for _, provider := range providers {
+		day.EgldAPR[provider.Identity] = big.NewFloat(provider.APR)
+	}
+
+	return s.ImportHistory([]HistoricalDay{day})
+}
+
+// GetHistory returns the recorded days between the two dates, both included
+func (s *Service) GetHistory(from, to time.Time) (*History, error) {
+	var dates []string
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		dates = append(dates, date.Format(DateFormat))
+	}
+
+	history := NewHistory(nil)
+	if len(dates) == 0 {
+		return history, nil
+	}
+
+	ctx, cc := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cc()
+
+	values, err := s.Cache.HMGet(ctx, historyCacheKey, dates...).Result()
+	if err != nil {
+		log.Error("error retrieving the history from the cache: %s", err)
+		return nil, err
+	}
+
+	for _, value := range values {
+		data, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var day HistoricalDay
+		if err := json.Unmarshal([]byte(data), &day); err != nil {
+			log.Error("error unmarshalling a historical day: %s", err)
+			return nil, err
+		}
+		history.days[day.Date] = day
+	}
+
+	return history, nil
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadHistoryCSV(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	t.Run("valid dataset", func(t *testing.T) {
+		days, err := LoadHistoryCSV(strings.NewReader("date,egld_price,mex_price,egld_apr,mex_apr_locked,mex_apr_unlocked\n" +
+			"2022-03-01,100.5,0.0002,10.2,120,40\n" +
+			"2022-03-02,101,0.00021,10.1,118,39\n"))
+		require.NoError(t, err)
+		require.Len(t, days, 2)
+
+		assert.Equal(t, "2022-03-01", days[0].Date)
+		assert.Equal(t, "100.50", days[0].EgldPrice.Text('f', 2))
+		apr, ok := days[1].ProviderAPR("istari")
+		require.True(t, ok)
+		assert.Equal(t, "10.10", apr.Text('f', 2))
+	})
+
+	t.Run("invalid header", func(t *testing.T) {
+		_, err := LoadHistoryCSV(strings.NewReader("date,price\n2022-03-01,100\n"))
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid value", func(t *testing.T) {
+		_, err := LoadHistoryCSV(strings.NewReader("date,egld_price,mex_price,egld_apr,mex_apr_locked,mex_apr_unlocked\n" +
+			"2022-03-01,abc,0.0002,10.2,120,40\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestHistory_Day(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	history := NewHistory([]HistoricalDay{
+		{
+			Date:      "2022-03-01",
+			EgldPrice: big.NewFloat(100),
+			MexPrice:  big.NewFloat(0.0002),
+			EgldAPR:   map[string]*big.Float{"istari": big.NewFloat(11), AnyStakingProvider: big.NewFloat(10)},
+		},
+	})
+
+	// the missing days fall back to the last recorded day within a week
+	day, ok := history.Day(time.Date(2022, time.March, 5, 0, 0, 0, 0, time.UTC))
+	require.True(t, ok)
+	assert.Equal(t, "2022-03-01", day.Date)
+
+	_, ok = history.Day(time.Date(2022, time.March, 8, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+	_, ok = history.Day(time.Date(2022, time.February, 28, 0, 0, 0, 0, time.UTC))
+	assert.False(t, ok)
+
+	apr, _ := day.ProviderAPR("istari")
+	assert.Equal(t, "11", apr.Text('f', 0))
+	apr, _ = day.ProviderAPR("other")
+	assert.Equal(t, "10", apr.Text('f', 0))
+
+	price, ok := history.Price(TokenTypeMex, time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC))
+	require.True(t, ok)
+	assert.Equal(t, "0.0002", price.Text('f', 4))
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"math/big"
+	"time"
+)
+
+// The locked MEX model follows the energy system of the exchange: locking MEX for a number of epochs (days) gives an
+// energy equal to the locked amount multiplied by the remaining epochs, which decays every day until the tokens
+// unlock. The farms boost the locked rewards of their users based on their energy; the full boost is received when
+// the energy equals the staked MEX value locked for the longest period.
+
+const (
+	// MaxLockedMexBoostMultiplier is the largest multiplier of the locked rewards APR obtained from the energy
+	MaxLockedMexBoostMultiplier = 2.5
+)
+
+// LockedMexLockPeriodsInDays are the lock periods which can be chosen for locking MEX
+var LockedMexLockPeriodsInDays = []int{360, 720, LockedMexLockPeriodInDays}
+
+// UnlockTranche represents an amount of locked MEX rewards which unlocks at the given date
+type UnlockTranche struct {
+	Date   time.Time
+	Amount *big.Float
+}
+
+// MexLockPeriod returns the number of days the locked MEX is locked for
+func (input *StrategiesInput) MexLockPeriod() int {
+	if input.MexLockPeriodInDays == 0 {
+		return LockedMexLockPeriodInDays
+	}
+	return input.MexLockPeriodInDays
+}
+
+// averageEnergy returns the average energy of the locked MEX over the given number of days, as the energy decays
+// linearly from lockedMex * lockPeriodInDays to 0 when the tokens unlock
+func averageEnergy(lockedMex *big.Float, lockPeriodInDays, durationInDays int) *big.Float {
+	energy := &big.Float{}
+	if lockedMex == nil || lockedMex.Sign() <= 0 || lockPeriodInDays <= 0 {
+		return energy
+	}
+
+	lockPeriod := big.NewFloat(float64(lockPeriodInDays))
+	if durationInDays <= 0 {
+		return energy.Mul(lockedMex, lockPeriod)
+	}
+
+	duration := big.NewFloat(float64(durationInDays))
+	averageRemainingDays := &big.Float{}
+	if durationInDays <= lockPeriodInDays {
+		// lockPeriod - duration / 2
+		averageRemainingDays.Quo(duration, big.NewFloat(2))
+		averageRemainingDays.Sub(lockPeriod, averageRemainingDays)
+	} else {
+		// the energy is 0 after the unlock, so the average is lockPeriod^2 / (2 * duration)
+		averageRemainingDays.Mul(lockPeriod, lockPeriod)
+		averageRemainingDays.Quo(averageRemainingDays, duration)
+		averageRemainingDays.Quo(averageRemainingDays, big.NewFloat(2))
+	}
+
+	return energy.Mul(lockedMex, averageRemainingDays)
+}
+
+// boostedLockedAPR returns the locked rewards APR boosted by the energy of the locked MEX held by the user, for a farm
+// position worth stakedValueInMex
+func boostedLockedAPR(baseAPR, stakedValueInMex *big.Float, input *StrategiesInput) *big.Float {
+	boosted := &big.Float{}
+	boosted.Copy(baseAPR)
+
+	if stakedValueInMex == nil || stakedValueInMex.Sign() <= 0 {
+		return boosted
+	}
+
+	energy := averageEnergy(input.LockedMexHeld, input.MexLockPeriod(), input.InvestmentDurationInDays)
+	if energy.Sign() <= 0 {
+		return boosted
+	}
+
+	maxEnergy := &big.Float{}
+	maxEnergy.Mul(stakedValueInMex, big.NewFloat(LockedMexLockPeriodInDays))
+
+	energyRatio := &big.Float{}
+	energyRatio.Quo(energy, maxEnergy)
+	if energyRatio.Cmp(big.NewFloat(1)) > 0 {
+		energyRatio.SetFloat64(1)
+	}
+
+	// multiplier = 1 + (MaxLockedMexBoostMultiplier - 1) * energyRatio
+	multiplier := big.NewFloat(MaxLockedMexBoostMultiplier - 1)
+	multiplier.Mul(multiplier, energyRatio)
+	multiplier.Add(multiplier, big.NewFloat(1))
+
+	return boosted.Mul(boosted, multiplier)
+}
+
+// lockedRewardsSchedule returns the unlock schedule of the rewards received evenly between the start and exitDay, each
+// of them being locked for lockPeriodInDays, and the amount which is unlocked at targetDay; the tranches are grouped by
+// the month they unlock in and dated with the first day of that month
+func lockedRewardsSchedule(rewards *big.Float, startDate time.Time, exitDay, lockPeriodInDays, targetDay int) ([]UnlockTranche, *big.Float) {
+	unlockedAtTarget := &big.Float{}
+	if rewards.Sign() <= 0 {
+		return nil, unlockedAtTarget
+	}
+
+	// the rewards received on the first day are locked even if the exit is immediate
+	if exitDay < 1 {
+		exitDay = 1
+	}
+
+	dailyRewards := &big.Float{}
+	dailyRewards.Quo(rewards, big.NewFloat(float64(exitDay)))
+
+	var schedule []UnlockTranche
+	for day := 1; day <= exitDay; day++ {
+		unlockDay := day + lockPeriodInDays
+		if unlockDay <= targetDay {
+			unlockedAtTarget.Add(unlockedAtTarget, dailyRewards)
+		}
+
+		// group the tranches by the month they unlock in
+		unlockDate := startDate.AddDate(0, 0, unlockDay)
+		monthStart := time.Date(unlockDate.Year(), unlockDate.Month(), 1, 0, 0, 0, 0, time.UTC)
+		if len(schedule) == 0 || !schedule[len(schedule)-1].Date.Equal(monthStart) {
+			schedule = append(schedule, UnlockTranche{Date: monthStart, Amount: &big.Float{}})
+		}
+		schedule[len(schedule)-1].Amount.Add(schedule[len(schedule)-1].Amount, dailyRewards)
+	}
+
+	return schedule, unlockedAtTarget
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_averageEnergy(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		lockedMex        *big.Float
+		lockPeriodInDays int
+		durationInDays   int
+		expectedEnergy   string
+	}{
+		{"no locked MEX", nil, 1440, 365, "0"},
+		{"no duration", big.NewFloat(100), 1440, 0, "144000"},
+		{"duration shorter than the lock", big.NewFloat(100), 1440, 360, "126000"},
+		{"duration longer than the lock", big.NewFloat(100), 360, 720, "9000"},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			energy := averageEnergy(test.lockedMex, test.lockPeriodInDays, test.durationInDays)
+			assert.Equal(t, test.expectedEnergy, energy.Text('f', 0))
+		})
+	}
+}
+
+func Test_boostedLockedAPR(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	baseAPR := big.NewFloat(40)
+
+	t.Run("no locked MEX held", func(t *testing.T) {
+		input := &StrategiesInput{InvestmentDurationInDays: 360}
+		assert.Equal(t, "40.00", boostedLockedAPR(baseAPR, big.NewFloat(1000), input).Text('f', 2))
+	})
+
+	t.Run("partial boost", func(t *testing.T) {
+		// the average energy over 360 days is 1000 * 1260, which is 87.5% of the maximum energy 1000 * 1440
+		input := &StrategiesInput{InvestmentDurationInDays: 360, LockedMexHeld: big.NewFloat(1000)}
+		assert.Equal(t, "92.50", boostedLockedAPR(baseAPR, big.NewFloat(1000), input).Text('f', 2))
+	})
+
+	t.Run("the boost is capped", func(t *testing.T) {
+		input := &StrategiesInput{InvestmentDurationInDays: 360, LockedMexHeld: big.NewFloat(1000000)}
+		assert.Equal(t, "100.00", boostedLockedAPR(baseAPR, big.NewFloat(1000), input).Text('f', 2))
+	})
+
+	t.Run("the base APR isn't modified", func(t *testing.T) {
+		input := &StrategiesInput{InvestmentDurationInDays: 360, LockedMexHeld: big.NewFloat(1000)}
+		boostedLockedAPR(baseAPR, big.NewFloat(1000), input)
+		assert.Equal(t, "40", baseAPR.Text('f', 0))
+	})
+}
+
+func Test_lockedRewardsSchedule(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	startDate := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("no rewards", func(t *testing.T) {
+		schedule, unlocked := lockedRewardsSchedule(big.NewFloat(0), startDate, 100, 360, 100)
+		assert.Empty(t, schedule)
+		assert.Equal(t, 0, unlocked.Sign())
+	})
+
+	t.Run("everything locked at the target date", func(t *testing.T) {
+		schedule, unlocked := lockedRewardsSchedule(big.NewFloat(100), startDate, 100, 360, 100)
+		assert.Equal(t, 0, unlocked.Sign())
+
+		// the rewards unlock between 2023-02-25 and 2023-06-05
+		require.Len(t, schedule, 5)
+		assert.Equal(t, "2023-02-01", schedule[0].Date.Format(DateFormat))
+		assert.Equal(t, "2023-06-01", schedule[4].Date.Format(DateFormat))
+
+		total := &big.Float{}
+		for _, tranche := range schedule {
+			total.Add(total, tranche.Amount)
+		}
+		assert.Equal(t, "100.00", total.Text('f', 2))
+	})
+
+	t.Run("partially unlocked at the target date", func(t *testing.T) {
+		// the rewards of the first 40 days unlock before the target date
+		_, unlocked := lockedRewardsSchedule(big.NewFloat(100), startDate, 100, 360, 400)
+		assert.Equal(t, "40.00", unlocked.Text('f', 2))
+	})
+}
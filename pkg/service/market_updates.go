@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+// marketUpdatesChannel is the Redis pub/sub channel the market updates are published on, so that they reach the
+// clients of every instance of the API
+const marketUpdatesChannel = "market_updates"
+
+// MarketUpdate is the market data published after each successful refresh of the cache
+type MarketUpdate struct {
+	Prices Prices `json:"prices"`
+	// MexLockedRewardsAPR and MexUnlockedRewardsAPR are the APRs of the MEX staking farm
+	MexLockedRewardsAPR   string `json:"mex_locked_rewards_apr"`
+	MexUnlockedRewardsAPR string `json:"mex_unlocked_rewards_apr"`
+	// StakingProviders maps the identities of the EGLD staking providers to their APR
+	StakingProviders map[string]float64 `json:"staking_providers"`
+	UpdatedAt        time.Time          `json:"updated_at"`
+}
+
+// newMarketUpdate returns the update of the given market data
+func newMarketUpdate(economics Economics, egldStakingProviders []fetcher.EgldStakingProvider, now time.Time) *MarketUpdate {
+	update := &MarketUpdate{
+		Prices:           economics.Prices,
+		StakingProviders: make(map[string]float64, len(egldStakingProviders)),
+		UpdatedAt:        now,
+	}
+	if economics.mexEconomics.LockedRewardsAPR != nil {
+		update.MexLockedRewardsAPR = economics.mexEconomics.LockedRewardsAPR.Text('f', FloatingPointAccuracy)
+	}
+	if economics.mexEconomics.UnlockedRewardsAPR != nil {
+		update.MexUnlockedRewardsAPR = economics.mexEconomics.UnlockedRewardsAPR.Text('f', FloatingPointAccuracy)
+	}
+	for _, provider := range egldStakingProviders {
+		update.StakingProviders[provider.Identity] = provider.APR
+	}
+	return update
+}
+
+// GetMarketUpdate returns the update of the market data currently in the cache
+func (s *Service) GetMarketUpdate() (*MarketUpdate, error) {
+	economics, err := s.GetEconomics()
+	if err != nil {
+		log.Error("error retrieving the economics of the market update: %s", err)
+		return nil, err
+	}
+
+	providers, err := s.GetStakingProviders()
+	if err != nil {
+		log.Error("error retrieving the staking providers of the market update: %s", err)
+		return nil, err
+	}
+
+	return newMarketUpdate(economics, providers, time.Now().UTC().Truncate(time.Second)), nil
+}
+
+// PublishMarketUpdate publishes the market data currently in the cache to the subscribers of every instance
+func (s *Service) PublishMarketUpdate() error {
+	update, err := s.GetMarketUpdate()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(update)
+	if err != nil {
+		log.Error("error marshalling the market update: %s", err)
+		return err
+	}
+
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	if err := s.Cache.Publish(ctx, marketUpdatesChannel, data).Err(); err != nil {
+		log.Error("error publishing the market update: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// SubscribeMarketUpdates returns the JSON encoded market updates published until the context is done, when the
+// channel is closed
+func (s *Service) SubscribeMarketUpdates(ctx context.Context) <-chan string {
+	pubsub := s.Cache.Subscribe(ctx, marketUpdatesChannel)
+	updates := make(chan string)
+
+	go func() {
+		defer close(updates)
+		defer pubsub.Close()
+
+		messages := pubsub.Channel()
+		for {
+			select {
+			case message, ok := <-messages:
+				if !ok {
+					return
+				}
+				select {
+				case updates <- message.Payload:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return updates
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMarketUpdate(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	economics := Economics{
+		Prices: Prices{EGLD: "40", MEX: "0.00005"},
+		mexEconomics: fetcher.MexEconomics{
+			LockedRewardsAPR:   big.NewFloat(80),
+			UnlockedRewardsAPR: big.NewFloat(25.5),
+		},
+	}
+	providers := []fetcher.EgldStakingProvider{
+		{Identity: "istari", APR: 8.5},
+		{Identity: "other", APR: 7},
+	}
+
+	update := newMarketUpdate(economics, providers, now)
+	assert.Equal(t, &MarketUpdate{
+		Prices:                economics.Prices,
+		MexLockedRewardsAPR:   "80.0000000000",
+		MexUnlockedRewardsAPR: "25.5000000000",
+		StakingProviders:      map[string]float64{"istari": 8.5, "other": 7},
+		UpdatedAt:             now,
+	}, update)
+
+	// the APRs of MEX are empty if the MEX economics are not available
+	update = newMarketUpdate(Economics{Prices: economics.Prices}, nil, now)
+	assert.Empty(t, update.MexLockedRewardsAPR)
+	assert.Empty(t, update.StakingProviders)
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+const (
+	// scenarioCacheKeyPrefix is the prefix of the cache keys of the saved scenarios, followed by their ID
+	scenarioCacheKeyPrefix = "scenario_"
+
+	// DefaultScenarioTTL is how long a scenario is kept if no expiry is requested
+	DefaultScenarioTTL = 30 * 24 * time.Hour
+	// MaxScenarioTTL is the longest a scenario can be kept
+	MaxScenarioTTL = 365 * 24 * time.Hour
+
+	// scenarioIDBytes is the number of random bytes of a scenario ID, encoded in 8 URL safe characters
+	scenarioIDBytes = 6
+	// scenarioIDAttempts is the number of IDs tried before giving up on a collision
+	scenarioIDAttempts = 5
+)
+
+var (
+	// ErrScenarioNotFound is returned when the scenario doesn't exist or has expired
+	ErrScenarioNotFound = errors.New("scenario not found")
+	// ErrInvalidScenarioToken is returned when deleting a scenario with a token other than the one it was saved with
+	ErrInvalidScenarioToken = errors.New("invalid scenario token")
+)
+
+// MarketSnapshot contains the market data a calculation ran against, so that it can be replayed
+type MarketSnapshot struct {
+	Prices           Prices
+	MexEconomics     fetcher.MexEconomics
+	LiquidStaking    *fetcher.LiquidStaking
+	StakingProviders []fetcher.EgldStakingProvider
+}
+
+// NewMarketSnapshot returns the snapshot of the given market data
+func NewMarketSnapshot(egldStakingProviders []fetcher.EgldStakingProvider, economics Economics) MarketSnapshot {
+	return MarketSnapshot{
+		Prices:           economics.Prices,
+		MexEconomics:     economics.mexEconomics,
+		LiquidStaking:    economics.liquidStaking,
+		StakingProviders: egldStakingProviders,
+	}
+}
+
+// Economics returns the economics the snapshot was taken from
+func (m MarketSnapshot) Economics() Economics {
+	return Economics{
+		Prices:        m.Prices,
+		mexEconomics:  m.MexEconomics,
+		liquidStaking: m.LiquidStaking,
+	}
+}
+
+// Scenario is a saved calculation: its request payload, the market data it ran against and its response
+type Scenario struct {
+	ID        string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	// Payload is the request payload of the calculation, stored as received
+	Payload json.RawMessage
+	// Input is the parsed payload, before being completed with the market data, so the scenario can be re-run
+	Input    *StrategiesInput
+	Snapshot MarketSnapshot
+	// Response is the response of the calculation, returned as is when the scenario is replayed
+	Response json.RawMessage
+	// TokenHash is the SHA-256 of the token required to delete the scenario
+	TokenHash string
+}
+
+// SaveScenario stores the scenario for the given duration, capped to MaxScenarioTTL, under a new short ID; it returns
+// the token required to delete the scenario, which is only stored hashed
+func (s *Service) SaveScenario(scenario *Scenario, ttl time.Duration) (string, error) {
+	if ttl <= 0 {
+		ttl = DefaultScenarioTTL
+	}
+	if ttl > MaxScenarioTTL {
+		ttl = MaxScenarioTTL
+	}
+
+	token, err := randomString(16, hex.EncodeToString)
+	if err != nil {
+		log.Error("error generating the scenario token: %s", err)
+		return "", err
+	}
+	tokenHash := sha256.Sum256([]byte(token))
+
+	scenario.CreatedAt = time.Now().UTC().Truncate(time.Second)
+	scenario.ExpiresAt = scenario.CreatedAt.Add(ttl)
+	scenario.TokenHash = hex.EncodeToString(tokenHash[:])
+
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	for attempt := 0; attempt < scenarioIDAttempts; attempt++ {
+		scenario.ID, err = randomString(scenarioIDBytes, base64.RawURLEncoding.EncodeToString)
+		if err != nil {
+			log.Error("error generating the scenario ID: %s", err)
+			return "", err
+		}
+
+		data, err := json.Marshal(scenario)
+		if err != nil {
+			log.Error("error marshalling the scenario to JSON: %s", err)
+			return "", err
+		}
+
+		// the ID is only used if no other scenario has it
+		ok, err := s.Cache.SetNX(ctx, scenarioCacheKeyPrefix+scenario.ID, data, ttl).Result()
+		if err != nil {
+			log.Error("error storing the scenario in the cache: %s", err)
+			return "", err
+		}
+		if ok {
+			return token, nil
+		}
+	}
+
+	return "", fmt.Errorf("no unused scenario ID found after %d attempts", scenarioIDAttempts)
+}
+
+// GetScenario returns the saved scenario with the given ID
+func (s *Service) GetScenario(id string) (*Scenario, error) {
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	data, err := s.Cache.Get(ctx, scenarioCacheKeyPrefix+id).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, fmt.Errorf("%w: '%s'", ErrScenarioNotFound, id)
+		}
+		log.Error("error retrieving the scenario %s from the cache: %s", id, err)
+		return nil, err
+	}
+
+	var scenario Scenario
+	if err := json.Unmarshal([]byte(data), &scenario); err != nil {
+		log.Error("error unmarshalling the scenario %s: %s", id, err)
+		return nil, err
+	}
+
+	return &scenario, nil
+}
+
+// DeleteScenario deletes the saved scenario if the token is the one returned when it was saved
+func (s *Service) DeleteScenario(id, token string) error {
+	scenario, err := s.GetScenario(id)
+	if err != nil {
+		return err
+	}
+
+	tokenHash := sha256.Sum256([]byte(token))
+	if subtle.ConstantTimeCompare([]byte(hex.EncodeToString(tokenHash[:])), []byte(scenario.TokenHash)) != 1 {
+		return ErrInvalidScenarioToken
+	}
+
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	if _, err := s.Cache.Del(ctx, scenarioCacheKeyPrefix+id).Result(); err != nil {
+		log.Error("error deleting the scenario %s from the cache: %s", id, err)
+		return err
+	}
+
+	return nil
+}
+
+// randomString returns the given number of random bytes, encoded with encode
+func randomString(size int, encode func([]byte) string) (string, error) {
+	data := make([]byte, size)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return encode(data), nil
+}
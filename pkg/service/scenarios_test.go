@@ -0,0 +1,44 @@
+package service
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarketSnapshot_Economics(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	economics := Economics{
+		Prices: Prices{EGLD: "250", MEX: "0.0002"},
+		mexEconomics: fetcher.MexEconomics{
+			LockedRewardsAPR:   big.NewFloat(100),
+			UnlockedRewardsAPR: big.NewFloat(40),
+			Price:              big.NewFloat(0.0002),
+			Pools:              []fetcher.LiquidityPool{{LPTokenIdentifier: "EGLDMEX-0be9e5"}},
+		},
+		liquidStaking: &fetcher.LiquidStaking{Token: "SEGLD-3ad2d0", ExchangeRate: big.NewFloat(1.04), APR: big.NewFloat(8)},
+	}
+	providers := []fetcher.EgldStakingProvider{{APR: 12, Identity: "istari"}}
+
+	// the snapshot is stored as JSON with the scenario
+	data, err := json.Marshal(NewMarketSnapshot(providers, economics))
+	require.NoError(t, err)
+
+	var snapshot MarketSnapshot
+	require.NoError(t, json.Unmarshal(data, &snapshot))
+
+	replayed := snapshot.Economics()
+	assert.Equal(t, economics.Prices, replayed.Prices)
+	assert.Equal(t, "40", replayed.MexEconomics().UnlockedRewardsAPR.String())
+	assert.Equal(t, "EGLDMEX-0be9e5", replayed.MexEconomics().Pools[0].LPTokenIdentifier)
+	liquidStaking, ok := replayed.LiquidStaking()
+	require.True(t, ok)
+	assert.Equal(t, "SEGLD-3ad2d0", liquidStaking.Token)
+	assert.Equal(t, providers, snapshot.StakingProviders)
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+// SensitivityParameter is an input varied by the sensitivity analysis
+type SensitivityParameter string
+
+const (
+	SensitivityEgldPrice            SensitivityParameter = "egld-price"
+	SensitivityMexPrice             SensitivityParameter = "mex-price"
+	SensitivityEgldAPR              SensitivityParameter = "egld-apr"
+	SensitivityDuration             SensitivityParameter = "duration"
+	SensitivityRedelegationInterval SensitivityParameter = "redelegation-interval"
+
+	// MaxSensitivitySteps is the largest number of values of an axis of the sensitivity grid
+	MaxSensitivitySteps = 25
+
+	// sensitivityWorkers is the number of grid points computed concurrently
+	sensitivityWorkers = 8
+
+	// sensitivityCacheTTL is how long a sensitivity grid is cached; it matches the refresh interval of the market data
+	sensitivityCacheTTL = 5 * time.Minute
+)
+
+// ErrInvalidSensitivityInput is returned when the axes of the sensitivity grid are invalid
+var ErrInvalidSensitivityInput = errors.New("invalid sensitivity input")
+
+// SensitivityAxis describes the values of an input varied by the sensitivity analysis: Steps values evenly spread
+// between Min and Max, both included
+type SensitivityAxis struct {
+	Parameter SensitivityParameter
+	Min       *big.Float
+	Max       *big.Float
+	Steps     int
+}
+
+// SensitivityInput describes the two inputs varied by the sensitivity analysis
+type SensitivityInput struct {
+	X SensitivityAxis
+	Y SensitivityAxis
+}
+
+// SensitivityCellJSON is the result of a strategy for one point of the sensitivity grid
+type SensitivityCellJSON struct {
+	TotalBalanceInUsd string
+	ROI               string
+}
+
+// SensitivityAxisJSON represents the values of a SensitivityAxis formatted for the API responses
+type SensitivityAxisJSON struct {
+	Parameter SensitivityParameter
+	Values    []string
+}
+
+// SensitivityGridJSON is the result of the sensitivity analysis
+type SensitivityGridJSON struct {
+	X SensitivityAxisJSON
+	Y SensitivityAxisJSON
+	// Results maps the strategies results keys to their cells, indexed by [y][x]; a cell is nil if the strategy is not
+	// applicable for the point
+	Results map[string][][]*SensitivityCellJSON
+}
+
+// values returns the values of the axis, rounded to integers for the parameters which are numbers of days
+func (a SensitivityAxis) values() ([]*big.Float, error) {
+	switch a.Parameter {
+	case SensitivityEgldPrice, SensitivityMexPrice, SensitivityEgldAPR, SensitivityDuration, SensitivityRedelegationInterval:
+	default:
+		return nil, fmt.Errorf("%w: unknown parameter '%s'", ErrInvalidSensitivityInput, a.Parameter)
+	}
+
+	if a.Steps < 1 || a.Steps > MaxSensitivitySteps {
+		return nil, fmt.Errorf("%w: the steps of '%s' must be between 1 and %d", ErrInvalidSensitivityInput, a.Parameter, MaxSensitivitySteps)
+	}
+	if a.Min == nil || a.Max == nil || a.Min.Sign() < 0 || a.Min.Cmp(a.Max) > 0 {
+		return nil, fmt.Errorf("%w: the range of '%s' is invalid", ErrInvalidSensitivityInput, a.Parameter)
+	}
+	// the numbers of days are bounded like the inputs of the calculation, and before their conversion to int64
+	if a.Parameter.InDays() && a.Max.Cmp(big.NewFloat(MaxInvestmentDurationInDays)) > 0 {
+		return nil, fmt.Errorf("%w: the values of '%s' must be at most %d days", ErrInvalidSensitivityInput, a.Parameter, MaxInvestmentDurationInDays)
+	}
+
+	step := &big.Float{}
+	if a.Steps > 1 {
+		step.Sub(a.Max, a.Min)
+		step.Quo(step, big.NewFloat(float64(a.Steps-1)))
+	}
+
+	values := make([]*big.Float, a.Steps)
+	for i := range values {
+		value := &big.Float{}
+		value.Mul(step, big.NewFloat(float64(i)))
+		value.Add(value, a.Min)
+
+		if a.Parameter.InDays() {
+			days, _ := value.Int64()
+			if days < 1 {
+				return nil, fmt.Errorf("%w: the values of '%s' must be at least 1 day", ErrInvalidSensitivityInput, a.Parameter)
+			}
+			value.SetInt64(days)
+		}
+		values[i] = value
+	}
+
+	return values, nil
+}
+
+// InDays returns true for the parameters which are numbers of days
+func (p SensitivityParameter) InDays() bool {
+	return p == SensitivityDuration || p == SensitivityRedelegationInterval
+}
+
+// apply sets the value of the parameter on the input
+func (p SensitivityParameter) apply(input *StrategiesInput, value *big.Float) {
+	switch p {
+	case SensitivityEgldPrice:
+		input.EgldTargetPrice = value
+	case SensitivityMexPrice:
+		input.MexTargetPrice = value
+	case SensitivityEgldAPR:
+		input.EgldAPR = value
+	case SensitivityDuration:
+		days, _ := value.Int64()
+		input.InvestmentDurationInDays = int(days)
+	case SensitivityRedelegationInterval:
+		days, _ := value.Int64()
+		input.RedelegationIntervalInDays = int(days)
+	}
+}
+
+// GetSensitivityGrid returns the sensitivity grid of the input, from the cache if the same grid was computed with the
+// same market data, or computes and caches it otherwise
+func (s *Service) GetSensitivityGrid(input *StrategiesInput, sensitivityInput SensitivityInput, egldStakingProviders []fetcher.EgldStakingProvider, economics Economics) (*SensitivityGridJSON, error) {
+	if s.Cache == nil {
+		return s.SensitivityGrid(input, sensitivityInput, egldStakingProviders, economics)
+	}
+
+	key, err := sensitivityCacheKey(input, sensitivityInput, egldStakingProviders, economics)
+	if err != nil {
+		log.Error("error computing the sensitivity cache key: %s", err)
+		return nil, err
+	}
+
+	ctx, cc := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cc()
+
+	cached, err := s.Cache.Get(ctx, key).Result()
+	if err == nil {
+		var grid SensitivityGridJSON
+		if err := json.Unmarshal([]byte(cached), &grid); err == nil {
+			return &grid, nil
+		}
+		log.Error("error unmarshalling the cached sensitivity grid %s: %s", key, err)
+	} else if !errors.Is(err, redis.Nil) {
+		log.Error("error retrieving the sensitivity grid %s from the cache: %s", key, err)
+	}
+
+	grid, err := s.SensitivityGrid(input, sensitivityInput, egldStakingProviders, economics)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(grid)
+	if err != nil {
+		log.Error("error marshalling the sensitivity grid to JSON: %s", err)
+		return grid, nil
+	}
+
+	// a failure to cache the grid doesn't prevent returning it
+	if _, err := s.Cache.Set(ctx, key, data, sensitivityCacheTTL).Result(); err != nil {
+		log.Error("error caching the sensitivity grid %s: %s", key, err)
+	}
+
+	return grid, nil
+}
+
+// SensitivityGrid computes TotalBalanceInUsd and ROI of the strategies for every combination of the values of the two
+// axes, using a bounded number of workers
+func (s *Service) SensitivityGrid(input *StrategiesInput, sensitivityInput SensitivityInput, egldStakingProviders []fetcher.EgldStakingProvider, economics Economics) (*SensitivityGridJSON, error) {
+	if sensitivityInput.X.Parameter == sensitivityInput.Y.Parameter {
+		return nil, fmt.Errorf("%w: the two axes vary the same parameter '%s'", ErrInvalidSensitivityInput, sensitivityInput.X.Parameter)
+	}
+
+	xValues, err := sensitivityInput.X.values()
+	if err != nil {
+		return nil, err
+	}
+	yValues, err := sensitivityInput.Y.values()
+	if err != nil {
+		return nil, err
+	}
+
+	strategies, err := s.Registry().Select(input.Strategies)
+	if err != nil {
+		log.Error("error selecting the strategies to be calculated: %s", err)
+		return nil, err
+	}
+
+	egldInitialPrice, mexInitialPrice, err := s.prepareStrategiesInput(input, egldStakingProviders, economics)
+	if err != nil {
+		return nil, err
+	}
+
+	grid := &SensitivityGridJSON{
+		X:       SensitivityAxisJSON{Parameter: sensitivityInput.X.Parameter},
+		Y:       SensitivityAxisJSON{Parameter: sensitivityInput.Y.Parameter},
+		Results: make(map[string][][]*SensitivityCellJSON),
+	}
+	for _, value := range xValues {
+		grid.X.Values = append(grid.X.Values, value.Text('f', FloatingPointAccuracy))
+	}
+	for _, value := range yValues {
+		grid.Y.Values = append(grid.Y.Values, value.Text('f', FloatingPointAccuracy))
+	}
+
+	type point struct {
+		x, y int
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mutex    sync.Mutex
+		firstErr error
+	)
+	points := make(chan point)
+
+	worker := func() {
+		defer wg.Done()
+		for p := range points {
+			pointInput := *input
+			sensitivityInput.X.Parameter.apply(&pointInput, xValues[p.x])
+			sensitivityInput.Y.Parameter.apply(&pointInput, yValues[p.y])
+			pointInput.EgldAPRCurve = ProjectEgldAPR(&pointInput)
+
+			results, err := s.runStrategies(strategies, &pointInput, economics, egldInitialPrice, mexInitialPrice)
+
+			mutex.Lock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				mutex.Unlock()
+				continue
+			}
+			for key, result := range results {
+				cells, ok := grid.Results[key]
+				if !ok {
+					cells = make([][]*SensitivityCellJSON, len(yValues))
+					for i := range cells {
+						cells[i] = make([]*SensitivityCellJSON, len(xValues))
+					}
+					grid.Results[key] = cells
+				}
+				cells[p.y][p.x] = &SensitivityCellJSON{
+					TotalBalanceInUsd: result.TotalBalanceInUsd.Text('f', FloatingPointAccuracy),
+					ROI:               result.ROI.Text('f', 6),
+				}
+			}
+			mutex.Unlock()
+		}
+	}
+
+	wg.Add(sensitivityWorkers)
+	for i := 0; i < sensitivityWorkers; i++ {
+		go worker()
+	}
+	for y := range yValues {
+		for x := range xValues {
+			points <- point{x: x, y: y}
+		}
+	}
+	close(points)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return grid, nil
+}
+
+// sensitivityCacheKey returns the cache key of a sensitivity grid, as the hash of the inputs and of all the market data
+// read by the strategies
+func sensitivityCacheKey(input *StrategiesInput, sensitivityInput SensitivityInput, egldStakingProviders []fetcher.EgldStakingProvider, economics Economics) (string, error) {
+	data, err := json.Marshal(struct {
+		Input         *StrategiesInput
+		Sensitivity   SensitivityInput
+		Providers     []fetcher.EgldStakingProvider
+		Prices        Prices
+		MexEconomics  fetcher.MexEconomics
+		LiquidStaking *fetcher.LiquidStaking
+	}{input, sensitivityInput, egldStakingProviders, economics.Prices, economics.mexEconomics, economics.liquidStaking})
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(data)
+	return "sensitivity_" + hex.EncodeToString(hash[:]), nil
+}
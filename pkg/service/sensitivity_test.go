@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_SensitivityGrid(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// test setup
+	service := Service{}
+	economics := Economics{
+		Prices: Prices{EGLD: "250"},
+		mexEconomics: fetcher.MexEconomics{
+			LockedRewardsAPR:   big.NewFloat(100),
+			UnlockedRewardsAPR: big.NewFloat(40),
+			Price:              big.NewFloat(0.0002),
+		},
+	}
+	providers := []fetcher.EgldStakingProvider{{APR: 12, Identity: "istari"}}
+
+	newInput := func() *StrategiesInput {
+		return &StrategiesInput{
+			EgldTokensInvested:          big.NewFloat(10),
+			MexTokensInvested:           &big.Float{},
+			PercentageOfPortfolioInEgld: big.NewFloat(100),
+			PercentageOfPortfolioInMex:  &big.Float{},
+			EgldTargetPrice:             big.NewFloat(300),
+			MexTargetPrice:              big.NewFloat(0.0003),
+			EgldAPR:                     &big.Float{},
+			MexAPRLocked:                &big.Float{},
+			MexAPRUnlocked:              &big.Float{},
+			InvestmentDurationInDays:    365,
+			RedelegationIntervalInDays:  7,
+			StakingProvider:             "istari",
+			StartDate:                   time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC),
+			Strategies:                  []string{"hold", "stake"},
+		}
+	}
+
+	t.Run("price and APR grid", func(t *testing.T) {
+		grid, err := service.SensitivityGrid(newInput(), SensitivityInput{
+			X: SensitivityAxis{Parameter: SensitivityEgldPrice, Min: big.NewFloat(100), Max: big.NewFloat(300), Steps: 3},
+			Y: SensitivityAxis{Parameter: SensitivityEgldAPR, Min: big.NewFloat(10), Max: big.NewFloat(20), Steps: 2},
+		}, providers, economics)
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"100.0000000000", "200.0000000000", "300.0000000000"}, grid.X.Values)
+		assert.Equal(t, []string{"10.0000000000", "20.0000000000"}, grid.Y.Values)
+		require.Len(t, grid.Results, 2)
+
+		stake := grid.Results["egld_stake"]
+		require.Len(t, stake, 2)
+		require.Len(t, stake[0], 3)
+
+		// 11 EGLD at 100 USD with 10% APR and 12 EGLD at 300 USD with 20% APR
+		assert.Equal(t, "1100.0000000000", stake[0][0].TotalBalanceInUsd)
+		assert.Equal(t, "3600.0000000000", stake[1][2].TotalBalanceInUsd)
+		assert.Equal(t, "20.000000", stake[1][2].ROI)
+
+		// holding doesn't depend on the APR
+		hold := grid.Results["egld_hold"]
+		assert.Equal(t, hold[0][1].TotalBalanceInUsd, hold[1][1].TotalBalanceInUsd)
+	})
+
+	t.Run("invalid axes", func(t *testing.T) {
+		_, err := service.SensitivityGrid(newInput(), SensitivityInput{
+			X: SensitivityAxis{Parameter: SensitivityDuration, Min: big.NewFloat(30), Max: big.NewFloat(365), Steps: 3},
+			Y: SensitivityAxis{Parameter: SensitivityDuration, Min: big.NewFloat(30), Max: big.NewFloat(365), Steps: 3},
+		}, providers, economics)
+		assert.True(t, errors.Is(err, ErrInvalidSensitivityInput), "expected ErrInvalidSensitivityInput, got %v", err)
+
+		_, err = service.SensitivityGrid(newInput(), SensitivityInput{
+			X: SensitivityAxis{Parameter: SensitivityDuration, Min: big.NewFloat(30), Max: big.NewFloat(365), Steps: MaxSensitivitySteps + 1},
+			Y: SensitivityAxis{Parameter: SensitivityEgldPrice, Min: big.NewFloat(100), Max: big.NewFloat(300), Steps: 3},
+		}, providers, economics)
+		assert.True(t, errors.Is(err, ErrInvalidSensitivityInput), "expected ErrInvalidSensitivityInput, got %v", err)
+
+		_, err = service.SensitivityGrid(newInput(), SensitivityInput{
+			X: SensitivityAxis{Parameter: "yolo", Min: big.NewFloat(1), Max: big.NewFloat(2), Steps: 2},
+			Y: SensitivityAxis{Parameter: SensitivityEgldPrice, Min: big.NewFloat(100), Max: big.NewFloat(300), Steps: 3},
+		}, providers, economics)
+		assert.True(t, errors.Is(err, ErrInvalidSensitivityInput), "expected ErrInvalidSensitivityInput, got %v", err)
+
+		_, err = service.SensitivityGrid(newInput(), SensitivityInput{
+			X: SensitivityAxis{Parameter: SensitivityRedelegationInterval, Min: big.NewFloat(1), Max: big.NewFloat(1e30), Steps: 2},
+			Y: SensitivityAxis{Parameter: SensitivityEgldPrice, Min: big.NewFloat(100), Max: big.NewFloat(300), Steps: 3},
+		}, providers, economics)
+		assert.True(t, errors.Is(err, ErrInvalidSensitivityInput), "expected ErrInvalidSensitivityInput, got %v", err)
+	})
+}
+
+func Test_sensitivityCacheKey(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	input := &StrategiesInput{EgldTokensInvested: big.NewFloat(10), InvestmentDurationInDays: 365}
+	sensitivityInput := SensitivityInput{
+		X: SensitivityAxis{Parameter: SensitivityEgldPrice, Min: big.NewFloat(100), Max: big.NewFloat(300), Steps: 3},
+		Y: SensitivityAxis{Parameter: SensitivityEgldAPR, Min: big.NewFloat(10), Max: big.NewFloat(20), Steps: 2},
+	}
+
+	key, err := sensitivityCacheKey(input, sensitivityInput, nil, Economics{Prices: Prices{EGLD: "250"}})
+	require.NoError(t, err)
+	sameKey, err := sensitivityCacheKey(input, sensitivityInput, nil, Economics{Prices: Prices{EGLD: "250"}})
+	require.NoError(t, err)
+	otherPricesKey, err := sensitivityCacheKey(input, sensitivityInput, nil, Economics{Prices: Prices{EGLD: "251"}})
+	require.NoError(t, err)
+
+	otherAPRKey, err := sensitivityCacheKey(input, sensitivityInput, nil, Economics{
+		Prices:       Prices{EGLD: "250"},
+		mexEconomics: fetcher.MexEconomics{LockedRewardsAPR: big.NewFloat(80)},
+	})
+	require.NoError(t, err)
+	otherLiquidStakingKey, err := sensitivityCacheKey(input, sensitivityInput, nil, Economics{
+		Prices:        Prices{EGLD: "250"},
+		liquidStaking: &fetcher.LiquidStaking{APR: big.NewFloat(9)},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, key, sameKey)
+	assert.NotEqual(t, key, otherPricesKey)
+	assert.NotEqual(t, key, otherAPRKey)
+	assert.NotEqual(t, key, otherLiquidStakingKey)
+}
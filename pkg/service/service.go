@@ -1,8 +1,11 @@
 package service
 
 import (
+	"sync"
+
 	"github.com/go-redis/redis/v8"
 	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/notifier"
 )
 
 // Service encapsulates the business logic and computation
@@ -13,4 +16,19 @@ type Service struct {
 	EgldPriceFetcher            fetcher.EgldPriceFetcher
 	MexEconomicsFetcher         fetcher.MexEconomicsFetcher
 	EgldStakingProvidersFetcher fetcher.EgldStakingProvidersFetcher
+	// LiquidStakingFetcher is optional; the liquid staking strategies are skipped if it is nil
+	LiquidStakingFetcher fetcher.LiquidStakingFetcher
+	// WalletFetcher is optional; the wallets can't be imported if it is nil
+	WalletFetcher fetcher.WalletFetcher
+	// Notifier is optional; the digests are not sent if it is nil
+	Notifier notifier.Notifier
+	// PublicURL is the base URL of the API, used by the links of the digests
+	PublicURL string
+
+	// Strategies is the registry of strategies to be calculated; if nil, DefaultStrategyRegistry is used
+	Strategies *StrategyRegistry
+
+	// alertDeliveries is the queue of the triggered alerts, consumed by the delivery workers
+	alertDeliveries     chan pendingAlertDelivery
+	alertDeliveriesOnce sync.Once
 }
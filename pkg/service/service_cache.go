@@ -10,12 +10,29 @@ import (
 	"github.com/silviutroscot/istari-vision/pkg/log"
 )
 
+// liquidStakingCacheTTL is how long the liquid staking state is kept without being refreshed; it survives a couple of
+// failed refreshes before the liquid staking strategies become unavailable
+const liquidStakingCacheTTL = 15 * time.Minute
+
 func (s *Service) CacheCron(ctx context.Context) {
 	t := time.NewTicker(time.Minute * 5)
 	for {
 		select {
 		case <-t.C:
-			s.updateCache()
+			if errs := s.updateCache(); len(errs) > 0 {
+				continue
+			}
+			// the live clients are notified first, so they get the new prices as soon as possible
+			if err := s.PublishMarketUpdate(); err != nil {
+				log.Error("error publishing the market update: %s", err)
+			}
+			// the history is recorded from the refreshed market data
+			if err := s.RecordHistory(); err != nil {
+				log.Error("error recording the history: %s", err)
+			}
+			if err := s.CheckAlerts(); err != nil {
+				log.Error("error checking the alerts: %s", err)
+			}
 		case <-ctx.Done():
 			t.Stop()
 			return
@@ -41,18 +58,34 @@ func (s *Service) updateCache() []error {
 		s.updateCacheEgldPrice,
 	}
 
-	var wg sync.WaitGroup
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+	)
 	wg.Add(len(cacheFuncs))
 
+	// the liquid staking state is optional, so its errors are only logged: the cached state expires and the liquid
+	// staking strategies are reported as unavailable, while the rest of the market data keeps being refreshed
+	if s.LiquidStakingFetcher != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = s.updateCacheLiquidStaking()
+		}()
+	}
+
 	for _, f := range cacheFuncs {
 		go func(cacheFunc func() error) {
 			defer wg.Done()
 			err := cacheFunc()
 			if err != nil {
+				mutex.Lock()
 				anyError = append(anyError, err)
+				mutex.Unlock()
 			}
 		}(f)
 	}
+	wg.Wait()
 
 	if len(anyError) > 0 {
 		return anyError
@@ -135,4 +168,29 @@ func (s *Service) updateCacheEgldPrice() error {
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+func (s *Service) updateCacheLiquidStaking() error {
+	liquidStaking, err := s.LiquidStakingFetcher.FetchLiquidStaking()
+	if err != nil {
+		log.Error("error fetching the liquid staking state: %s", err)
+		return err
+	}
+
+	ctx, cc := context.WithTimeout(context.Background(), time.Second*5)
+	defer cc()
+
+	data, err := json.Marshal(&liquidStaking)
+	if err != nil {
+		log.Error("error marshalling the liquid staking state to JSON: %s", err)
+		return err
+	}
+
+	_, err = s.Cache.Set(ctx, "liquid_staking", data, liquidStakingCacheTTL).Result()
+	if err != nil {
+		log.Error("error storing the liquid staking state in cache: %s", err)
+		return err
+	}
+
+	return nil
+}
@@ -4,9 +4,11 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"math/big"
 	"time"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/silviutroscot/istari-vision/pkg/fetcher"
 )
 
@@ -23,6 +25,21 @@ type EgldPrice struct {
 type Economics struct {
 	Prices       Prices
 	mexEconomics fetcher.MexEconomics
+	// liquidStaking is nil if the liquid staking state is not available
+	liquidStaking *fetcher.LiquidStaking
+}
+
+// MexEconomics returns the MEX price, farms and liquidity pools the economics were built from
+func (e Economics) MexEconomics() fetcher.MexEconomics {
+	return e.mexEconomics
+}
+
+// LiquidStaking returns the liquid staking state and true if it is available
+func (e Economics) LiquidStaking() (fetcher.LiquidStaking, bool) {
+	if e.liquidStaking == nil {
+		return fetcher.LiquidStaking{}, false
+	}
+	return *e.liquidStaking, true
 }
 
 func (s *Service) GetEconomics() (Economics, error) {
@@ -57,5 +74,21 @@ func (s *Service) GetEconomics() (Economics, error) {
 		}
 	}
 
+	// liquid staking parsing; it is optional so a missing key is not an error
+	{
+		result, err := s.Cache.Get(ctx, "liquid_staking").Result()
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return economics, err
+		}
+
+		if err == nil {
+			var liquidStaking fetcher.LiquidStaking
+			if err := json.Unmarshal([]byte(result), &liquidStaking); err != nil {
+				return economics, err
+			}
+			economics.liquidStaking = &liquidStaking
+		}
+	}
+
 	return economics, nil
 }
\ No newline at end of file
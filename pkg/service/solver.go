@@ -0,0 +1,347 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+// SolveVariable is the input which is searched by the solver
+type SolveVariable string
+
+// SolveMetric is the value of the strategy result the solver has to reach
+type SolveMetric string
+
+const (
+	SolveForEgldPrice SolveVariable = "egld-price"
+	SolveForMexPrice  SolveVariable = "mex-price"
+	SolveForDuration  SolveVariable = "duration"
+
+	// SolveMetricUsdBalance is the TotalBalanceInUsd of the strategy; without a target value, the target is the USD
+	// value of the portfolio at the start, so the solution is the break-even with holding USD
+	SolveMetricUsdBalance SolveMetric = "usd-balance"
+	// SolveMetricROI is the ROI of the strategy
+	SolveMetricROI SolveMetric = "roi"
+	// SolveMetricParity is reached when the TotalBalanceInUsd of the strategy equals the one of another strategy
+	SolveMetricParity SolveMetric = "parity"
+
+	// maxSolveIterations is the maximum number of bisection steps of the solver
+	maxSolveIterations = 200
+	// maxSolveDurationInDays is the default upper bound when solving the duration
+	maxSolveDurationInDays = MaxInvestmentDurationInDays
+)
+
+var (
+	// ErrInvalidSolveInput is returned when the solver input is incomplete or inconsistent
+	ErrInvalidSolveInput = errors.New("invalid solve input")
+	// ErrNoSolution is returned when the target is not reached, or always exceeded, between the bounds of the variable
+	ErrNoSolution = errors.New("no solution between the bounds")
+
+	// solvePriceBoundsFactor is the factor between the initial price and the default bounds when solving a price
+	solvePriceBoundsFactor = big.NewFloat(100)
+	// solvePriceTolerance is the relative precision of a solved price
+	solvePriceTolerance = big.NewFloat(1e-10)
+)
+
+// SolveInput describes what the solver searches: the Variable for which the Metric of the Strategy on the TokenType
+// reaches Target
+type SolveInput struct {
+	Strategy  string
+	TokenType TokenType
+	Variable  SolveVariable
+	Metric    SolveMetric
+	// Target is the value of the metric to be reached; it is not used for SolveMetricParity
+	Target *big.Float
+	// OtherStrategy and OtherTokenType are the strategy compared with for SolveMetricParity
+	OtherStrategy  string
+	OtherTokenType TokenType
+	// Min and Max are the optional bounds of the variable
+	Min *big.Float
+	Max *big.Float
+}
+
+// SolveResult is the value of the variable reaching the target, with the strategy result for it
+type SolveResult struct {
+	Value *big.Float
+	// MetricValue is the value of the metric at Value, which is close to the target
+	MetricValue *big.Float
+	Target      *big.Float
+	Iterations  int
+	Result      *StrategyResult
+}
+
+// SolveResultJSON represents a SolveResult formatted for the API responses
+type SolveResultJSON struct {
+	Value       string
+	MetricValue string
+	Target      string
+	Iterations  int
+	Result      StrategyResultJSON
+}
+
+// MarshallToJSON returns the SolveResultJSON of the result; durations are formatted as integers
+func (r *SolveResult) MarshallToJSON(variable SolveVariable) SolveResultJSON {
+	value := r.Value.Text('f', FloatingPointAccuracy)
+	if variable == SolveForDuration {
+		value = r.Value.Text('f', 0)
+	}
+
+	return SolveResultJSON{
+		Value:       value,
+		MetricValue: r.MetricValue.Text('f', FloatingPointAccuracy),
+		Target:      r.Target.Text('f', FloatingPointAccuracy),
+		Iterations:  r.Iterations,
+		Result:      r.Result.MarshallToJSON(),
+	}
+}
+
+// Solve searches by bisection the value of the variable for which the metric of the strategy reaches the target,
+// using the same market data as CalculateStrategies. The metric has to cross the target between the bounds of the
+// variable; the returned value is the first one past the crossing, e.g. the first day the target is reached.
+func (s *Service) Solve(input *StrategiesInput, solveInput SolveInput, egldStakingProviders []fetcher.EgldStakingProvider, economics Economics) (*SolveResult, error) {
+	strategy, ok := s.Registry().Get(solveInput.Strategy)
+	if !ok {
+		return nil, fmt.Errorf("%w '%s'", ErrUnknownStrategy, solveInput.Strategy)
+	}
+
+	if !supportsToken(strategy, solveInput.TokenType) {
+		return nil, fmt.Errorf("%w: the strategy %s doesn't support %s", ErrInvalidSolveInput, strategy.Name(), solveInput.TokenType)
+	}
+
+	var otherStrategy Strategy
+	if solveInput.Metric == SolveMetricParity {
+		otherStrategy, ok = s.Registry().Get(solveInput.OtherStrategy)
+		if !ok {
+			return nil, fmt.Errorf("%w '%s'", ErrUnknownStrategy, solveInput.OtherStrategy)
+		}
+		if !supportsToken(otherStrategy, solveInput.OtherTokenType) {
+			return nil, fmt.Errorf("%w: the strategy %s doesn't support %s", ErrInvalidSolveInput, otherStrategy.Name(), solveInput.OtherTokenType)
+		}
+	}
+
+	// the USD value of the portfolio before the swap is the target when comparing with holding USD
+	initialValueInUSD, err := s.portfolioValueInUSD(input, economics)
+	if err != nil {
+		return nil, err
+	}
+
+	egldInitialPrice, mexInitialPrice, err := s.prepareStrategiesInput(input, egldStakingProviders, economics)
+	if err != nil {
+		return nil, err
+	}
+
+	target := solveInput.Target
+	switch solveInput.Metric {
+	case SolveMetricUsdBalance:
+		if target == nil {
+			target = initialValueInUSD
+		}
+	case SolveMetricROI:
+		if target == nil {
+			return nil, fmt.Errorf("%w: the target value is required for the metric '%s'", ErrInvalidSolveInput, solveInput.Metric)
+		}
+	case SolveMetricParity:
+		target = &big.Float{}
+	default:
+		return nil, fmt.Errorf("%w: unknown metric '%s'", ErrInvalidSolveInput, solveInput.Metric)
+	}
+
+	lowerBound, upperBound, err := solveBounds(solveInput, egldInitialPrice, mexInitialPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	run := func(strategy Strategy, tokenType TokenType, value *big.Float) (*StrategyResult, error) {
+		runInput := *input
+		switch solveInput.Variable {
+		case SolveForEgldPrice:
+			runInput.EgldTargetPrice = value
+		case SolveForMexPrice:
+			runInput.MexTargetPrice = value
+		case SolveForDuration:
+			days, _ := value.Int64()
+			runInput.InvestmentDurationInDays = int(days)
+			runInput.EgldAPRCurve = ProjectEgldAPR(&runInput)
+		}
+
+		result, err := s.runStrategy(strategy, tokenType, &runInput, economics, egldInitialPrice, mexInitialPrice)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil {
+			return nil, fmt.Errorf("%w: the strategy %s is not applicable for %s", ErrInvalidSolveInput, strategy.Name(), tokenType)
+		}
+		return result, nil
+	}
+
+	// evaluate returns the difference between the metric and the target at the given value of the variable
+	evaluate := func(value *big.Float) (*big.Float, *StrategyResult, error) {
+		result, err := run(strategy, solveInput.TokenType, value)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		metric := &big.Float{}
+		switch solveInput.Metric {
+		case SolveMetricUsdBalance:
+			metric.Copy(result.TotalBalanceInUsd)
+		case SolveMetricROI:
+			metric.Copy(result.ROI)
+		case SolveMetricParity:
+			otherResult, err := run(otherStrategy, solveInput.OtherTokenType, value)
+			if err != nil {
+				return nil, nil, err
+			}
+			metric.Sub(result.TotalBalanceInUsd, otherResult.TotalBalanceInUsd)
+		}
+
+		difference := &big.Float{}
+		difference.Sub(metric, target)
+		return difference, result, nil
+	}
+
+	lowDifference, lowResult, err := evaluate(lowerBound)
+	if err != nil {
+		return nil, err
+	}
+	if lowDifference.Sign() == 0 {
+		return &SolveResult{Value: lowerBound, MetricValue: target, Target: target, Result: lowResult}, nil
+	}
+	highDifference, highResult, err := evaluate(upperBound)
+	if err != nil {
+		return nil, err
+	}
+
+	if lowDifference.Sign() == highDifference.Sign() {
+		return nil, fmt.Errorf("%w [%s, %s]", ErrNoSolution, lowerBound.String(), upperBound.String())
+	}
+
+	solution := &SolveResult{Value: upperBound, Target: target, Result: highResult}
+	solution.MetricValue = &big.Float{}
+	solution.MetricValue.Add(highDifference, target)
+
+	for ; solution.Iterations < maxSolveIterations; solution.Iterations++ {
+		if solveInput.Variable == SolveForDuration {
+			// the days are integers, so the search stops when the bounds are consecutive days
+			distance := &big.Float{}
+			distance.Sub(upperBound, lowerBound)
+			if distance.Cmp(big.NewFloat(1)) <= 0 {
+				break
+			}
+		} else {
+			tolerance := &big.Float{}
+			tolerance.Mul(upperBound, solvePriceTolerance)
+			distance := &big.Float{}
+			distance.Sub(upperBound, lowerBound)
+			if distance.Cmp(tolerance) <= 0 {
+				break
+			}
+		}
+
+		middle := &big.Float{}
+		middle.Add(lowerBound, upperBound)
+		middle.Quo(middle, big.NewFloat(2))
+		if solveInput.Variable == SolveForDuration {
+			days, _ := middle.Int64()
+			middle.SetInt64(days)
+		}
+
+		middleDifference, middleResult, err := evaluate(middle)
+		if err != nil {
+			return nil, err
+		}
+
+		if middleDifference.Sign() != 0 && middleDifference.Sign() == lowDifference.Sign() {
+			lowerBound, lowDifference = middle, middleDifference
+			continue
+		}
+
+		upperBound = middle
+		solution.Value = middle
+		solution.Result = middleResult
+		solution.MetricValue = &big.Float{}
+		solution.MetricValue.Add(middleDifference, target)
+	}
+
+	log.Info("solved %s for the %s of %s_%s in %d iterations: %s", solveInput.Variable, solveInput.Metric,
+		solveInput.TokenType, solveInput.Strategy, solution.Iterations, solution.Value.String())
+
+	return solution, nil
+}
+
+// solveBounds returns the bounds of the variable, using the defaults around the initial prices if they are not
+// provided; the duration bounds are clamped to whole days between 1 and maxSolveDurationInDays
+func solveBounds(solveInput SolveInput, egldInitialPrice, mexInitialPrice *big.Float) (*big.Float, *big.Float, error) {
+	lowerBound, upperBound := &big.Float{}, &big.Float{}
+
+	switch solveInput.Variable {
+	case SolveForEgldPrice, SolveForMexPrice:
+		initialPrice := egldInitialPrice
+		if solveInput.Variable == SolveForMexPrice {
+			initialPrice = mexInitialPrice
+		}
+		lowerBound.Quo(initialPrice, solvePriceBoundsFactor)
+		upperBound.Mul(initialPrice, solvePriceBoundsFactor)
+	case SolveForDuration:
+		lowerBound.SetInt64(1)
+		upperBound.SetInt64(maxSolveDurationInDays)
+	default:
+		return nil, nil, fmt.Errorf("%w: unknown variable '%s'", ErrInvalidSolveInput, solveInput.Variable)
+	}
+
+	if solveInput.Min != nil {
+		lowerBound.Copy(solveInput.Min)
+	}
+	if solveInput.Max != nil {
+		upperBound.Copy(solveInput.Max)
+	}
+
+	if solveInput.Variable == SolveForDuration {
+		// the bounds are clamped before their conversion to int64, which would saturate
+		for _, bound := range []*big.Float{lowerBound, upperBound} {
+			if bound.Cmp(big.NewFloat(1)) < 0 {
+				bound.SetInt64(1)
+			}
+			if bound.Cmp(big.NewFloat(maxSolveDurationInDays)) > 0 {
+				bound.SetInt64(maxSolveDurationInDays)
+			}
+			days, _ := bound.Int64()
+			bound.SetInt64(days)
+		}
+	}
+
+	if lowerBound.Sign() <= 0 || lowerBound.Cmp(upperBound) >= 0 {
+		return nil, nil, fmt.Errorf("%w: the bounds [%s, %s] are invalid", ErrInvalidSolveInput, lowerBound.String(), upperBound.String())
+	}
+
+	return lowerBound, upperBound, nil
+}
+
+// supportsToken returns true if the token type is one of the tokens supported by the strategy
+func supportsToken(strategy Strategy, tokenType TokenType) bool {
+	for _, supported := range strategy.SupportedTokens() {
+		if supported == tokenType {
+			return true
+		}
+	}
+	return false
+}
+
+// portfolioValueInUSD returns the USD value of the tokens invested, at the current prices
+func (s *Service) portfolioValueInUSD(input *StrategiesInput, economics Economics) (*big.Float, error) {
+	egldPrice, _, err := big.ParseFloat(economics.Prices.EGLD, 10, 0, big.ToNearestEven)
+	if err != nil {
+		log.Error("error converting EGLD price string to float: %s", err)
+		return nil, err
+	}
+
+	value := &big.Float{}
+	value.Mul(input.EgldTokensInvested, egldPrice)
+	mexValue := &big.Float{}
+	mexValue.Mul(input.MexTokensInvested, economics.mexEconomics.Price)
+
+	return value.Add(value, mexValue), nil
+}
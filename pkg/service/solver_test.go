@@ -0,0 +1,140 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Solve(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// test setup
+	service := Service{}
+	economics := Economics{
+		Prices: Prices{EGLD: "250"},
+		mexEconomics: fetcher.MexEconomics{
+			LockedRewardsAPR:   big.NewFloat(100),
+			UnlockedRewardsAPR: big.NewFloat(40),
+			Price:              big.NewFloat(0.0002),
+		},
+	}
+	providers := []fetcher.EgldStakingProvider{{APR: 12, Identity: "istari"}}
+
+	// the investment starts after the end of the inflation schedule, so the APR is constant
+	newInput := func() *StrategiesInput {
+		return &StrategiesInput{
+			EgldTokensInvested:          big.NewFloat(10),
+			MexTokensInvested:           &big.Float{},
+			PercentageOfPortfolioInEgld: big.NewFloat(100),
+			PercentageOfPortfolioInMex:  &big.Float{},
+			EgldTargetPrice:             big.NewFloat(300),
+			MexTargetPrice:              big.NewFloat(0.0003),
+			EgldAPR:                     &big.Float{},
+			MexAPRLocked:                &big.Float{},
+			MexAPRUnlocked:              &big.Float{},
+			InvestmentDurationInDays:    365,
+			RedelegationIntervalInDays:  7,
+			StakingProvider:             "istari",
+			StartDate:                   time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC),
+		}
+	}
+
+	t.Run("break-even price of holding EGLD with holding USD", func(t *testing.T) {
+		solution, err := service.Solve(newInput(), SolveInput{
+			Strategy:  "hold",
+			TokenType: TokenTypeEgld,
+			Variable:  SolveForEgldPrice,
+			Metric:    SolveMetricUsdBalance,
+		}, providers, economics)
+		require.NoError(t, err)
+
+		assert.Equal(t, "250.0000", solution.Value.Text('f', 4))
+		assert.Equal(t, "2500.00", solution.Target.Text('f', 2))
+	})
+
+	t.Run("price to reach a USD balance by staking", func(t *testing.T) {
+		solution, err := service.Solve(newInput(), SolveInput{
+			Strategy:  "stake",
+			TokenType: TokenTypeEgld,
+			Variable:  SolveForEgldPrice,
+			Metric:    SolveMetricUsdBalance,
+			Target:    big.NewFloat(5600),
+		}, providers, economics)
+		require.NoError(t, err)
+
+		// 11.2 EGLD are owned after one year
+		assert.Equal(t, "500.0000", solution.Value.Text('f', 4))
+		assert.Equal(t, "5600.00", solution.Result.TotalBalanceInUsd.Text('f', 2))
+	})
+
+	t.Run("duration to reach an ROI", func(t *testing.T) {
+		solution, err := service.Solve(newInput(), SolveInput{
+			Strategy:  "stake",
+			TokenType: TokenTypeEgld,
+			Variable:  SolveForDuration,
+			Metric:    SolveMetricROI,
+			Target:    big.NewFloat(6),
+		}, providers, economics)
+		require.NoError(t, err)
+
+		// 12% APR gives 6% after 182.5 days
+		assert.Equal(t, "183", solution.MarshallToJSON(SolveForDuration).Value)
+	})
+
+	t.Run("no solution between the bounds", func(t *testing.T) {
+		// staking always beats holding
+		_, err := service.Solve(newInput(), SolveInput{
+			Strategy:       "stake",
+			TokenType:      TokenTypeEgld,
+			Variable:       SolveForEgldPrice,
+			Metric:         SolveMetricParity,
+			OtherStrategy:  "hold",
+			OtherTokenType: TokenTypeEgld,
+		}, providers, economics)
+		assert.True(t, errors.Is(err, ErrNoSolution), "expected ErrNoSolution, got %v", err)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := service.Solve(newInput(), SolveInput{
+			Strategy:  "stake",
+			TokenType: TokenTypeEgld,
+			Variable:  SolveForEgldPrice,
+			Metric:    SolveMetricROI,
+		}, providers, economics)
+		assert.True(t, errors.Is(err, ErrInvalidSolveInput), "expected ErrInvalidSolveInput, got %v", err)
+
+		_, err = service.Solve(newInput(), SolveInput{
+			Strategy:  "hold",
+			TokenType: TokenTypeMex,
+			Variable:  SolveForEgldPrice,
+			Metric:    SolveMetricUsdBalance,
+		}, providers, economics)
+		assert.True(t, errors.Is(err, ErrInvalidSolveInput), "expected ErrInvalidSolveInput, got %v", err)
+	})
+}
+
+func Test_solveBounds(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// the duration bounds are clamped to the calculated range, without saturating
+	lowerBound, upperBound, err := solveBounds(SolveInput{Variable: SolveForDuration, Min: big.NewFloat(-5), Max: big.NewFloat(1e30)}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "1", lowerBound.String())
+	assert.Equal(t, "3650", upperBound.String())
+
+	lowerBound, _, err = solveBounds(SolveInput{Variable: SolveForDuration, Min: big.NewFloat(10.7)}, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "10", lowerBound.String())
+
+	// the price bounds must be positive
+	_, _, err = solveBounds(SolveInput{Variable: SolveForEgldPrice, Min: big.NewFloat(0)}, big.NewFloat(100), big.NewFloat(1))
+	assert.True(t, errors.Is(err, ErrInvalidSolveInput), "expected ErrInvalidSolveInput, got %v", err)
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/silviutroscot/istari-vision/pkg/fetcher"
 	"github.com/silviutroscot/istari-vision/pkg/log"
@@ -12,21 +13,94 @@ func (s *Service) CalculateStrategies(input *StrategiesInput, egldStakingProvide
 	// wrapper over all the strategies results
 	result := make(map[string]StrategyResultJSON)
 
+	egldInitialPrice, mexInitialPrice, err := s.prepareStrategiesInput(input, egldStakingProviders, economics)
+	if err != nil {
+		return result, err
+	}
+
+	strategies, err := s.Registry().Select(input.Strategies)
+	if err != nil {
+		log.Error("error selecting the strategies to be calculated: %s", err)
+		return result, err
+	}
+
+	results, err := s.runStrategies(strategies, input, economics, egldInitialPrice, mexInitialPrice)
+	if err != nil {
+		return result, err
+	}
+
+	for key, strategyResult := range results {
+		result[key] = strategyResult.MarshallToJSON()
+	}
+
+	return result, nil
+}
+
+// runStrategies computes the result of every strategy for each of its supported tokens which has an amount invested,
+// keyed by '<token>_<name>'; the strategies which are not applicable for the input are skipped
+func (s *Service) runStrategies(strategies []Strategy, input *StrategiesInput, economics Economics, egldInitialPrice, mexInitialPrice *big.Float) (map[string]*StrategyResult, error) {
+	results := make(map[string]*StrategyResult)
+
+	for _, strategy := range strategies {
+		for _, tokenType := range strategy.SupportedTokens() {
+			if !input.HasInvestment(tokenType) {
+				continue
+			}
+
+			strategyResult, err := s.runStrategy(strategy, tokenType, input, economics, egldInitialPrice, mexInitialPrice)
+			if err != nil {
+				log.Error("error calculating %s strategy for %s: %s", strategy.Name(), tokenType, err)
+				return nil, err
+			}
+
+			// the strategy is not applicable for the given input
+			if strategyResult == nil {
+				continue
+			}
+			results[tokenType.String()+"_"+strategy.Name()] = strategyResult
+		}
+	}
+
+	return results, nil
+}
+
+// prepareStrategiesInput completes the input with the market data needed by the strategies: the APRs, the balances
+// after the swap matching the portfolio distribution and the EGLD APR projection. It returns the initial prices of
+// EGLD and MEX.
+func (s *Service) prepareStrategiesInput(input *StrategiesInput, egldStakingProviders []fetcher.EgldStakingProvider, economics Economics) (*big.Float, *big.Float, error) {
 	egldInitialPrice, _, err := big.ParseFloat(economics.Prices.EGLD, 10, 0, big.ToNearestEven)
 	if err != nil {
 		log.Error("error converting EGLD price string to float: %s", err)
-		return result, err
+		return nil, nil, err
+	}
+
+	if input.StartDate.IsZero() {
+		input.StartDate = startOfDay(time.Now())
 	}
 
 	mexInitialPrice := economics.mexEconomics.Price
 	input.MexAPRLocked.Copy(economics.mexEconomics.LockedRewardsAPR)
 	input.MexAPRUnlocked.Copy(economics.mexEconomics.UnlockedRewardsAPR)
 
-	// if the portfolio percentage distribution is provided, simulate the swap to match the distribution
-	egldToBeInvested, mexToBeInvested :=
-		s.SwapTokensToMatchDistribution(input.EgldTokensInvested, input.MexTokensInvested, input.PercentageOfPortfolioInEgld, egldInitialPrice, mexInitialPrice)
-	input.EgldTokensInvested = egldToBeInvested
-	input.MexTokensInvested = mexToBeInvested
+	// if the portfolio percentage distribution is provided, simulate the swap to match the distribution; the swap is
+	// done in the EGLD-MEX pool if it is available, so the strategies start from the balances left after the fee and
+	// the price impact
+	if pool, ok := egldMexPool(economics); ok {
+		egldToBeInvested, mexToBeInvested, swap, err := s.SwapInPoolToMatchDistribution(input.EgldTokensInvested,
+			input.MexTokensInvested, input.PercentageOfPortfolioInEgld, egldInitialPrice, mexInitialPrice, pool)
+		if err != nil {
+			log.Error("error simulating the swap in the pool %s: %s", pool.LPTokenIdentifier, err)
+			return nil, nil, err
+		}
+		input.EgldTokensInvested = egldToBeInvested
+		input.MexTokensInvested = mexToBeInvested
+		input.Swap = swap
+	} else {
+		egldToBeInvested, mexToBeInvested :=
+			s.SwapTokensToMatchDistribution(input.EgldTokensInvested, input.MexTokensInvested, input.PercentageOfPortfolioInEgld, egldInitialPrice, mexInitialPrice)
+		input.EgldTokensInvested = egldToBeInvested
+		input.MexTokensInvested = mexToBeInvested
+	}
 
 	// find the staking provider in the list of staking providers and retrieve its APR
 	egldStakingProviderWasFound := false
@@ -41,56 +115,30 @@ func (s *Service) CalculateStrategies(input *StrategiesInput, egldStakingProvide
 	if !egldStakingProviderWasFound {
 		message := fmt.Sprintf("error finding the staking provider %s", input.StakingProvider)
 		log.Error(message)
-		return result, fmt.Errorf(message)
+		return nil, nil, fmt.Errorf(message)
 	}
 
-	// if there is egld invested, compute the results for HOLD, STAKE and STAKE + REDELEGATE
-	if egldToBeInvested.Cmp(EPSILON) == 1 {
-		// HOLD
-		egldHoldResult, err := s.HoldStrategy(TokenTypeEgld, input)
-		if err != nil {
-			log.Error("error calculating HOLD strategy for EGLD: %s", err)
-			return result, err
-		}
-		log.Info("HOLD result token balance is %s", egldHoldResult.TotalBalanceInEgld.String())
-		result["egld_hold"] = egldHoldResult.MarshallToJSON()
+	input.EgldAPRCurve = ProjectEgldAPR(input)
 
-		// Stake
-		egldStakeResult, err := s.StakeStrategy(TokenTypeEgld, input, egldInitialPrice)
-		if err != nil {
-			log.Error("error calculating STAKE strategy for EGLD: %s", err)
-			return result, err
-		}
-		log.Info("egld stake result ROI is %v", egldStakeResult)
-		result["egld_stake"] = egldStakeResult.MarshallToJSON()
+	return egldInitialPrice, mexInitialPrice, nil
+}
 
-		// Redelegate
-		egldRedelegateResult, err := s.RedelegateStrategy(TokenTypeEgld, input, egldInitialPrice)
-		if err != nil {
-			log.Error("error calculating REDELEGATE strategy for EGLD: %s", err)
-			return result, err
-		}
-		result["egld_redelegate"] = egldRedelegateResult.MarshallToJSON()
+// runStrategy computes the result of the strategy for the token type, on an input prepared by prepareStrategiesInput;
+// the result is nil if the strategy is not applicable for the input
+func (s *Service) runStrategy(strategy Strategy, tokenType TokenType, input *StrategiesInput, economics Economics, egldInitialPrice, mexInitialPrice *big.Float) (*StrategyResult, error) {
+	initialPrices := map[TokenType]*big.Float{
+		TokenTypeEgld: egldInitialPrice,
+		TokenTypeMex:  mexInitialPrice,
 	}
 
-	// if there is MEX invested, compute the results for STAKE and STAKE + REDELEGATE
-	if mexToBeInvested.Cmp(EPSILON) == 1 {
-		// Stake
-		mexStakeResult, err := s.StakeStrategy(TokenTypeMex, input, mexInitialPrice)
-		if err != nil {
-			log.Error("error calculating STAKE strategy for MEX: %s", err)
-			return result, err
-		}
-		result["mex_stake"] = mexStakeResult.MarshallToJSON()
-
-		// Redelegate
-		mexRedelegateResult, err := s.RedelegateStrategy(TokenTypeMex, input, mexInitialPrice)
-		if err != nil {
-			log.Error("error calculating REDELEGATE strategy for MEX: %s", err)
-			return result, err
-		}
-		result["mex_redelegate"] = mexRedelegateResult.MarshallToJSON()
+	ctx := StrategyRunContext{
+		Service:           s,
+		TokenType:         tokenType,
+		TokenInitialPrice: initialPrices[tokenType],
+		EgldInitialPrice:  egldInitialPrice,
+		MexInitialPrice:   mexInitialPrice,
+		Economics:         economics,
 	}
 
-	return result, nil
+	return strategy.Run(ctx, input)
 }
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"math/big"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+func init() {
+	RegisterStrategy(holdStrategy{})
+	RegisterStrategy(stakeStrategy{})
+	RegisterStrategy(redelegateStrategy{})
+	RegisterStrategy(lpFarmStrategy{})
+	RegisterStrategy(metastakeStrategy{})
+	RegisterStrategy(metastakeStrategy{redelegate: true})
+	RegisterStrategy(liquidStakeStrategy{})
+	RegisterStrategy(liquidStakeStrategy{farm: true})
+}
+
+var (
+	inputFieldTokensInvested = StrategyInputField{
+		Name:        "TokensInvested",
+		Type:        "decimal",
+		Required:    true,
+		Description: "the amount of tokens invested (EgldTokensInvested or MexTokensInvested)",
+	}
+	inputFieldTargetPrice = StrategyInputField{
+		Name:        "TargetPrice",
+		Type:        "decimal",
+		Required:    true,
+		Description: "the USD price of the token at the end of the investment (EgldTargetPrice or MexTargetPrice)",
+	}
+	inputFieldAPR = StrategyInputField{
+		Name:        "APR",
+		Type:        "decimal",
+		Required:    true,
+		Description: "the staking APR as percentage (EgldAPR, or MexAPRLocked/MexAPRUnlocked based on RewardsInLockedMEX)",
+	}
+	inputFieldInvestmentDuration = StrategyInputField{
+		Name:        "InvestmentDurationInDays",
+		Type:        "integer",
+		Required:    true,
+		Description: "the number of days the tokens are invested for",
+	}
+	inputFieldPortfolio = StrategyInputField{
+		Name:        "TokensInvested",
+		Type:        "decimal",
+		Required:    true,
+		Description: "the EgldTokensInvested and MexTokensInvested; their whole value is provided as liquidity",
+	}
+	inputFieldTargetPrices = StrategyInputField{
+		Name:        "TargetPrices",
+		Type:        "decimal",
+		Required:    true,
+		Description: "the EgldTargetPrice and MexTargetPrice, both greater than 0",
+	}
+	inputFieldRewardsInLockedMEX = StrategyInputField{
+		Name:        "RewardsInLockedMEX",
+		Type:        "boolean",
+		Required:    false,
+		Description: "true if the farm rewards are received in locked MEX",
+	}
+	inputFieldLockedMex = StrategyInputField{
+		Name:        "LockedMex",
+		Type:        "decimal",
+		Required:    false,
+		Description: "the MexLockPeriodInDays (360, 720 or 1440) and the LockedMexHeld boosting the locked rewards",
+	}
+	inputFieldContribution = StrategyInputField{
+		Name:        "Contribution",
+		Type:        "object",
+		Required:    false,
+		Description: "the periodic purchases of the token (amount in USD or tokens, weekly or monthly) made after the start",
+	}
+	inputFieldRedelegationInterval = StrategyInputField{
+		Name:        "RedelegationIntervalInDays",
+		Type:        "integer",
+		Required:    true,
+		Description: "the number of days between two redelegations of the rewards",
+	}
+)
+
+// holdStrategy exposes HoldStrategy through the Strategy interface
+type holdStrategy struct{}
+
+func (holdStrategy) Name() string { return "hold" }
+
+func (holdStrategy) SupportedTokens() []TokenType { return []TokenType{TokenTypeEgld} }
+
+func (holdStrategy) InputSchema() []StrategyInputField {
+	return []StrategyInputField{inputFieldTokensInvested, inputFieldTargetPrice, inputFieldContribution}
+}
+
+func (holdStrategy) Run(ctx StrategyRunContext, input *StrategiesInput) (*StrategyResult, error) {
+	return runWithExitTiming(input, 0, func(input *StrategiesInput) (*StrategyResult, error) {
+		return runWithContributions(ctx.TokenType, input, ctx.TokenInitialPrice, func(input *StrategiesInput, tokenInitialPrice *big.Float) (*StrategyResult, error) {
+			return ctx.Service.HoldStrategy(ctx.TokenType, input)
+		})
+	})
+}
+
+// stakeStrategy exposes StakeStrategy through the Strategy interface
+type stakeStrategy struct{}
+
+func (stakeStrategy) Name() string { return "stake" }
+
+func (stakeStrategy) SupportedTokens() []TokenType { return []TokenType{TokenTypeEgld, TokenTypeMex} }
+
+func (stakeStrategy) InputSchema() []StrategyInputField {
+	return []StrategyInputField{inputFieldTokensInvested, inputFieldTargetPrice, inputFieldAPR, inputFieldInvestmentDuration,
+		inputFieldContribution}
+}
+
+func (stakeStrategy) Run(ctx StrategyRunContext, input *StrategiesInput) (*StrategyResult, error) {
+	return runWithExitTiming(input, unbondingPeriodInDays(ctx.TokenType), func(input *StrategiesInput) (*StrategyResult, error) {
+		return runWithContributions(ctx.TokenType, input, ctx.TokenInitialPrice, func(input *StrategiesInput, tokenInitialPrice *big.Float) (*StrategyResult, error) {
+			return ctx.Service.StakeStrategy(ctx.TokenType, input, tokenInitialPrice)
+		})
+	})
+}
+
+// redelegateStrategy exposes RedelegateStrategy through the Strategy interface
+type redelegateStrategy struct{}
+
+func (redelegateStrategy) Name() string { return "redelegate" }
+
+func (redelegateStrategy) SupportedTokens() []TokenType {
+	return []TokenType{TokenTypeEgld, TokenTypeMex}
+}
+
+func (redelegateStrategy) InputSchema() []StrategyInputField {
+	return []StrategyInputField{inputFieldTokensInvested, inputFieldTargetPrice, inputFieldAPR,
+		inputFieldInvestmentDuration, inputFieldRedelegationInterval, inputFieldContribution}
+}
+
+func (redelegateStrategy) Run(ctx StrategyRunContext, input *StrategiesInput) (*StrategyResult, error) {
+	return runWithExitTiming(input, unbondingPeriodInDays(ctx.TokenType), func(input *StrategiesInput) (*StrategyResult, error) {
+		return runWithContributions(ctx.TokenType, input, ctx.TokenInitialPrice, func(input *StrategiesInput, tokenInitialPrice *big.Float) (*StrategyResult, error) {
+			return ctx.Service.RedelegateStrategy(ctx.TokenType, input, tokenInitialPrice)
+		})
+	})
+}
+
+// lpFarmStrategy exposes LPFarmStrategy through the Strategy interface, for the EGLD-MEX farm
+type lpFarmStrategy struct{}
+
+func (lpFarmStrategy) Name() string { return "farm" }
+
+func (lpFarmStrategy) SupportedTokens() []TokenType { return []TokenType{TokenTypeEgldMexLP} }
+
+func (lpFarmStrategy) InputSchema() []StrategyInputField {
+	return []StrategyInputField{inputFieldPortfolio, inputFieldTargetPrices, inputFieldRewardsInLockedMEX, inputFieldLockedMex,
+		inputFieldInvestmentDuration}
+}
+
+func (lpFarmStrategy) Run(ctx StrategyRunContext, input *StrategiesInput) (*StrategyResult, error) {
+	// the impermanent loss can't be computed without a target price for both tokens
+	if input.EgldTargetPrice.Sign() <= 0 || input.MexTargetPrice.Sign() <= 0 {
+		return nil, nil
+	}
+
+	farm, pool, ok := egldMexFarmAndPool(ctx.Economics)
+	if !ok {
+		return nil, nil
+	}
+
+	// exiting the farm doesn't have an unbonding period
+	return runWithExitTiming(input, 0, func(input *StrategiesInput) (*StrategyResult, error) {
+		return ctx.Service.LPFarmStrategy(input, farm, pool, ctx.EgldInitialPrice, ctx.MexInitialPrice)
+	})
+}
+
+// metastakeStrategy exposes MetastakeStrategy through the Strategy interface, for the EGLD-MEX farm
+type metastakeStrategy struct {
+	redelegate bool
+}
+
+func (m metastakeStrategy) Name() string {
+	if m.redelegate {
+		return "metastake_redelegate"
+	}
+	return "metastake"
+}
+
+func (metastakeStrategy) SupportedTokens() []TokenType { return []TokenType{TokenTypeEgldMexLP} }
+
+func (m metastakeStrategy) InputSchema() []StrategyInputField {
+	schema := []StrategyInputField{inputFieldPortfolio, inputFieldTargetPrices, inputFieldRewardsInLockedMEX, inputFieldLockedMex,
+		inputFieldAPR, inputFieldInvestmentDuration}
+	if m.redelegate {
+		schema = append(schema, inputFieldRedelegationInterval)
+	}
+	return schema
+}
+
+func (m metastakeStrategy) Run(ctx StrategyRunContext, input *StrategiesInput) (*StrategyResult, error) {
+	if input.EgldTargetPrice.Sign() <= 0 || input.MexTargetPrice.Sign() <= 0 {
+		return nil, nil
+	}
+
+	farm, pool, ok := egldMexFarmAndPool(ctx.Economics)
+	if !ok {
+		return nil, nil
+	}
+
+	mexEconomics := ctx.Economics.MexEconomics()
+	metastakingFarm, ok := mexEconomics.MetastakingFarmFor(pool.Address, farm.Address)
+	if !ok {
+		log.Info("no metastaking farm is available for %s, skipping the metastaking strategy", farm.FarmTokenName)
+		return nil, nil
+	}
+
+	return runWithExitTiming(input, metastakingFarm.UnbondingEpochs, func(input *StrategiesInput) (*StrategyResult, error) {
+		return ctx.Service.MetastakeStrategy(input, farm, pool, ctx.EgldInitialPrice, ctx.MexInitialPrice, m.redelegate)
+	})
+}
+
+// liquidStakeStrategy exposes LiquidStakeStrategy through the Strategy interface; if farm is true, the liquid tokens
+// are staked in the farm accepting them
+type liquidStakeStrategy struct {
+	farm bool
+}
+
+func (l liquidStakeStrategy) Name() string {
+	if l.farm {
+		return "liquid_stake_farm"
+	}
+	return "liquid_stake"
+}
+
+func (liquidStakeStrategy) SupportedTokens() []TokenType { return []TokenType{TokenTypeEgld} }
+
+func (l liquidStakeStrategy) InputSchema() []StrategyInputField {
+	schema := []StrategyInputField{inputFieldTokensInvested, inputFieldTargetPrice, inputFieldInvestmentDuration}
+	if l.farm {
+		schema = append(schema, inputFieldRewardsInLockedMEX, inputFieldLockedMex)
+	}
+	return schema
+}
+
+// Available returns false while the liquid staking state is not available
+func (l liquidStakeStrategy) Available(economics Economics) bool {
+	_, ok := economics.LiquidStaking()
+	return ok
+}
+
+func (l liquidStakeStrategy) Run(ctx StrategyRunContext, input *StrategiesInput) (*StrategyResult, error) {
+	liquidStaking, ok := ctx.Economics.LiquidStaking()
+	if !ok {
+		log.Info("the liquid staking state is not available, skipping the liquid staking strategy")
+		return nil, nil
+	}
+
+	var liquidTokenFarm *fetcher.MexFarm
+	if l.farm {
+		mexEconomics := ctx.Economics.MexEconomics()
+		for idx := range mexEconomics.Farms {
+			if mexEconomics.Farms[idx].FarmingTokenIdentifier == liquidStaking.Token {
+				liquidTokenFarm = &mexEconomics.Farms[idx]
+				break
+			}
+		}
+
+		if liquidTokenFarm == nil {
+			log.Info("no farm accepts %s, skipping the liquid staking farm strategy", liquidStaking.Token)
+			return nil, nil
+		}
+	}
+
+	// the liquid token can be swapped back to EGLD right away, so there is no unbonding period
+	return runWithExitTiming(input, 0, func(input *StrategiesInput) (*StrategyResult, error) {
+		return ctx.Service.LiquidStakeStrategy(input, liquidStaking, liquidTokenFarm, ctx.EgldInitialPrice, ctx.MexInitialPrice)
+	})
+}
+
+// unbondingPeriodInDays returns the unbonding period of the staked token; only the delegated EGLD has one, as the MEX
+// can be unstaked from the farm right away
+func unbondingPeriodInDays(tokenType TokenType) int {
+	if tokenType == TokenTypeEgld {
+		return EgldUnbondingPeriodInDays
+	}
+	return 0
+}
+
+// egldMexFarmAndPool returns the EGLD-MEX farm and its liquidity pool from the economics
+func egldMexFarmAndPool(economics Economics) (fetcher.MexFarm, fetcher.LiquidityPool, bool) {
+	mexEconomics := economics.MexEconomics()
+	farm, ok := mexEconomics.FarmByName(EgldMexLPFarmTokenName)
+	if !ok {
+		log.Info("the farm %s is not available", EgldMexLPFarmTokenName)
+		return fetcher.MexFarm{}, fetcher.LiquidityPool{}, false
+	}
+
+	pool, ok := mexEconomics.PoolByLPToken(farm.FarmingTokenIdentifier)
+	if !ok {
+		log.Info("the liquidity pool for %s is not available", farm.FarmingTokenIdentifier)
+		return fetcher.MexFarm{}, fetcher.LiquidityPool{}, false
+	}
+
+	return farm, pool, true
+}
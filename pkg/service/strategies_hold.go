@@ -13,11 +13,40 @@ const (
 	TokenTypeUndefined TokenType = iota
 	TokenTypeEgld
 	TokenTypeMex
+	// TokenTypeEgldMexLP represents a position in the EGLD-MEX liquidity pool
+	TokenTypeEgldMexLP
 
 	// FloatingPointAccuracy sets the accuracy when converting to string
 	FloatingPointAccuracy = 10
+
+	// DateFormat is the format of the dates in the strategies results
+	DateFormat = "2006-01-02"
 )
 
+// String returns the name of the token, as used in the keys of the strategies results
+func (t TokenType) String() string {
+	switch t {
+	case TokenTypeEgld:
+		return "egld"
+	case TokenTypeMex:
+		return "mex"
+	case TokenTypeEgldMexLP:
+		return "egld_mex_lp"
+	default:
+		return "undefined"
+	}
+}
+
+// ParseTokenType returns the TokenType named name, as returned by String, or TokenTypeUndefined if there is none
+func ParseTokenType(name string) TokenType {
+	for _, tokenType := range []TokenType{TokenTypeEgld, TokenTypeMex, TokenTypeEgldMexLP} {
+		if tokenType.String() == name {
+			return tokenType
+		}
+	}
+	return TokenTypeUndefined
+}
+
 // StrategyResultJSON represents a StrategyResult but with all fields formatted to have 10^-10 accuracy
 type StrategyResultJSON struct {
 	ProfitInEgld       string
@@ -27,6 +56,43 @@ type StrategyResultJSON struct {
 	TotalBalanceInMex  string
 	TotalBalanceInUsd  string
 	ROI                string
+
+	// fields which are only set by the liquidity pool strategies
+	HoldBalanceInUsd          string `json:",omitempty"`
+	ImpermanentLossInUsd      string `json:",omitempty"`
+	ImpermanentLossPercentage string `json:",omitempty"`
+
+	UnbondingPeriodInDays int    `json:",omitempty"`
+	UnbondingStartDate    string `json:",omitempty"`
+	LiquidAt              string `json:",omitempty"`
+	LostYieldInUsd        string `json:",omitempty"`
+
+	LockedMexAtTarget    string              `json:",omitempty"`
+	SellableBalanceInUsd string              `json:",omitempty"`
+	UnlockSchedule       []UnlockTrancheJSON `json:",omitempty"`
+
+	ContributionsCount       int    `json:",omitempty"`
+	TotalContributedInTokens string `json:",omitempty"`
+	TotalContributedInUsd    string `json:",omitempty"`
+	AverageCostBasis         string `json:",omitempty"`
+	NetProfitInUsd           string `json:",omitempty"`
+
+	AssumedAPRCurve []APRPeriodJSON `json:",omitempty"`
+}
+
+// APRPeriodJSON represents an APRPeriod formatted for the strategies results
+type APRPeriodJSON struct {
+	StartDate     string
+	EndDate       string
+	APR           string
+	InflationRate string `json:",omitempty"`
+	StakingRatio  string `json:",omitempty"`
+}
+
+// UnlockTrancheJSON represents an UnlockTranche formatted for the strategies results
+type UnlockTrancheJSON struct {
+	Date        string
+	AmountInMex string
 }
 
 
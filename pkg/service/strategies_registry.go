@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+)
+
+// ErrUnknownStrategy is returned when a strategy that is not registered is requested
+var ErrUnknownStrategy = errors.New("unknown strategy")
+
+// Strategy represents an investment strategy which can be registered in a StrategyRegistry and evaluated by
+// CalculateStrategies for each of the tokens it supports
+type Strategy interface {
+	// Name returns the unique name of the strategy; the results are keyed by '<token>_<name>' (e.g. egld_stake)
+	Name() string
+	// SupportedTokens returns the tokens the strategy can be applied on
+	SupportedTokens() []TokenType
+	// InputSchema describes the fields of StrategiesInput the strategy reads
+	InputSchema() []StrategyInputField
+	// Run computes the result of applying the strategy on the token from the run context; a nil result without error
+	// means that the strategy is not applicable for the input
+	Run(ctx StrategyRunContext, input *StrategiesInput) (*StrategyResult, error)
+}
+
+// OptionalStrategy is implemented by the strategies depending on market data which may not be available, such as the
+// liquid staking state
+type OptionalStrategy interface {
+	// Available returns false if the market data needed by the strategy is missing from the economics
+	Available(economics Economics) bool
+}
+
+// UnavailableStrategies returns the names of the strategies which can't be calculated with the economics, because
+// their market data is not available
+func UnavailableStrategies(strategies []Strategy, economics Economics) []string {
+	var names []string
+	for _, strategy := range strategies {
+		if optional, ok := strategy.(OptionalStrategy); ok && !optional.Available(economics) {
+			names = append(names, strategy.Name())
+		}
+	}
+	return names
+}
+
+// StrategyInputField describes one field of StrategiesInput used by a strategy
+type StrategyInputField struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required"`
+	Description string `json:"description"`
+}
+
+// StrategyRunContext encapsulates the market data a strategy is evaluated against
+type StrategyRunContext struct {
+	Service *Service
+	// TokenType is the token the strategy is applied on
+	TokenType TokenType
+	// TokenInitialPrice is the USD price of TokenType when the investment starts
+	TokenInitialPrice *big.Float
+	EgldInitialPrice  *big.Float
+	MexInitialPrice   *big.Float
+	Economics         Economics
+}
+
+// StrategyRegistry keeps the strategies that CalculateStrategies evaluates, in the order they were registered
+type StrategyRegistry struct {
+	mu         sync.RWMutex
+	strategies map[string]Strategy
+	names      []string
+}
+
+// DefaultStrategyRegistry is the registry used by a Service which doesn't provide its own; it contains the
+// strategies from this package and any strategy registered by other packages using RegisterStrategy
+var DefaultStrategyRegistry = NewStrategyRegistry()
+
+// NewStrategyRegistry returns an empty StrategyRegistry
+func NewStrategyRegistry() *StrategyRegistry {
+	return &StrategyRegistry{
+		strategies: make(map[string]Strategy),
+	}
+}
+
+// RegisterStrategy adds the strategy to the DefaultStrategyRegistry and panics if its name is already taken; it is
+// meant to be called from the init function of the package providing the strategy
+func RegisterStrategy(strategy Strategy) {
+	if err := DefaultStrategyRegistry.Register(strategy); err != nil {
+		panic(err)
+	}
+}
+
+// Register adds the strategy to the registry, returning an error if a strategy with the same name already exists
+func (r *StrategyRegistry) Register(strategy Strategy) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	name := strategy.Name()
+	if name == "" {
+		return fmt.Errorf("strategy has no name")
+	}
+
+	if _, ok := r.strategies[name]; ok {
+		return fmt.Errorf("strategy '%s' is already registered", name)
+	}
+
+	r.strategies[name] = strategy
+	r.names = append(r.names, name)
+
+	return nil
+}
+
+// Get returns the strategy registered with the given name
+func (r *StrategyRegistry) Get(name string) (Strategy, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	strategy, ok := r.strategies[name]
+	return strategy, ok
+}
+
+// Strategies returns all the registered strategies, in the order they were registered
+func (r *StrategyRegistry) Strategies() []Strategy {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	strategies := make([]Strategy, 0, len(r.names))
+	for _, name := range r.names {
+		strategies = append(strategies, r.strategies[name])
+	}
+
+	return strategies
+}
+
+// Select returns the strategies with the given names, in the order they were registered; if no name is provided,
+// all the strategies are returned
+func (r *StrategyRegistry) Select(names []string) ([]Strategy, error) {
+	if len(names) == 0 {
+		return r.Strategies(), nil
+	}
+
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		if _, ok := r.Get(name); !ok {
+			return nil, fmt.Errorf("%w '%s'", ErrUnknownStrategy, name)
+		}
+		wanted[name] = true
+	}
+
+	strategies := make([]Strategy, 0, len(wanted))
+	for _, strategy := range r.Strategies() {
+		if wanted[strategy.Name()] {
+			strategies = append(strategies, strategy)
+		}
+	}
+
+	return strategies, nil
+}
+
+// Registry returns the strategy registry used by the service
+func (s *Service) Registry() *StrategyRegistry {
+	if s.Strategies != nil {
+		return s.Strategies
+	}
+	return DefaultStrategyRegistry
+}
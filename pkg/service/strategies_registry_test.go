@@ -0,0 +1,119 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrategyRegistry(t *testing.T) {
+	t.Parallel()
+
+	t.Run("register and select", func(t *testing.T) {
+		registry := NewStrategyRegistry()
+		require.NoError(t, registry.Register(holdStrategy{}))
+		require.NoError(t, registry.Register(stakeStrategy{}))
+		require.NoError(t, registry.Register(redelegateStrategy{}))
+
+		err := registry.Register(stakeStrategy{})
+		assert.Error(t, err, "expected an error when registering the same strategy twice")
+
+		all, err := registry.Select(nil)
+		require.NoError(t, err)
+		require.Len(t, all, 3)
+		assert.Equal(t, "hold", all[0].Name())
+		assert.Equal(t, "stake", all[1].Name())
+		assert.Equal(t, "redelegate", all[2].Name())
+
+		// the registration order is kept regardless of the order of the requested names
+		subset, err := registry.Select([]string{"redelegate", "hold"})
+		require.NoError(t, err)
+		require.Len(t, subset, 2)
+		assert.Equal(t, "hold", subset[0].Name())
+		assert.Equal(t, "redelegate", subset[1].Name())
+
+		_, err = registry.Select([]string{"stake", "yolo"})
+		assert.True(t, errors.Is(err, ErrUnknownStrategy), "expected ErrUnknownStrategy, got %v", err)
+	})
+
+	t.Run("default registry contains the built-in strategies", func(t *testing.T) {
+		for _, name := range []string{"hold", "stake", "redelegate"} {
+			_, ok := DefaultStrategyRegistry.Get(name)
+			assert.True(t, ok, "expected strategy %s to be registered", name)
+		}
+	})
+}
+
+func TestService_CalculateStrategies_Subset(t *testing.T) {
+	t.Parallel()
+
+	service := Service{}
+	economics := Economics{
+		Prices: Prices{EGLD: "250"},
+		mexEconomics: fetcher.MexEconomics{
+			LockedRewardsAPR:   big.NewFloat(100),
+			UnlockedRewardsAPR: big.NewFloat(40),
+			Price:              big.NewFloat(0.0002),
+		},
+	}
+	providers := []fetcher.EgldStakingProvider{{APR: 12, Identity: "istari"}}
+
+	newInput := func(strategies []string) *StrategiesInput {
+		return &StrategiesInput{
+			EgldTokensInvested:          big.NewFloat(10),
+			MexTokensInvested:           &big.Float{},
+			PercentageOfPortfolioInEgld: big.NewFloat(50),
+			PercentageOfPortfolioInMex:  big.NewFloat(50),
+			EgldTargetPrice:             big.NewFloat(300),
+			MexTargetPrice:              big.NewFloat(0.0003),
+			EgldAPR:                     &big.Float{},
+			MexAPRLocked:                &big.Float{},
+			MexAPRUnlocked:              &big.Float{},
+			InvestmentDurationInDays:    365,
+			RedelegationIntervalInDays:  7,
+			StakingProvider:             "istari",
+			Strategies:                  strategies,
+		}
+	}
+
+	t.Run("all strategies", func(t *testing.T) {
+		results, err := service.CalculateStrategies(newInput(nil), providers, economics)
+		require.NoError(t, err)
+
+		for _, key := range []string{"egld_hold", "egld_stake", "egld_redelegate", "mex_stake", "mex_redelegate"} {
+			assert.Contains(t, results, key)
+		}
+	})
+
+	t.Run("subset of strategies", func(t *testing.T) {
+		results, err := service.CalculateStrategies(newInput([]string{"stake"}), providers, economics)
+		require.NoError(t, err)
+
+		assert.Len(t, results, 2)
+		assert.Contains(t, results, "egld_stake")
+		assert.Contains(t, results, "mex_stake")
+	})
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		_, err := service.CalculateStrategies(newInput([]string{"yolo"}), providers, economics)
+		assert.True(t, errors.Is(err, ErrUnknownStrategy), "expected ErrUnknownStrategy, got %v", err)
+	})
+}
+
+func TestUnavailableStrategies(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	strategies, err := DefaultStrategyRegistry.Select([]string{"stake", "liquid_stake"})
+	require.NoError(t, err)
+
+	// the liquid staking strategies are unavailable without the liquid staking state
+	assert.Equal(t, []string{"liquid_stake"}, UnavailableStrategies(strategies, Economics{}))
+
+	economics := Economics{liquidStaking: &fetcher.LiquidStaking{Token: "SEGLD-3ad2d0", ExchangeRate: big.NewFloat(1), APR: big.NewFloat(9)}}
+	assert.Empty(t, UnavailableStrategies(strategies, economics))
+}
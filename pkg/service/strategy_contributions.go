@@ -0,0 +1,162 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+)
+
+// ContributionFrequency is how often the periodic contributions are made
+type ContributionFrequency string
+
+const (
+	ContributionWeekly  ContributionFrequency = "weekly"
+	ContributionMonthly ContributionFrequency = "monthly"
+)
+
+// Contribution describes the periodic purchases of a token made after the initial investment (dollar-cost averaging)
+type Contribution struct {
+	// TokenType is the token bought by the contributions
+	TokenType TokenType
+	// Amount is the amount bought by each contribution, in USD if AmountInUsd is true and in tokens otherwise
+	Amount      *big.Float
+	AmountInUsd bool
+	Frequency   ContributionFrequency
+}
+
+// days returns the days of the investment when the contributions are made, after the start and before the end
+func (c *Contribution) days(input *StrategiesInput) []int {
+	var days []int
+	for i := 1; ; i++ {
+		day := 7 * i
+		if c.Frequency == ContributionMonthly {
+			day = int(input.StartDate.AddDate(0, i, 0).Sub(input.StartDate).Hours() / 24)
+		}
+		if day >= input.InvestmentDurationInDays {
+			return days
+		}
+		days = append(days, day)
+	}
+}
+
+// modeledPrice returns the price of the token at the given day, as recorded if the input is backtested, or on the
+// linear path between the initial price and the target price of the input otherwise
+func modeledPrice(input *StrategiesInput, tokenType TokenType, tokenInitialPrice *big.Float, day int) *big.Float {
+	if input.History != nil {
+		if price, ok := input.History.Price(tokenType, input.StartDate.AddDate(0, 0, day)); ok {
+			return price
+		}
+	}
+
+	targetPrice := input.EgldTargetPrice
+	if tokenType == TokenTypeMex {
+		targetPrice = input.MexTargetPrice
+	}
+
+	price := &big.Float{}
+	if input.InvestmentDurationInDays <= 0 {
+		return price.Copy(tokenInitialPrice)
+	}
+
+	// initialPrice + (targetPrice - initialPrice) * day / duration
+	price.Sub(targetPrice, tokenInitialPrice)
+	price.Mul(price, big.NewFloat(float64(day)))
+	price.Quo(price, big.NewFloat(float64(input.InvestmentDurationInDays)))
+	return price.Add(price, tokenInitialPrice)
+}
+
+// contributionRunFunc computes the result of a strategy for the input, with the tokens bought at tokenInitialPrice
+type contributionRunFunc func(input *StrategiesInput, tokenInitialPrice *big.Float) (*StrategyResult, error)
+
+// runWithContributions runs the strategy for the initial investment and for each periodic contribution of the input,
+// bought at the modeled price of its day and invested for the remaining days, and sums the results. With
+// contributions, the ROI is the net profit as percentage of the total contributed.
+func runWithContributions(tokenType TokenType, input *StrategiesInput, tokenInitialPrice *big.Float, run contributionRunFunc) (*StrategyResult, error) {
+	contribution := input.Contribution
+	if contribution == nil || contribution.TokenType != tokenType {
+		return run(input, tokenInitialPrice)
+	}
+
+	result := NewStrategyResult()
+	totalTokens := &big.Float{}
+	totalContributedInUsd := &big.Float{}
+
+	addResult := func(runResult *StrategyResult) {
+		result.ProfitInEgld.Add(result.ProfitInEgld, runResult.ProfitInEgld)
+		result.ProfitInMex.Add(result.ProfitInMex, runResult.ProfitInMex)
+		result.ProfitInUSD.Add(result.ProfitInUSD, runResult.ProfitInUSD)
+		result.TotalBalanceInEgld.Add(result.TotalBalanceInEgld, runResult.TotalBalanceInEgld)
+		result.TotalBalanceInMex.Add(result.TotalBalanceInMex, runResult.TotalBalanceInMex)
+		result.TotalBalanceInUsd.Add(result.TotalBalanceInUsd, runResult.TotalBalanceInUsd)
+	}
+
+	// the strategies can't compute the ROI of an empty investment, so the initial investment is skipped if it is 0
+	if initialTokens := input.TokensInvested(tokenType); initialTokens != nil && initialTokens.Cmp(EPSILON) == 1 {
+		initialResult, err := run(input, tokenInitialPrice)
+		if err != nil {
+			return nil, err
+		}
+		addResult(initialResult)
+
+		initialValueInUSD := &big.Float{}
+		initialValueInUSD.Mul(initialTokens, tokenInitialPrice)
+		totalTokens.Add(totalTokens, initialTokens)
+		totalContributedInUsd.Add(totalContributedInUsd, initialValueInUSD)
+	}
+
+	days := contribution.days(input)
+	for _, day := range days {
+		price := modeledPrice(input, tokenType, tokenInitialPrice, day)
+		if price.Sign() <= 0 {
+			return nil, fmt.Errorf("the modeled price of %s at day %d is not positive", tokenType, day)
+		}
+
+		tokens := &big.Float{}
+		costInUSD := &big.Float{}
+		if contribution.AmountInUsd {
+			tokens.Quo(contribution.Amount, price)
+			costInUSD.Copy(contribution.Amount)
+		} else {
+			tokens.Copy(contribution.Amount)
+			costInUSD.Mul(contribution.Amount, price)
+		}
+
+		contributionInput := *input
+		contributionInput.EgldTokensInvested = &big.Float{}
+		contributionInput.MexTokensInvested = &big.Float{}
+		if tokenType == TokenTypeMex {
+			contributionInput.MexTokensInvested = tokens
+		} else {
+			contributionInput.EgldTokensInvested = tokens
+		}
+		contributionInput.InvestmentDurationInDays = input.InvestmentDurationInDays - day
+		contributionInput.StartDate = input.StartDate.AddDate(0, 0, day)
+		contributionInput.EgldAPRCurve = input.EgldAPRCurve.from(day)
+		contributionInput.Contribution = nil
+
+		contributionResult, err := run(&contributionInput, price)
+		if err != nil {
+			return nil, err
+		}
+		addResult(contributionResult)
+
+		totalTokens.Add(totalTokens, tokens)
+		totalContributedInUsd.Add(totalContributedInUsd, costInUSD)
+	}
+
+	result.AssumedAPRCurve = egldAPRCurve(tokenType, input)
+	result.ContributionsCount = len(days)
+	result.TotalContributedInTokens = totalTokens
+	result.TotalContributedInUsd = totalContributedInUsd
+	result.AverageCostBasis = &big.Float{}
+	result.NetProfitInUsd = &big.Float{}
+	result.NetProfitInUsd.Sub(result.TotalBalanceInUsd, totalContributedInUsd)
+	if totalTokens.Sign() > 0 {
+		result.AverageCostBasis.Quo(totalContributedInUsd, totalTokens)
+	}
+	if totalContributedInUsd.Sign() > 0 {
+		result.ROI.Quo(result.NetProfitInUsd, totalContributedInUsd)
+		result.ROI.Mul(result.ROI, BigFloatOneHundred)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_runWithContributions(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// test setup
+	service := Service{}
+	startDate := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("holding with weekly USD contributions", func(t *testing.T) {
+		input := &StrategiesInput{
+			EgldTokensInvested:       big.NewFloat(10),
+			EgldTargetPrice:          big.NewFloat(200),
+			InvestmentDurationInDays: 28,
+			StartDate:                startDate,
+			Contribution: &Contribution{
+				TokenType:   TokenTypeEgld,
+				Amount:      big.NewFloat(100),
+				AmountInUsd: true,
+				Frequency:   ContributionWeekly,
+			},
+		}
+
+		result, err := runWithContributions(TokenTypeEgld, input, big.NewFloat(100), func(input *StrategiesInput, tokenInitialPrice *big.Float) (*StrategyResult, error) {
+			return service.HoldStrategy(TokenTypeEgld, input)
+		})
+		require.NoError(t, err)
+
+		// 100 USD are invested at 125, 150 and 175 USD on the days 7, 14 and 21
+		assert.Equal(t, 3, result.ContributionsCount)
+		assert.Equal(t, "12.038095", result.TotalContributedInTokens.Text('f', 6))
+		assert.Equal(t, "12.038095", result.TotalBalanceInEgld.Text('f', 6))
+		assert.Equal(t, "1300.00", result.TotalContributedInUsd.Text('f', 2))
+		assert.Equal(t, "2407.62", result.TotalBalanceInUsd.Text('f', 2))
+		assert.Equal(t, "1107.62", result.NetProfitInUsd.Text('f', 2))
+		assert.Equal(t, "107.99", result.AverageCostBasis.Text('f', 2))
+		assert.Equal(t, "85.20", result.ROI.Text('f', 2))
+	})
+
+	t.Run("staking with weekly token contributions", func(t *testing.T) {
+		input := &StrategiesInput{
+			EgldTokensInvested:       &big.Float{},
+			EgldTargetPrice:          big.NewFloat(100),
+			EgldAPR:                  big.NewFloat(36.5),
+			InvestmentDurationInDays: 28,
+			StartDate:                startDate,
+			Contribution: &Contribution{
+				TokenType: TokenTypeEgld,
+				Amount:    big.NewFloat(1),
+				Frequency: ContributionWeekly,
+			},
+		}
+
+		result, err := runWithContributions(TokenTypeEgld, input, big.NewFloat(100), func(input *StrategiesInput, tokenInitialPrice *big.Float) (*StrategyResult, error) {
+			return service.StakeStrategy(TokenTypeEgld, input, tokenInitialPrice)
+		})
+		require.NoError(t, err)
+
+		// 0.1% per day for 21, 14 and 7 days
+		assert.Equal(t, "0.042000", result.ProfitInEgld.Text('f', 6))
+		assert.Equal(t, "3.042000", result.TotalBalanceInEgld.Text('f', 6))
+		assert.Equal(t, "300.00", result.TotalContributedInUsd.Text('f', 2))
+		assert.Equal(t, "100.00", result.AverageCostBasis.Text('f', 2))
+	})
+
+	t.Run("monthly contributions", func(t *testing.T) {
+		contribution := &Contribution{TokenType: TokenTypeEgld, Amount: big.NewFloat(1), Frequency: ContributionMonthly}
+		input := &StrategiesInput{InvestmentDurationInDays: 92, StartDate: startDate}
+
+		// 2022-04-01 and 2022-05-01; the contribution of 2022-06-01 is at the end of the investment
+		assert.Equal(t, []int{31, 61}, contribution.days(input))
+	})
+
+	t.Run("contributions of another token", func(t *testing.T) {
+		input := &StrategiesInput{
+			EgldTokensInvested:       big.NewFloat(10),
+			EgldTargetPrice:          big.NewFloat(200),
+			InvestmentDurationInDays: 28,
+			StartDate:                startDate,
+			Contribution: &Contribution{
+				TokenType: TokenTypeMex,
+				Amount:    big.NewFloat(1000),
+				Frequency: ContributionWeekly,
+			},
+		}
+
+		result, err := runWithContributions(TokenTypeEgld, input, big.NewFloat(100), func(input *StrategiesInput, tokenInitialPrice *big.Float) (*StrategyResult, error) {
+			return service.HoldStrategy(TokenTypeEgld, input)
+		})
+		require.NoError(t, err)
+
+		assert.Nil(t, result.TotalContributedInUsd)
+		assert.Equal(t, "2000.00", result.TotalBalanceInUsd.Text('f', 2))
+	})
+}
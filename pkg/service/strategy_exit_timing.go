@@ -0,0 +1,83 @@
+package service
+
+import (
+	"math/big"
+	"time"
+)
+
+const (
+	// EgldUnbondingPeriodInDays is the number of epochs (days) between undelegating EGLD and being able to withdraw it
+	EgldUnbondingPeriodInDays = 10
+
+	// LockedMexLockPeriodInDays is the default (and longest) number of epochs (days) the locked MEX rewards are locked
+	// for before they can be sold
+	LockedMexLockPeriodInDays = 1440
+)
+
+// strategyRunFunc computes the result of a strategy for the given input
+type strategyRunFunc func(input *StrategiesInput) (*StrategyResult, error)
+
+// runWithExitTiming runs the strategy and sets the dates when the exit has to start and when the tokens are liquid.
+// If the input requires the tokens to be liquid at the target date, the exit starts unbondingPeriodInDays before it, so
+// the rewards are received for fewer days; the USD value of the rewards lost this way is set in the result.
+func runWithExitTiming(input *StrategiesInput, unbondingPeriodInDays int, run strategyRunFunc) (*StrategyResult, error) {
+	result, err := run(input)
+	if err != nil || result == nil {
+		return result, err
+	}
+
+	exitDay := input.InvestmentDurationInDays
+	if input.LiquidAtTargetDate && unbondingPeriodInDays > 0 {
+		exitDay -= unbondingPeriodInDays
+		if exitDay < 0 {
+			exitDay = 0
+		}
+
+		exitInput := *input
+		exitInput.InvestmentDurationInDays = exitDay
+
+		exitResult, err := run(&exitInput)
+		if err != nil || exitResult == nil {
+			return exitResult, err
+		}
+
+		lostYield := &big.Float{}
+		lostYield.Sub(result.ProfitInUSD, exitResult.ProfitInUSD)
+		exitResult.LostYieldInUsd = lostYield
+		result = exitResult
+	}
+
+	liquidDay := exitDay + unbondingPeriodInDays
+
+	// the locked MEX rewards received until the exit can only be sold after their lock period
+	if input.RewardsInLockedMEX && result.ProfitInMex.Sign() > 0 {
+		lockPeriodInDays := input.MexLockPeriod()
+		if exitDay+lockPeriodInDays > liquidDay {
+			liquidDay = exitDay + lockPeriodInDays
+		}
+
+		schedule, unlockedAtTarget := lockedRewardsSchedule(result.ProfitInMex, input.StartDate, exitDay,
+			lockPeriodInDays, input.InvestmentDurationInDays)
+
+		lockedAtTarget := &big.Float{}
+		lockedAtTarget.Sub(result.ProfitInMex, unlockedAtTarget)
+		lockedValueInUSD := &big.Float{}
+		lockedValueInUSD.Mul(lockedAtTarget, input.MexTargetPrice)
+
+		result.UnlockSchedule = schedule
+		result.LockedMexAtTarget = lockedAtTarget
+		result.SellableBalanceInUsd = &big.Float{}
+		result.SellableBalanceInUsd.Sub(result.TotalBalanceInUsd, lockedValueInUSD)
+	}
+
+	result.UnbondingPeriodInDays = unbondingPeriodInDays
+	result.UnbondingStartDate = input.StartDate.AddDate(0, 0, exitDay)
+	result.LiquidAt = input.StartDate.AddDate(0, 0, liquidDay)
+
+	return result, nil
+}
+
+// startOfDay returns the given time truncated to the start of its day, in UTC
+func startOfDay(t time.Time) time.Time {
+	return t.UTC().Truncate(24 * time.Hour)
+}
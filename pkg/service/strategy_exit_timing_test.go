@@ -0,0 +1,95 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_runWithExitTiming(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// test setup
+	service := Service{}
+	startDate := time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC)
+	egldInitialPrice := big.NewFloat(100)
+
+	newInput := func(liquidAtTargetDate bool) *StrategiesInput {
+		return &StrategiesInput{
+			EgldTokensInvested:       big.NewFloat(10),
+			EgldTargetPrice:          big.NewFloat(100),
+			EgldAPR:                  big.NewFloat(36.5),
+			MexTokensInvested:        big.NewFloat(1000),
+			MexTargetPrice:           big.NewFloat(0.1),
+			MexAPRLocked:             big.NewFloat(36.5),
+			RewardsInLockedMEX:       true,
+			InvestmentDurationInDays: 100,
+			LiquidAtTargetDate:       liquidAtTargetDate,
+			StartDate:                startDate,
+		}
+	}
+
+	stakeEgld := func(input *StrategiesInput) (*StrategyResult, error) {
+		return service.StakeStrategy(TokenTypeEgld, input, egldInitialPrice)
+	}
+
+	t.Run("unbonding starts at the target date", func(t *testing.T) {
+		result, err := runWithExitTiming(newInput(false), EgldUnbondingPeriodInDays, stakeEgld)
+		require.NoError(t, err)
+
+		// 0.1% per day for 100 days
+		assert.Equal(t, "1.00000", result.ProfitInEgld.Text('f', 5))
+		assert.Equal(t, EgldUnbondingPeriodInDays, result.UnbondingPeriodInDays)
+		assert.Equal(t, "2022-06-09", result.UnbondingStartDate.Format(DateFormat))
+		assert.Equal(t, "2022-06-19", result.LiquidAt.Format(DateFormat))
+		assert.Nil(t, result.LostYieldInUsd)
+	})
+
+	t.Run("liquid at the target date", func(t *testing.T) {
+		result, err := runWithExitTiming(newInput(true), EgldUnbondingPeriodInDays, stakeEgld)
+		require.NoError(t, err)
+
+		// the rewards are only received for 90 days, so the rewards of 10 days are lost
+		assert.Equal(t, "0.90000", result.ProfitInEgld.Text('f', 5))
+		assert.Equal(t, "2022-05-30", result.UnbondingStartDate.Format(DateFormat))
+		assert.Equal(t, "2022-06-09", result.LiquidAt.Format(DateFormat))
+		require.NotNil(t, result.LostYieldInUsd)
+		assert.Equal(t, "10.00", result.LostYieldInUsd.Text('f', 2))
+	})
+
+	t.Run("unbonding longer than the investment", func(t *testing.T) {
+		input := newInput(true)
+		input.InvestmentDurationInDays = 5
+
+		result, err := runWithExitTiming(input, EgldUnbondingPeriodInDays, stakeEgld)
+		require.NoError(t, err)
+
+		assert.True(t, BigFloatsAreEqual(*BigFloatZero, *result.ProfitInEgld),
+			"expected no rewards, got %v", result.ProfitInEgld)
+		assert.Equal(t, "2022-03-01", result.UnbondingStartDate.Format(DateFormat))
+		assert.Equal(t, "2022-03-11", result.LiquidAt.Format(DateFormat))
+	})
+
+	t.Run("locked MEX rewards", func(t *testing.T) {
+		result, err := runWithExitTiming(newInput(true), 0, func(input *StrategiesInput) (*StrategyResult, error) {
+			return service.StakeStrategy(TokenTypeMex, input, big.NewFloat(0.1))
+		})
+		require.NoError(t, err)
+
+		// there is no unbonding, but the rewards are locked after the exit
+		assert.Equal(t, 0, result.UnbondingPeriodInDays)
+		assert.Equal(t, "2022-06-09", result.UnbondingStartDate.Format(DateFormat))
+		assert.Equal(t, startDate.AddDate(0, 0, 100+LockedMexLockPeriodInDays), result.LiquidAt)
+		assert.Nil(t, result.LostYieldInUsd)
+
+		// none of the rewards is unlocked at the target date, so only the staked MEX can be sold
+		require.NotNil(t, result.LockedMexAtTarget)
+		assert.Equal(t, result.ProfitInMex.Text('f', 5), result.LockedMexAtTarget.Text('f', 5))
+		assert.Equal(t, "100.00", result.SellableBalanceInUsd.Text('f', 2))
+		assert.NotEmpty(t, result.UnlockSchedule)
+	})
+}
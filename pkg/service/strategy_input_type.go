@@ -2,8 +2,12 @@ package service
 
 import (
 	"math/big"
+	"time"
 )
 
+// MaxInvestmentDurationInDays is the longest investment the strategies can be calculated for
+const MaxInvestmentDurationInDays = 3650
+
 // StrategiesInput represents a parsed and preprocessed request from an user to calculate their estimated gains
 type StrategiesInput struct {
 	EgldTokensInvested          *big.Float
@@ -11,12 +15,66 @@ type StrategiesInput struct {
 	PercentageOfPortfolioInEgld *big.Float
 	PercentageOfPortfolioInMex  *big.Float
 	RewardsInLockedMEX          bool
-	EgldTargetPrice             *big.Float
-	MexTargetPrice              *big.Float
-	EgldAPR                     *big.Float
-	MexAPRLocked                *big.Float
-	MexAPRUnlocked              *big.Float
-	InvestmentDurationInDays    int
-	RedelegationIntervalInDays  int
-	StakingProvider             string
+	// MexLockPeriodInDays is the number of days the locked MEX is locked for; 0 means LockedMexLockPeriodInDays
+	MexLockPeriodInDays int
+	// LockedMexHeld is the amount of locked MEX the user holds, locked for MexLockPeriodInDays, which gives the
+	// energy boosting the locked rewards; it can be nil
+	LockedMexHeld   *big.Float
+	EgldTargetPrice *big.Float
+	MexTargetPrice  *big.Float
+	EgldAPR         *big.Float
+	// EgldStakingRatio is the percentage of the EGLD supply assumed to be staked at the start; nil means
+	// DefaultEgldStakingRatio
+	EgldStakingRatio *big.Float
+	// EgldStakingRatioChangePerYear is the assumed yearly change of EgldStakingRatio in percentage points; it can be nil
+	EgldStakingRatioChangePerYear *big.Float
+	// EgldAPRCurve is the projection of EgldAPR over the investment; EgldAPR is used for the whole investment if empty
+	EgldAPRCurve               APRCurve
+	MexAPRLocked               *big.Float
+	MexAPRUnlocked             *big.Float
+	InvestmentDurationInDays   int
+	RedelegationIntervalInDays int
+	StakingProvider            string
+	// LiquidAtTargetDate is true if the tokens must be liquid at the end of the investment, so the exit from the
+	// strategies with an unbonding period has to start before it
+	LiquidAtTargetDate bool
+	// StartDate is the date the investment starts; it is set to the current day if not provided
+	StartDate time.Time
+	// Contribution describes the periodic purchases made after the initial investment; it can be nil
+	Contribution *Contribution
+	// History contains the recorded prices replayed by a backtest; it is nil when the investment is projected
+	History *History `json:"-"`
+	// Swap is the swap done to match the portfolio distribution, set when the strategies are calculated; nil if the
+	// swap is not simulated against the pool or no swap is needed
+	Swap *SwapResult
+	// Strategies contains the names of the strategies to be calculated; if empty, all the registered strategies are used
+	Strategies []string
+}
+
+// TokensInvested returns the amount of tokens of the given type which are invested
+func (input *StrategiesInput) TokensInvested(tokenType TokenType) *big.Float {
+	switch tokenType {
+	case TokenTypeEgld:
+		return input.EgldTokensInvested
+	case TokenTypeMex:
+		return input.MexTokensInvested
+	default:
+		return BigFloatZero
+	}
+}
+
+// HasInvestment returns true if there are tokens invested, or contributions, which can be used for the given token
+// type; the liquidity pool positions can be built from any amount of EGLD or MEX invested at the start
+func (input *StrategiesInput) HasInvestment(tokenType TokenType) bool {
+	if tokenType == TokenTypeEgldMexLP {
+		egldInvested, mexInvested := input.TokensInvested(TokenTypeEgld), input.TokensInvested(TokenTypeMex)
+		return (egldInvested != nil && egldInvested.Cmp(EPSILON) == 1) || (mexInvested != nil && mexInvested.Cmp(EPSILON) == 1)
+	}
+
+	if input.Contribution != nil && input.Contribution.TokenType == tokenType {
+		return true
+	}
+
+	tokensInvested := input.TokensInvested(tokenType)
+	return tokensInvested != nil && tokensInvested.Cmp(EPSILON) == 1
 }
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+// LiquidStakeStrategy returns a StrategyResult representing the result of converting the EGLD to the liquid staking
+// token and redeeming it at the end of the investment. The rewards are compounded daily by the protocol through the
+// growth of the exchange rate, and the liquid token can be redeemed or swapped without waiting for an unbonding period.
+// If farm is not nil, the liquid tokens are also staked in the farm, receiving MEX rewards on top of the exchange rate
+// growth.
+func (s *Service) LiquidStakeStrategy(input *StrategiesInput, liquidStaking fetcher.LiquidStaking, farm *fetcher.MexFarm, egldInitialPrice, mexInitialPrice *big.Float) (*StrategyResult, error) {
+	if liquidStaking.ExchangeRate.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid exchange rate %s for the liquid staking token %s",
+			liquidStaking.ExchangeRate.String(), liquidStaking.Token)
+	}
+
+	liquidTokens := &big.Float{}
+	liquidTokens.Quo(input.EgldTokensInvested, liquidStaking.ExchangeRate)
+	log.Info("converting %s EGLD to %s %s", input.EgldTokensInvested.String(), liquidTokens.String(), liquidStaking.Token)
+
+	// the exchange rate grows daily with the daily APR: exchangeRate * (1 + APR / 100 / 365) ^ days
+	dailyGrowth := &big.Float{}
+	dailyGrowth.Quo(liquidStaking.APR, BigFloatOneHundred)
+	dailyGrowth.Quo(dailyGrowth, BigFloatDaysInYear)
+	dailyGrowth.Add(dailyGrowth, big.NewFloat(1))
+
+	finalExchangeRate := &big.Float{}
+	finalExchangeRate.Mul(liquidStaking.ExchangeRate, BigFloatPow(dailyGrowth, input.InvestmentDurationInDays))
+
+	egldBalance := &big.Float{}
+	egldBalance.Mul(liquidTokens, finalExchangeRate)
+
+	earnedEgld := &big.Float{}
+	earnedEgld.Sub(egldBalance, input.EgldTokensInvested)
+
+	initialValueInUSD := &big.Float{}
+	initialValueInUSD.Mul(input.EgldTokensInvested, egldInitialPrice)
+
+	result := NewStrategyResult()
+	result.ProfitInEgld = earnedEgld
+	result.ProfitInUSD.Mul(earnedEgld, egldInitialPrice)
+	result.TotalBalanceInEgld = egldBalance
+	result.TotalBalanceInUsd.Mul(egldBalance, input.EgldTargetPrice)
+
+	if farm != nil {
+		farmAPR := farm.UnlockedRewardsAPR
+		if input.RewardsInLockedMEX {
+			stakedValueInMex := &big.Float{}
+			stakedValueInMex.Quo(initialValueInUSD, mexInitialPrice)
+			farmAPR = boostedLockedAPR(farm.LockedRewardsAPR, stakedValueInMex, input)
+		}
+
+		percentageOfTheYearReceivingAPR := &big.Float{}
+		percentageOfTheYearReceivingAPR.Quo(big.NewFloat(float64(input.InvestmentDurationInDays)), BigFloatDaysInYear)
+
+		farmRewardsInUSD := &big.Float{}
+		farmRewardsInUSD.Mul(initialValueInUSD, farmAPR)
+		farmRewardsInUSD.Mul(farmRewardsInUSD, percentageOfTheYearReceivingAPR)
+		farmRewardsInUSD.Quo(farmRewardsInUSD, BigFloatOneHundred)
+
+		farmRewardsInMex := &big.Float{}
+		farmRewardsInMex.Quo(farmRewardsInUSD, mexInitialPrice)
+
+		farmRewardsValueInUSD := &big.Float{}
+		farmRewardsValueInUSD.Mul(farmRewardsInMex, input.MexTargetPrice)
+
+		result.ProfitInMex = farmRewardsInMex
+		result.TotalBalanceInMex.Copy(farmRewardsInMex)
+		result.ProfitInUSD.Add(result.ProfitInUSD, farmRewardsInUSD)
+		result.TotalBalanceInUsd.Add(result.TotalBalanceInUsd, farmRewardsValueInUSD)
+	}
+
+	if initialValueInUSD.Sign() > 0 {
+		result.ROI.Quo(result.ProfitInUSD, initialValueInUSD)
+		result.ROI.Mul(result.ROI, BigFloatOneHundred)
+	}
+
+	return result, nil
+}
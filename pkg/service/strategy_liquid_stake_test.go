@@ -0,0 +1,89 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_LiquidStakeStrategy(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// test setup
+	service := Service{}
+	liquidStaking := fetcher.LiquidStaking{
+		Token:        "SEGLD-3ad2d0",
+		ExchangeRate: big.NewFloat(1.25),
+		APR:          big.NewFloat(10),
+	}
+	egldInitialPrice := big.NewFloat(200)
+	mexInitialPrice := big.NewFloat(0.0002)
+
+	t.Run("exchange rate compounds daily", func(t *testing.T) {
+		input := StrategiesInput{
+			EgldTokensInvested:       big.NewFloat(10),
+			EgldTargetPrice:          big.NewFloat(300),
+			MexTargetPrice:           big.NewFloat(0),
+			InvestmentDurationInDays: 365,
+		}
+
+		result, err := service.LiquidStakeStrategy(&input, liquidStaking, nil, egldInitialPrice, mexInitialPrice)
+		require.NoError(t, err)
+
+		// (1 + 0.1 / 365) ^ 365 = 1.105155782
+		assert.Equal(t, "11.05156", result.TotalBalanceInEgld.Text('f', 5))
+		assert.Equal(t, "1.05156", result.ProfitInEgld.Text('f', 5))
+		assert.Equal(t, "210.31", result.ProfitInUSD.Text('f', 2))
+		assert.Equal(t, "3315.47", result.TotalBalanceInUsd.Text('f', 2))
+		assert.Equal(t, "10.5156", result.ROI.Text('f', 4))
+		assert.True(t, BigFloatsAreEqual(*BigFloatZero, *result.TotalBalanceInMex),
+			"expected no MEX without farming, got %v", result.TotalBalanceInMex)
+
+		// compounding daily yields more than staking without redelegating
+		input.EgldAPR = liquidStaking.APR
+		stakeResult, err := service.StakeStrategy(TokenTypeEgld, &input, egldInitialPrice)
+		require.NoError(t, err)
+		assert.Equal(t, 1, result.ProfitInEgld.Cmp(stakeResult.ProfitInEgld))
+	})
+
+	t.Run("liquid token staked in a farm", func(t *testing.T) {
+		input := StrategiesInput{
+			EgldTokensInvested:       big.NewFloat(10),
+			EgldTargetPrice:          big.NewFloat(200),
+			MexTargetPrice:           big.NewFloat(0.0002),
+			InvestmentDurationInDays: 0,
+		}
+		farm := fetcher.MexFarm{
+			FarmingTokenIdentifier: liquidStaking.Token,
+			UnlockedRewardsAPR:     big.NewFloat(5),
+			LockedRewardsAPR:       big.NewFloat(15),
+		}
+
+		result, err := service.LiquidStakeStrategy(&input, liquidStaking, &farm, egldInitialPrice, mexInitialPrice)
+		require.NoError(t, err)
+		assert.Equal(t, "2000.00", result.TotalBalanceInUsd.Text('f', 2))
+
+		input.InvestmentDurationInDays = 365
+		input.RewardsInLockedMEX = true
+		result, err = service.LiquidStakeStrategy(&input, liquidStaking, &farm, egldInitialPrice, mexInitialPrice)
+		require.NoError(t, err)
+
+		// 15% of 2000 USD is received as MEX
+		assert.Equal(t, "1500000.00", result.ProfitInMex.Text('f', 2))
+		assert.Equal(t, "1500000.00", result.TotalBalanceInMex.Text('f', 2))
+		assert.Equal(t, "510.31", result.ProfitInUSD.Text('f', 2))
+	})
+
+	t.Run("invalid exchange rate", func(t *testing.T) {
+		input := StrategiesInput{EgldTokensInvested: big.NewFloat(10)}
+		invalid := liquidStaking
+		invalid.ExchangeRate = big.NewFloat(0)
+
+		_, err := service.LiquidStakeStrategy(&input, invalid, nil, egldInitialPrice, mexInitialPrice)
+		assert.Error(t, err)
+	})
+}
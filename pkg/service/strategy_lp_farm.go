@@ -0,0 +1,134 @@
+package service
+
+import (
+	"math/big"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+const (
+	// EgldMexLPFarmTokenName is the name of the token received for staking EGLD-MEX LP tokens in their farm
+	EgldMexLPFarmTokenName = "EGLDMEXLPStaked"
+
+	// wrappedEgldIdentifierPrefix is the prefix of the identifier of wrapped EGLD, the token used by the pools
+	wrappedEgldIdentifierPrefix = "WEGLD-"
+)
+
+// LPFarmStrategy returns a StrategyResult representing the result of providing the whole portfolio as liquidity to the
+// EGLD-MEX pool and staking the LP tokens in the farm, without reinvesting the MEX rewards. The value of the position at
+// the target prices accounts for the impermanent loss caused by the change of the EGLD/MEX price ratio.
+func (s *Service) LPFarmStrategy(input *StrategiesInput, farm fetcher.MexFarm, pool fetcher.LiquidityPool, egldInitialPrice, mexInitialPrice *big.Float) (*StrategyResult, error) {
+	egldProvided, mexProvided, portfolioValueInUSD, err := provideLiquidity(input, pool, egldInitialPrice, mexInitialPrice)
+	if err != nil {
+		return nil, err
+	}
+	log.Info("providing %s EGLD and %s MEX as liquidity", egldProvided.String(), mexProvided.String())
+
+	// the product of the token amounts of the position is constant, so at the target price ratio
+	// egldBalance = sqrt(k * mexTargetPrice / egldTargetPrice) and mexBalance = sqrt(k * egldTargetPrice / mexTargetPrice)
+	k := &big.Float{}
+	k.Mul(egldProvided, mexProvided)
+
+	egldBalance := &big.Float{}
+	egldBalance.Mul(k, input.MexTargetPrice)
+	egldBalance.Quo(egldBalance, input.EgldTargetPrice)
+	egldBalance.Sqrt(egldBalance)
+
+	mexBalance := &big.Float{}
+	mexBalance.Mul(k, input.EgldTargetPrice)
+	mexBalance.Quo(mexBalance, input.MexTargetPrice)
+	mexBalance.Sqrt(mexBalance)
+
+	positionValueInUSD := &big.Float{}
+	positionValueInUSD.Mul(egldBalance, input.EgldTargetPrice)
+	positionMexValueInUSD := &big.Float{}
+	positionMexValueInUSD.Mul(mexBalance, input.MexTargetPrice)
+	positionValueInUSD.Add(positionValueInUSD, positionMexValueInUSD)
+
+	// compare the position with holding the tokens which were provided as liquidity
+	holdValueInUSD := &big.Float{}
+	holdValueInUSD.Mul(egldProvided, input.EgldTargetPrice)
+	holdMexValueInUSD := &big.Float{}
+	holdMexValueInUSD.Mul(mexProvided, input.MexTargetPrice)
+	holdValueInUSD.Add(holdValueInUSD, holdMexValueInUSD)
+
+	impermanentLoss := &big.Float{}
+	impermanentLoss.Sub(holdValueInUSD, positionValueInUSD)
+	impermanentLossPercentage := &big.Float{}
+	if holdValueInUSD.Sign() > 0 {
+		impermanentLossPercentage.Quo(impermanentLoss, holdValueInUSD)
+		impermanentLossPercentage.Mul(impermanentLossPercentage, BigFloatOneHundred)
+	}
+
+	// the farm APR is computed on the USD value of the position, and the rewards are paid in MEX
+	farmAPR := farm.UnlockedRewardsAPR
+	if input.RewardsInLockedMEX {
+		stakedValueInMex := &big.Float{}
+		stakedValueInMex.Quo(portfolioValueInUSD, mexInitialPrice)
+		farmAPR = boostedLockedAPR(farm.LockedRewardsAPR, stakedValueInMex, input)
+	}
+
+	percentageOfTheYearReceivingAPR := &big.Float{}
+	percentageOfTheYearReceivingAPR.Quo(big.NewFloat(float64(input.InvestmentDurationInDays)), BigFloatDaysInYear)
+
+	rewardsInUSD := &big.Float{}
+	rewardsInUSD.Mul(portfolioValueInUSD, farmAPR)
+	rewardsInUSD.Mul(rewardsInUSD, percentageOfTheYearReceivingAPR)
+	rewardsInUSD.Quo(rewardsInUSD, BigFloatOneHundred)
+
+	rewardsInMex := &big.Float{}
+	rewardsInMex.Quo(rewardsInUSD, mexInitialPrice)
+
+	rewardsValueInUSD := &big.Float{}
+	rewardsValueInUSD.Mul(rewardsInMex, input.MexTargetPrice)
+
+	roi := &big.Float{}
+	if portfolioValueInUSD.Sign() > 0 {
+		roi.Quo(rewardsInUSD, portfolioValueInUSD)
+		roi.Mul(roi, BigFloatOneHundred)
+	}
+
+	result := NewStrategyResult()
+	result.ProfitInMex = rewardsInMex
+	result.ProfitInUSD = rewardsInUSD
+	result.TotalBalanceInEgld = egldBalance
+	result.TotalBalanceInMex.Add(mexBalance, rewardsInMex)
+	result.TotalBalanceInUsd.Add(positionValueInUSD, rewardsValueInUSD)
+	result.ROI = roi
+	result.HoldBalanceInUsd = holdValueInUSD
+	result.ImpermanentLossInUsd = impermanentLoss
+	result.ImpermanentLossPercentage = impermanentLossPercentage
+
+	return result, nil
+}
+
+// provideLiquidity returns the amounts of EGLD and MEX obtained by converting the whole portfolio at the ratio of the
+// pool reserves, alongside the USD value of the portfolio
+func provideLiquidity(input *StrategiesInput, pool fetcher.LiquidityPool, egldInitialPrice, mexInitialPrice *big.Float) (*big.Float, *big.Float, *big.Float, error) {
+	egldReserve, mexReserve, err := egldMexReserves(pool)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	portfolioValueInUSD := &big.Float{}
+	portfolioValueInUSD.Mul(input.EgldTokensInvested, egldInitialPrice)
+	mexValueInUSD := &big.Float{}
+	mexValueInUSD.Mul(input.MexTokensInvested, mexInitialPrice)
+	portfolioValueInUSD.Add(portfolioValueInUSD, mexValueInUSD)
+
+	mexPerEgld := &big.Float{}
+	mexPerEgld.Quo(mexReserve, egldReserve)
+
+	// egldProvided = portfolioValue / (egldPrice + mexPerEgld * mexPrice)
+	liquidityUnitPrice := &big.Float{}
+	liquidityUnitPrice.Mul(mexPerEgld, mexInitialPrice)
+	liquidityUnitPrice.Add(liquidityUnitPrice, egldInitialPrice)
+
+	egldProvided := &big.Float{}
+	egldProvided.Quo(portfolioValueInUSD, liquidityUnitPrice)
+	mexProvided := &big.Float{}
+	mexProvided.Mul(egldProvided, mexPerEgld)
+
+	return egldProvided, mexProvided, portfolioValueInUSD, nil
+}
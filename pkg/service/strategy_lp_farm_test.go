@@ -0,0 +1,96 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_LPFarmStrategy(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// test setup
+	service := Service{}
+	farm := fetcher.MexFarm{
+		FarmTokenName:          EgldMexLPFarmTokenName,
+		FarmingTokenIdentifier: "EGLDMEX-1331c2",
+		LockedRewardsAPR:       big.NewFloat(80),
+		UnlockedRewardsAPR:     big.NewFloat(20),
+	}
+	// the pool price matches the USD prices: 1 EGLD = 200 / 0.0002 = 1,000,000 MEX
+	pool := fetcher.LiquidityPool{
+		LPTokenIdentifier:     "EGLDMEX-1331c2",
+		FirstTokenIdentifier:  "MEX-455c57",
+		SecondTokenIdentifier: "WEGLD-bd4d79",
+		FirstTokenReserve:     big.NewFloat(100000000000),
+		SecondTokenReserve:    big.NewFloat(100000),
+		FeePercent:            big.NewFloat(0.3),
+	}
+	egldInitialPrice := big.NewFloat(200)
+	mexInitialPrice := big.NewFloat(0.0002)
+
+	t.Run("unchanged prices have no impermanent loss", func(t *testing.T) {
+		input := StrategiesInput{
+			EgldTokensInvested:       big.NewFloat(10),
+			MexTokensInvested:        big.NewFloat(0),
+			EgldTargetPrice:          big.NewFloat(200),
+			MexTargetPrice:           big.NewFloat(0.0002),
+			InvestmentDurationInDays: 365,
+			RewardsInLockedMEX:       false,
+		}
+
+		result, err := service.LPFarmStrategy(&input, farm, pool, egldInitialPrice, mexInitialPrice)
+		require.NoError(t, err)
+
+		// 2000 USD are provided as 5 EGLD and 5,000,000 MEX, and 20% APR are received as MEX
+		assert.Equal(t, "5.00000", result.TotalBalanceInEgld.Text('f', 5))
+		assert.Equal(t, "7000000.00", result.TotalBalanceInMex.Text('f', 2))
+		assert.Equal(t, "2000000.00", result.ProfitInMex.Text('f', 2))
+		assert.Equal(t, "400.00", result.ProfitInUSD.Text('f', 2))
+		assert.Equal(t, "2400.00", result.TotalBalanceInUsd.Text('f', 2))
+		assert.Equal(t, "2000.00", result.HoldBalanceInUsd.Text('f', 2))
+		assert.Equal(t, "0.000", result.ImpermanentLossInUsd.Text('f', 3))
+		assert.Equal(t, "20.00", result.ROI.Text('f', 2))
+	})
+
+	t.Run("EGLD price doubles", func(t *testing.T) {
+		input := StrategiesInput{
+			EgldTokensInvested:       big.NewFloat(5),
+			MexTokensInvested:        big.NewFloat(5000000),
+			EgldTargetPrice:          big.NewFloat(400),
+			MexTargetPrice:           big.NewFloat(0.0002),
+			InvestmentDurationInDays: 0,
+			RewardsInLockedMEX:       true,
+		}
+
+		result, err := service.LPFarmStrategy(&input, farm, pool, egldInitialPrice, mexInitialPrice)
+		require.NoError(t, err)
+
+		// when the price ratio doubles, the impermanent loss is 1 - 2*sqrt(2)/3 = 5.719%
+		assert.Equal(t, "3000.00", result.HoldBalanceInUsd.Text('f', 2))
+		assert.Equal(t, "2828.43", result.TotalBalanceInUsd.Text('f', 2))
+		assert.Equal(t, "171.57", result.ImpermanentLossInUsd.Text('f', 2))
+		assert.Equal(t, "5.719", result.ImpermanentLossPercentage.Text('f', 3))
+		assert.Equal(t, "3.53553", result.TotalBalanceInEgld.Text('f', 5))
+		assert.True(t, BigFloatsAreEqual(*BigFloatZero, *result.ProfitInMex),
+			"expected no rewards for a 0 days investment, got %v", result.ProfitInMex)
+	})
+
+	t.Run("empty pool", func(t *testing.T) {
+		input := StrategiesInput{
+			EgldTokensInvested: big.NewFloat(5),
+			MexTokensInvested:  big.NewFloat(0),
+			EgldTargetPrice:    big.NewFloat(400),
+			MexTargetPrice:     big.NewFloat(0.0002),
+		}
+		emptyPool := pool
+		emptyPool.FirstTokenReserve = big.NewFloat(0)
+
+		_, err := service.LPFarmStrategy(&input, farm, emptyPool, egldInitialPrice, mexInitialPrice)
+		assert.Error(t, err, "expected an error for a pool without reserves")
+	})
+}
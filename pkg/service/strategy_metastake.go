@@ -0,0 +1,53 @@
+package service
+
+import (
+	"math/big"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+)
+
+// MetastakeStrategy returns a StrategyResult representing the result of providing the portfolio as liquidity to the
+// EGLD-MEX pool, staking the LP tokens in the farm and metastaking the farm tokens, so the EGLD part of the liquidity is
+// also delegated to the staking provider. The delegation rewards are redelegated each RedelegationIntervalInDays if
+// redelegate is true.
+func (s *Service) MetastakeStrategy(input *StrategiesInput, farm fetcher.MexFarm, pool fetcher.LiquidityPool, egldInitialPrice, mexInitialPrice *big.Float, redelegate bool) (*StrategyResult, error) {
+	result, err := s.LPFarmStrategy(input, farm, pool, egldInitialPrice, mexInitialPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	egldProvided, _, portfolioValueInUSD, err := provideLiquidity(input, pool, egldInitialPrice, mexInitialPrice)
+	if err != nil {
+		return nil, err
+	}
+
+	// the delegated amount is the EGLD provided as liquidity; its variation caused by the price changes is ignored
+	delegationInput := *input
+	delegationInput.EgldTokensInvested = egldProvided
+
+	var delegationResult *StrategyResult
+	if redelegate {
+		delegationResult, err = s.RedelegateStrategy(TokenTypeEgld, &delegationInput, egldInitialPrice)
+	} else {
+		delegationResult, err = s.StakeStrategy(TokenTypeEgld, &delegationInput, egldInitialPrice)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	delegationRewardsValueInUSD := &big.Float{}
+	delegationRewardsValueInUSD.Mul(delegationResult.ProfitInEgld, input.EgldTargetPrice)
+
+	result.ProfitInEgld.Copy(delegationResult.ProfitInEgld)
+	result.ProfitInUSD.Add(result.ProfitInUSD, delegationResult.ProfitInUSD)
+	result.TotalBalanceInEgld.Add(result.TotalBalanceInEgld, delegationResult.ProfitInEgld)
+	result.TotalBalanceInUsd.Add(result.TotalBalanceInUsd, delegationRewardsValueInUSD)
+	result.AssumedAPRCurve = delegationResult.AssumedAPRCurve
+
+	if portfolioValueInUSD.Sign() > 0 {
+		result.ROI.Quo(result.ProfitInUSD, portfolioValueInUSD)
+		result.ROI.Mul(result.ROI, BigFloatOneHundred)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_MetastakeStrategy(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// test setup
+	service := Service{}
+	farm := fetcher.MexFarm{
+		Address:                "erd1farm",
+		FarmTokenName:          EgldMexLPFarmTokenName,
+		FarmingTokenIdentifier: "EGLDMEX-1331c2",
+		LockedRewardsAPR:       big.NewFloat(80),
+		UnlockedRewardsAPR:     big.NewFloat(20),
+	}
+	pool := fetcher.LiquidityPool{
+		Address:               "erd1pair",
+		LPTokenIdentifier:     "EGLDMEX-1331c2",
+		FirstTokenIdentifier:  "WEGLD-bd4d79",
+		SecondTokenIdentifier: "MEX-455c57",
+		FirstTokenReserve:     big.NewFloat(100000),
+		SecondTokenReserve:    big.NewFloat(100000000000),
+		FeePercent:            big.NewFloat(0.3),
+	}
+	egldInitialPrice := big.NewFloat(200)
+	mexInitialPrice := big.NewFloat(0.0002)
+
+	newInput := func(durationInDays int) *StrategiesInput {
+		return &StrategiesInput{
+			EgldTokensInvested:         big.NewFloat(10),
+			MexTokensInvested:          big.NewFloat(0),
+			EgldTargetPrice:            big.NewFloat(200),
+			MexTargetPrice:             big.NewFloat(0.0002),
+			EgldAPR:                    big.NewFloat(10),
+			InvestmentDurationInDays:   durationInDays,
+			RedelegationIntervalInDays: 7,
+		}
+	}
+
+	t.Run("farm and delegation rewards", func(t *testing.T) {
+		result, err := service.MetastakeStrategy(newInput(365), farm, pool, egldInitialPrice, mexInitialPrice, false)
+		require.NoError(t, err)
+
+		// 5 EGLD are delegated at 10% APR, and the 2000 USD position receives 20% APR in MEX
+		assert.Equal(t, "0.50000", result.ProfitInEgld.Text('f', 5))
+		assert.Equal(t, "2000000.00", result.ProfitInMex.Text('f', 2))
+		assert.Equal(t, "5.50000", result.TotalBalanceInEgld.Text('f', 5))
+		assert.Equal(t, "500.00", result.ProfitInUSD.Text('f', 2))
+		assert.Equal(t, "2500.00", result.TotalBalanceInUsd.Text('f', 2))
+		assert.Equal(t, "25.00", result.ROI.Text('f', 2))
+	})
+
+	t.Run("redelegating the delegation rewards", func(t *testing.T) {
+		stakeResult, err := service.MetastakeStrategy(newInput(365), farm, pool, egldInitialPrice, mexInitialPrice, false)
+		require.NoError(t, err)
+
+		redelegateResult, err := service.MetastakeStrategy(newInput(365), farm, pool, egldInitialPrice, mexInitialPrice, true)
+		require.NoError(t, err)
+
+		assert.Equal(t, 1, redelegateResult.ProfitInEgld.Cmp(stakeResult.ProfitInEgld),
+			"expected the redelegated rewards %v to be larger than the staking rewards %v",
+			redelegateResult.ProfitInEgld, stakeResult.ProfitInEgld)
+		assert.True(t, BigFloatsAreEqual(*stakeResult.ProfitInMex, *redelegateResult.ProfitInMex),
+			"expected the farm rewards to be equal")
+	})
+}
@@ -23,7 +23,7 @@ func (s *Service) RedelegateStrategy(tokenType TokenType, input *StrategiesInput
 		initialTokenBalance = input.MexTokensInvested
 		targetPrice = input.MexTargetPrice
 		if input.RewardsInLockedMEX {
-			tokenAPR = input.MexAPRLocked
+			tokenAPR = boostedLockedAPR(input.MexAPRLocked, input.MexTokensInvested, input)
 		} else {
 			tokenAPR = input.MexAPRUnlocked
 		}
@@ -44,8 +44,14 @@ func (s *Service) RedelegateStrategy(tokenType TokenType, input *StrategiesInput
 	log.Info("input.InvestmentDurationInDays is %d", input.InvestmentDurationInDays)
 	log.Info("input.RedelegationIntervalInDays is %d", input.RedelegationIntervalInDays)
 	// compound the interest for the number of redelegations cycles
+	// the EGLD APR changes over the investment with the inflation and the staking ratio
+	aprCurve := egldAPRCurve(tokenType, input)
 	cycleRewardsDays := input.RedelegationIntervalInDays
 	for ; cycleRewardsDays <= input.InvestmentDurationInDays; cycleRewardsDays += input.RedelegationIntervalInDays {
+		if len(aprCurve) > 0 {
+			APRToBeReceivedInOneRedelegationCycle = aprCurve.RewardsRate(cycleRewardsDays-input.RedelegationIntervalInDays, cycleRewardsDays, tokenAPR)
+		}
+
 		interestReceived := &big.Float{}
 		interestReceived.Mul(APRToBeReceivedInOneRedelegationCycle, tokenBalance)
 		log.Info("in REDELEGATION the earned interest for cycleDays value %d is %s", cycleRewardsDays, interestReceived.String())
@@ -75,6 +81,9 @@ func (s *Service) RedelegateStrategy(tokenType TokenType, input *StrategiesInput
 	interestReceivedForRemainingDays.Mul(remainingDaysAsPercentageOfYear, tokenAPR)
 	interestReceivedForRemainingDays.Mul(interestReceivedForRemainingDays, tokenBalance)
 	interestReceivedForRemainingDays.Quo(interestReceivedForRemainingDays, BigFloatOneHundred)
+	if len(aprCurve) > 0 {
+		interestReceivedForRemainingDays.Mul(aprCurve.RewardsRate(input.InvestmentDurationInDays-remainingDays, input.InvestmentDurationInDays, tokenAPR), tokenBalance)
+	}
 	tokenBalance.Add(tokenBalance, interestReceivedForRemainingDays)
 
 	earnedInterestInTokens := &big.Float{}
@@ -91,6 +100,7 @@ func (s *Service) RedelegateStrategy(tokenType TokenType, input *StrategiesInput
 	result.ProfitInUSD.Copy(interestValueInUSD)
 	result.TotalBalanceInUsd.Mul(tokenBalance, targetPrice)
 	result.ROI.Copy(roi)
+	result.AssumedAPRCurve = aprCurve
 
 	switch tokenType {
 	case TokenTypeEgld:
@@ -2,6 +2,7 @@ package service
 
 import (
 	"math/big"
+	"time"
 
 	"github.com/silviutroscot/istari-vision/pkg/log"
 )
@@ -23,6 +24,49 @@ type StrategyResult struct {
 	TotalBalanceInUsd *big.Float
 	// ROI the percentage of profit we make in terms of EGLD; i.e. if at the beginning we invested 1 EGLD, and now we have 2 EGLD, ROI=100%
 	ROI *big.Float
+
+	// HoldBalanceInUsd the value in USD, using the target prices, of the tokens provided as liquidity if they were held
+	// instead; nil if the strategy does not provide liquidity
+	HoldBalanceInUsd *big.Float
+	// ImpermanentLossInUsd the difference between HoldBalanceInUsd and the value of the liquidity position, excluding the
+	// rewards; nil if the strategy does not provide liquidity
+	ImpermanentLossInUsd *big.Float
+	// ImpermanentLossPercentage ImpermanentLossInUsd as percentage of HoldBalanceInUsd
+	ImpermanentLossPercentage *big.Float
+
+	// UnbondingPeriodInDays the number of days between starting the exit from the strategy and receiving the tokens
+	UnbondingPeriodInDays int
+	// UnbondingStartDate the date when the exit from the strategy starts
+	UnbondingStartDate time.Time
+	// LiquidAt the date when all the tokens, including the rewards, can be sold
+	LiquidAt time.Time
+	// LostYieldInUsd the value of the rewards not received because the exit starts before the target date; nil if the
+	// exit starts at the target date
+	LostYieldInUsd *big.Float
+
+	// LockedMexAtTarget the amount of MEX rewards which are still locked at the target date; nil if the rewards are
+	// not locked
+	LockedMexAtTarget *big.Float
+	// SellableBalanceInUsd the value in USD of the tokens which can be sold at the target date, which excludes the
+	// locked MEX; nil if the rewards are not locked
+	SellableBalanceInUsd *big.Float
+	// UnlockSchedule the dates when the locked MEX rewards unlock
+	UnlockSchedule []UnlockTranche
+
+	// ContributionsCount the number of periodic contributions made after the initial investment
+	ContributionsCount int
+	// TotalContributedInTokens the amount of tokens bought, including the initial investment; nil without
+	// contributions
+	TotalContributedInTokens *big.Float
+	// TotalContributedInUsd the USD paid for TotalContributedInTokens
+	TotalContributedInUsd *big.Float
+	// AverageCostBasis the average USD price paid for the tokens
+	AverageCostBasis *big.Float
+	// NetProfitInUsd TotalBalanceInUsd minus TotalContributedInUsd
+	NetProfitInUsd *big.Float
+
+	// AssumedAPRCurve the projected EGLD APR used by the strategy; empty if the APR is constant
+	AssumedAPRCurve APRCurve
 }
 
 // Equals return true if the other StrategyResult equals the strategy
@@ -49,6 +93,61 @@ func (r *StrategyResult) MarshallToJSON() StrategyResultJSON {
 	// use a more aggressive truncation for ROI
 	result.ROI = r.ROI.Text('f', 6)
 
+	if r.HoldBalanceInUsd != nil {
+		result.HoldBalanceInUsd = r.HoldBalanceInUsd.Text('f', FloatingPointAccuracy)
+	}
+	if r.ImpermanentLossInUsd != nil {
+		result.ImpermanentLossInUsd = r.ImpermanentLossInUsd.Text('f', FloatingPointAccuracy)
+	}
+	if r.ImpermanentLossPercentage != nil {
+		result.ImpermanentLossPercentage = r.ImpermanentLossPercentage.Text('f', 6)
+	}
+
+	result.UnbondingPeriodInDays = r.UnbondingPeriodInDays
+	if !r.UnbondingStartDate.IsZero() {
+		result.UnbondingStartDate = r.UnbondingStartDate.Format(DateFormat)
+	}
+	if !r.LiquidAt.IsZero() {
+		result.LiquidAt = r.LiquidAt.Format(DateFormat)
+	}
+	if r.LostYieldInUsd != nil {
+		result.LostYieldInUsd = r.LostYieldInUsd.Text('f', FloatingPointAccuracy)
+	}
+	if r.LockedMexAtTarget != nil {
+		result.LockedMexAtTarget = r.LockedMexAtTarget.Text('f', FloatingPointAccuracy)
+	}
+	if r.SellableBalanceInUsd != nil {
+		result.SellableBalanceInUsd = r.SellableBalanceInUsd.Text('f', FloatingPointAccuracy)
+	}
+	if r.TotalContributedInUsd != nil {
+		result.ContributionsCount = r.ContributionsCount
+		result.TotalContributedInTokens = r.TotalContributedInTokens.Text('f', FloatingPointAccuracy)
+		result.TotalContributedInUsd = r.TotalContributedInUsd.Text('f', FloatingPointAccuracy)
+		result.AverageCostBasis = r.AverageCostBasis.Text('f', FloatingPointAccuracy)
+		result.NetProfitInUsd = r.NetProfitInUsd.Text('f', FloatingPointAccuracy)
+	}
+	for _, period := range r.AssumedAPRCurve {
+		periodJSON := APRPeriodJSON{
+			StartDate: period.StartDate.Format(DateFormat),
+			EndDate:   period.EndDate.Format(DateFormat),
+			APR:       period.APR.Text('f', FloatingPointAccuracy),
+		}
+		// the recorded APRs of a backtest have no inflation and staking ratio assumptions
+		if period.InflationRate != nil {
+			periodJSON.InflationRate = period.InflationRate.Text('f', FloatingPointAccuracy)
+		}
+		if period.StakingRatio != nil {
+			periodJSON.StakingRatio = period.StakingRatio.Text('f', FloatingPointAccuracy)
+		}
+		result.AssumedAPRCurve = append(result.AssumedAPRCurve, periodJSON)
+	}
+	for _, tranche := range r.UnlockSchedule {
+		result.UnlockSchedule = append(result.UnlockSchedule, UnlockTrancheJSON{
+			Date:        tranche.Date.Format(DateFormat),
+			AmountInMex: tranche.Amount.Text('f', FloatingPointAccuracy),
+		})
+	}
+
 	return result
 }
 
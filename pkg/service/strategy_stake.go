@@ -17,7 +17,7 @@ func (s *Service) StakeStrategy(tokenType TokenType, input *StrategiesInput, tok
 		tokenBalance = input.MexTokensInvested
 		targetPrice = input.MexTargetPrice
 		if input.RewardsInLockedMEX {
-			tokenAPR = input.MexAPRLocked
+			tokenAPR = boostedLockedAPR(input.MexAPRLocked, input.MexTokensInvested, input)
 		} else {
 			tokenAPR = input.MexAPRUnlocked
 		}
@@ -38,6 +38,12 @@ func (s *Service) StakeStrategy(tokenType TokenType, input *StrategiesInput, tok
 	APRToBeReceived := &big.Float{}
 	APRToBeReceived.Mul(tokenAPR, percentageOfTheYearReceivingAPR)
 	APRToBeReceived.Quo(APRToBeReceived, big.NewFloat(100.0))
+
+	// the EGLD APR changes over the investment with the inflation and the staking ratio
+	aprCurve := egldAPRCurve(tokenType, input)
+	if len(aprCurve) > 0 {
+		APRToBeReceived = aprCurve.RewardsRate(0, input.InvestmentDurationInDays, tokenAPR)
+	}
 	log.Info("apr to be received is %s", APRToBeReceived)
 	log.Info("tokens balance is %s", tokenBalance)
 
@@ -64,6 +70,7 @@ func (s *Service) StakeStrategy(tokenType TokenType, input *StrategiesInput, tok
 	result.TotalBalanceInUsd = totalUSDValue
 	result.ProfitInUSD = USDValueOfEarnedTokens
 	result.ROI = roi
+	result.AssumedAPRCurve = aprCurve
 
 	switch tokenType {
 	case TokenTypeEgld:
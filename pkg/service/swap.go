@@ -1,11 +1,18 @@
 package service
 
 import (
+	"fmt"
 	"math/big"
+	"strings"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
 )
 
 const (
 	FloatPrecision = 30
+
+	// mexIdentifierPrefix is the prefix of the identifier of MEX
+	mexIdentifierPrefix = "MEX-"
 )
 
 // SwapTokens returns the amount of destinationToken corresponding to the amount of firstToken provided, based on their USD price
@@ -18,3 +25,177 @@ func SwapTokens(amount, firstTokenValueInUSD, destinationTokenValueInUSD *big.Fl
 
 	return convertedMexAmount
 }
+
+// SwapResult represents a swap simulated against the reserves of a liquidity pool
+type SwapResult struct {
+	TokenIn   TokenType
+	TokenOut  TokenType
+	AmountIn  *big.Float
+	AmountOut *big.Float
+	// Fee the amount of TokenIn kept by the pool as swap fee
+	Fee *big.Float
+	// FeeInUsd the value of Fee at the initial price of TokenIn
+	FeeInUsd *big.Float
+	// SpotPrice the amount of TokenOut per TokenIn at the pool reserves before the swap
+	SpotPrice *big.Float
+	// EffectivePrice the amount of TokenOut received per TokenIn, after the fee and the price impact
+	EffectivePrice *big.Float
+	// PriceImpactPercentage the decrease of the price caused by the swap size, excluding the fee, as percentage of
+	// SpotPrice
+	PriceImpactPercentage *big.Float
+}
+
+// SwapResultJSON represents a SwapResult formatted for the API responses
+type SwapResultJSON struct {
+	TokenIn               string
+	TokenOut              string
+	AmountIn              string
+	AmountOut             string
+	Fee                   string
+	FeeInUsd              string
+	SpotPrice             string
+	EffectivePrice        string
+	PriceImpactPercentage string
+}
+
+// MarshallToJSON returns the SwapResultJSON of the swap
+func (r *SwapResult) MarshallToJSON() SwapResultJSON {
+	return SwapResultJSON{
+		TokenIn:               r.TokenIn.String(),
+		TokenOut:              r.TokenOut.String(),
+		AmountIn:              r.AmountIn.Text('f', FloatingPointAccuracy),
+		AmountOut:             r.AmountOut.Text('f', FloatingPointAccuracy),
+		Fee:                   r.Fee.Text('f', FloatingPointAccuracy),
+		FeeInUsd:              r.FeeInUsd.Text('f', FloatingPointAccuracy),
+		SpotPrice:             r.SpotPrice.Text('f', FloatingPointAccuracy),
+		EffectivePrice:        r.EffectivePrice.Text('f', FloatingPointAccuracy),
+		PriceImpactPercentage: r.PriceImpactPercentage.Text('f', FloatingPointAccuracy),
+	}
+}
+
+// ConstantProductSwap returns the amount of tokens received for swapping amountIn in a pool with the given reserves,
+// which keeps reserveIn * reserveOut constant, and the fee kept by the pool, as feePercent of amountIn
+func ConstantProductSwap(amountIn, reserveIn, reserveOut, feePercent *big.Float) (*big.Float, *big.Float) {
+	fee := &big.Float{}
+	if feePercent != nil {
+		fee.Mul(amountIn, feePercent)
+		fee.Quo(fee, BigFloatOneHundred)
+	}
+
+	amountInAfterFee := &big.Float{}
+	amountInAfterFee.Sub(amountIn, fee)
+
+	// amountOut = reserveOut * amountInAfterFee / (reserveIn + amountInAfterFee)
+	newReserveIn := &big.Float{}
+	newReserveIn.Add(reserveIn, amountInAfterFee)
+
+	amountOut := &big.Float{}
+	amountOut.Mul(reserveOut, amountInAfterFee)
+	amountOut.Quo(amountOut, newReserveIn)
+
+	return amountOut, fee
+}
+
+// SwapInPool returns the SwapResult of swapping amountIn of tokenIn for the other token of the EGLD-MEX pool
+func SwapInPool(tokenIn TokenType, amountIn *big.Float, pool fetcher.LiquidityPool, tokenInPrice *big.Float) (*SwapResult, error) {
+	egldReserve, mexReserve, err := egldMexReserves(pool)
+	if err != nil {
+		return nil, err
+	}
+
+	swap := &SwapResult{TokenIn: TokenTypeEgld, TokenOut: TokenTypeMex, AmountIn: amountIn}
+	reserveIn, reserveOut := egldReserve, mexReserve
+	if tokenIn == TokenTypeMex {
+		swap.TokenIn, swap.TokenOut = TokenTypeMex, TokenTypeEgld
+		reserveIn, reserveOut = mexReserve, egldReserve
+	}
+
+	swap.AmountOut, swap.Fee = ConstantProductSwap(amountIn, reserveIn, reserveOut, pool.FeePercent)
+
+	swap.FeeInUsd = &big.Float{}
+	swap.FeeInUsd.Mul(swap.Fee, tokenInPrice)
+
+	swap.SpotPrice = &big.Float{}
+	swap.SpotPrice.Quo(reserveOut, reserveIn)
+
+	swap.EffectivePrice = &big.Float{}
+	swap.PriceImpactPercentage = &big.Float{}
+	if amountIn.Sign() > 0 {
+		swap.EffectivePrice.Quo(swap.AmountOut, amountIn)
+
+		// the price impact excluding the fee is amountInAfterFee / (reserveIn + amountInAfterFee)
+		amountInAfterFee := &big.Float{}
+		amountInAfterFee.Sub(amountIn, swap.Fee)
+		newReserveIn := &big.Float{}
+		newReserveIn.Add(reserveIn, amountInAfterFee)
+		swap.PriceImpactPercentage.Quo(amountInAfterFee, newReserveIn)
+		swap.PriceImpactPercentage.Mul(swap.PriceImpactPercentage, BigFloatOneHundred)
+	}
+
+	return swap, nil
+}
+
+// SwapInPoolToMatchDistribution returns the amount of EGLD and MEX obtained by swapping the tokens in the EGLD-MEX pool
+// to match the required distribution, based on the USD value; the swap is nil if the distribution already matches
+func (s *Service) SwapInPoolToMatchDistribution(EGLDTokensBalance, MEXTokensBalance, EGLDPercentage, EGLDPrice, MEXPrice *big.Float, pool fetcher.LiquidityPool) (*big.Float, *big.Float, *SwapResult, error) {
+	targetEgldBalance, targetMexBalance := s.SwapTokensToMatchDistribution(EGLDTokensBalance, MEXTokensBalance, EGLDPercentage, EGLDPrice, MEXPrice)
+
+	egldToSwap := &big.Float{}
+	egldToSwap.Sub(EGLDTokensBalance, targetEgldBalance)
+	mexToSwap := &big.Float{}
+	mexToSwap.Sub(MEXTokensBalance, targetMexBalance)
+
+	egldBalance := &big.Float{}
+	mexBalance := &big.Float{}
+	switch {
+	case egldToSwap.Cmp(EPSILON) == 1:
+		swap, err := SwapInPool(TokenTypeEgld, egldToSwap, pool, EGLDPrice)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		egldBalance.Copy(targetEgldBalance)
+		mexBalance.Add(MEXTokensBalance, swap.AmountOut)
+		return egldBalance, mexBalance, swap, nil
+	case mexToSwap.Cmp(EPSILON) == 1:
+		swap, err := SwapInPool(TokenTypeMex, mexToSwap, pool, MEXPrice)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		egldBalance.Add(EGLDTokensBalance, swap.AmountOut)
+		mexBalance.Copy(targetMexBalance)
+		return egldBalance, mexBalance, swap, nil
+	default:
+		egldBalance.Copy(EGLDTokensBalance)
+		mexBalance.Copy(MEXTokensBalance)
+		return egldBalance, mexBalance, nil, nil
+	}
+}
+
+// egldMexReserves returns the EGLD and MEX reserves of the EGLD-MEX pool
+func egldMexReserves(pool fetcher.LiquidityPool) (*big.Float, *big.Float, error) {
+	egldReserve, mexReserve := pool.FirstTokenReserve, pool.SecondTokenReserve
+	if !strings.HasPrefix(pool.FirstTokenIdentifier, wrappedEgldIdentifierPrefix) {
+		egldReserve, mexReserve = mexReserve, egldReserve
+	}
+
+	if egldReserve == nil || mexReserve == nil || egldReserve.Sign() <= 0 || mexReserve.Sign() <= 0 {
+		return nil, nil, fmt.Errorf("the liquidity pool %s has no reserves", pool.LPTokenIdentifier)
+	}
+
+	return egldReserve, mexReserve, nil
+}
+
+// egldMexPool returns the EGLD-MEX pool of the economics, if available
+func egldMexPool(economics Economics) (fetcher.LiquidityPool, bool) {
+	for _, pool := range economics.MexEconomics().Pools {
+		first, second := pool.FirstTokenIdentifier, pool.SecondTokenIdentifier
+		if strings.HasPrefix(second, wrappedEgldIdentifierPrefix) {
+			first, second = second, first
+		}
+		if strings.HasPrefix(first, wrappedEgldIdentifierPrefix) && strings.HasPrefix(second, mexIdentifierPrefix) {
+			return pool, true
+		}
+	}
+
+	return fetcher.LiquidityPool{}, false
+}
@@ -4,7 +4,9 @@ import (
 	"math/big"
 	"testing"
 
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func Test_SwapTokens(t *testing.T) {
@@ -72,3 +74,84 @@ func Test_SwapTokens(t *testing.T) {
 			tokenAmount, firstTokenConvertedAmount, expectedFirstTokenAmountAfterSwap)
 	})
 }
+
+func Test_ConstantProductSwap(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	t.Run("without fee", func(t *testing.T) {
+		amountOut, fee := ConstantProductSwap(big.NewFloat(100), big.NewFloat(1000), big.NewFloat(1000), nil)
+
+		// 1000 * 100 / 1100
+		assert.Equal(t, "90.9091", amountOut.Text('f', 4))
+		assert.Equal(t, 0, fee.Sign())
+	})
+
+	t.Run("with fee", func(t *testing.T) {
+		amountOut, fee := ConstantProductSwap(big.NewFloat(100), big.NewFloat(1000), big.NewFloat(1000), big.NewFloat(1))
+
+		// 1000 * 99 / 1099
+		assert.Equal(t, "90.0819", amountOut.Text('f', 4))
+		assert.Equal(t, "1.00", fee.Text('f', 2))
+	})
+}
+
+func TestService_SwapInPoolToMatchDistribution(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	service := Service{}
+	egldPrice := big.NewFloat(200)
+	mexPrice := big.NewFloat(0.0002)
+
+	// the MEX is the first token of the pool, so the reserves have to be swapped
+	pool := fetcher.LiquidityPool{
+		LPTokenIdentifier:     "EGLDMEX-1331c2",
+		FirstTokenIdentifier:  "MEX-455c57",
+		SecondTokenIdentifier: "WEGLD-bd4d79",
+		FirstTokenReserve:     big.NewFloat(1000000000),
+		SecondTokenReserve:    big.NewFloat(1000),
+		FeePercent:            big.NewFloat(0.3),
+	}
+
+	t.Run("swapping EGLD to MEX", func(t *testing.T) {
+		egldBalance, mexBalance, swap, err := service.SwapInPoolToMatchDistribution(big.NewFloat(10), big.NewFloat(0),
+			big.NewFloat(50), egldPrice, mexPrice, pool)
+		require.NoError(t, err)
+		require.NotNil(t, swap)
+
+		assert.Equal(t, "5.00", egldBalance.Text('f', 2))
+		assert.Equal(t, TokenTypeEgld, swap.TokenIn)
+		assert.Equal(t, TokenTypeMex, swap.TokenOut)
+		assert.Equal(t, "5.00", swap.AmountIn.Text('f', 2))
+		assert.Equal(t, "0.015", swap.Fee.Text('f', 3))
+		assert.Equal(t, "3.00", swap.FeeInUsd.Text('f', 2))
+		assert.Equal(t, "1000000", swap.SpotPrice.Text('f', 0))
+
+		// 1e9 * 4.985 / 1004.985, which is less than the 5000000 MEX received at the USD prices
+		assert.Equal(t, "4960273", mexBalance.Text('f', 0))
+		assert.Equal(t, "992055", swap.EffectivePrice.Text('f', 0))
+		assert.Equal(t, "0.4960", swap.PriceImpactPercentage.Text('f', 4))
+	})
+
+	t.Run("swapping MEX to EGLD", func(t *testing.T) {
+		egldBalance, mexBalance, swap, err := service.SwapInPoolToMatchDistribution(big.NewFloat(0), big.NewFloat(10000000),
+			big.NewFloat(50), egldPrice, mexPrice, pool)
+		require.NoError(t, err)
+		require.NotNil(t, swap)
+
+		assert.Equal(t, TokenTypeMex, swap.TokenIn)
+		assert.Equal(t, "5000000", mexBalance.Text('f', 0))
+		assert.Equal(t, -1, egldBalance.Cmp(big.NewFloat(5)), "expected less than 5 EGLD, got %v", egldBalance)
+	})
+
+	t.Run("the distribution already matches", func(t *testing.T) {
+		egldBalance, mexBalance, swap, err := service.SwapInPoolToMatchDistribution(big.NewFloat(5), big.NewFloat(5000000),
+			big.NewFloat(50), egldPrice, mexPrice, pool)
+		require.NoError(t, err)
+
+		assert.Nil(t, swap)
+		assert.Equal(t, "5.00", egldBalance.Text('f', 2))
+		assert.Equal(t, "5000000", mexBalance.Text('f', 0))
+	})
+}
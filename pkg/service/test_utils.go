@@ -12,7 +12,7 @@ var (
 func BigFloatsAreEqual(x, y big.Float) bool {
 	copyX := &big.Float{}
 	copyY := &big.Float{}
-	x.Copy(copyX)
-	y.Copy(copyY)
+	copyX.Copy(&x)
+	copyY.Copy(&y)
 	return copyX.Sub(copyX, copyY).Abs(copyX).Cmp(EPSILON) < 1
 }
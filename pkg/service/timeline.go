@@ -0,0 +1,82 @@
+package service
+
+import (
+	"sort"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+const (
+	// TimelineIntervalInDays is the number of days between two points of a timeline
+	TimelineIntervalInDays = 30
+	// maxTimelinePoints is the largest number of points of a timeline, the interval is widened to stay under it
+	maxTimelinePoints = 60
+)
+
+// TimelinePointJSON is the result of a strategy if the investment ended at the day of the point
+type TimelinePointJSON struct {
+	Day               int
+	Date              string
+	Strategy          string
+	TotalBalanceInUsd string
+	ROI               string
+}
+
+// Timeline returns the results of the strategies at regular intervals during the investment, each point ending the
+// investment at its day with the prices on the linear path between the initial and the target prices
+func (s *Service) Timeline(input *StrategiesInput, egldStakingProviders []fetcher.EgldStakingProvider, economics Economics) ([]TimelinePointJSON, error) {
+	strategies, err := s.Registry().Select(input.Strategies)
+	if err != nil {
+		log.Error("error selecting the strategies of the timeline: %s", err)
+		return nil, err
+	}
+
+	egldInitialPrice, mexInitialPrice, err := s.prepareStrategiesInput(input, egldStakingProviders, economics)
+	if err != nil {
+		return nil, err
+	}
+
+	interval := TimelineIntervalInDays
+	for input.InvestmentDurationInDays/interval > maxTimelinePoints {
+		interval *= 2
+	}
+
+	var days []int
+	for day := interval; day < input.InvestmentDurationInDays; day += interval {
+		days = append(days, day)
+	}
+	days = append(days, input.InvestmentDurationInDays)
+
+	var timeline []TimelinePointJSON
+	for _, day := range days {
+		pointInput := *input
+		pointInput.InvestmentDurationInDays = day
+		pointInput.EgldTargetPrice = modeledPrice(input, TokenTypeEgld, egldInitialPrice, day)
+		pointInput.MexTargetPrice = modeledPrice(input, TokenTypeMex, mexInitialPrice, day)
+		pointInput.EgldAPRCurve = ProjectEgldAPR(&pointInput)
+
+		results, err := s.runStrategies(strategies, &pointInput, economics, egldInitialPrice, mexInitialPrice)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([]string, 0, len(results))
+		for key := range results {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			timeline = append(timeline, TimelinePointJSON{
+				Day:               day,
+				Date:              input.StartDate.AddDate(0, 0, day).Format(DateFormat),
+				Strategy:          key,
+				TotalBalanceInUsd: results[key].TotalBalanceInUsd.Text('f', FloatingPointAccuracy),
+				ROI:               results[key].ROI.Text('f', FloatingPointAccuracy),
+			})
+		}
+	}
+
+	return timeline, nil
+}
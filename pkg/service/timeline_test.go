@@ -0,0 +1,57 @@
+package service
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_Timeline(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// test setup
+	service := Service{}
+	economics := Economics{
+		Prices: Prices{EGLD: "100"},
+		mexEconomics: fetcher.MexEconomics{
+			LockedRewardsAPR:   big.NewFloat(100),
+			UnlockedRewardsAPR: big.NewFloat(40),
+			Price:              big.NewFloat(0.0002),
+		},
+	}
+	providers := []fetcher.EgldStakingProvider{{APR: 0, Identity: "istari"}}
+
+	input := &StrategiesInput{
+		EgldTokensInvested:          big.NewFloat(10),
+		MexTokensInvested:           &big.Float{},
+		PercentageOfPortfolioInEgld: big.NewFloat(100),
+		PercentageOfPortfolioInMex:  &big.Float{},
+		EgldTargetPrice:             big.NewFloat(200),
+		MexTargetPrice:              big.NewFloat(0.0002),
+		EgldAPR:                     &big.Float{},
+		MexAPRLocked:                &big.Float{},
+		MexAPRUnlocked:              &big.Float{},
+		InvestmentDurationInDays:    75,
+		RedelegationIntervalInDays:  7,
+		StakingProvider:             "istari",
+		StartDate:                   time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC),
+		Strategies:                  []string{"hold"},
+	}
+
+	timeline, err := service.Timeline(input, providers, economics)
+	require.NoError(t, err)
+
+	// the points are at the days 30 and 60, and at the end of the investment
+	require.Len(t, timeline, 3)
+	assert.Equal(t, 30, timeline[0].Day)
+	assert.Equal(t, "2031-01-31", timeline[0].Date)
+	assert.Equal(t, "egld_hold", timeline[0].Strategy)
+	assert.Equal(t, "1400.0000000000", timeline[0].TotalBalanceInUsd)
+	assert.Equal(t, 75, timeline[2].Day)
+	assert.Equal(t, "2000.0000000000", timeline[2].TotalBalanceInUsd)
+}
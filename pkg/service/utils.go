@@ -36,3 +36,18 @@ func (s *Service) SwapTokensToMatchDistribution(EGLDTokensBalance, MEXTokensBala
 	return targetEGLDBalance, mexTargetBalance
 }
 
+// BigFloatPow returns x^n for a non-negative integer n
+func BigFloatPow(x *big.Float, n int) *big.Float {
+	result := big.NewFloat(1)
+	base := &big.Float{}
+	base.Copy(x)
+
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			result.Mul(result, base)
+		}
+		base.Mul(base, base)
+	}
+
+	return result
+}
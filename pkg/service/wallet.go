@@ -0,0 +1,220 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+const (
+	// egldMexLPIdentifierPrefix is the prefix of the identifier of the EGLD-MEX LP token, used if the pool is unknown
+	egldMexLPIdentifierPrefix = "EGLDMEX-"
+)
+
+var (
+	// lockedMexCollectionPrefixes are the prefixes of the collections of the locked MEX Meta ESDTs
+	lockedMexCollectionPrefixes = []string{"LKMEX-", "XMEX-"}
+
+	// ErrInvalidAddress is returned when the address of the imported wallet is invalid
+	ErrInvalidAddress = errors.New("invalid address")
+	// ErrWalletUnavailable is returned when the service has no wallet fetcher
+	ErrWalletUnavailable = errors.New("the wallet import is unavailable")
+)
+
+// WalletDelegation is the EGLD delegated by the wallet to a staking provider
+type WalletDelegation struct {
+	Contract string
+	// StakingProvider is the identity of the staking provider, empty if the contract is not a known provider
+	StakingProvider  string
+	APR              float64
+	ActiveStake      *big.Float
+	ClaimableRewards *big.Float
+	Unbonding        *big.Float
+}
+
+// WalletHoldings summarizes the holdings of a wallet as the inputs of the strategies
+type WalletHoldings struct {
+	Address          string
+	EgldBalance      *big.Float
+	MexBalance       *big.Float
+	LockedMexBalance *big.Float
+	// LPBalance is the balance of EGLD-MEX LP tokens; LPValueInUsd is nil if their value is unknown
+	LPBalance    *big.Float
+	LPValueInUsd *big.Float
+	Delegations  []WalletDelegation
+	// PendingRewardsInEgld is the sum of the claimable delegation rewards
+	PendingRewardsInEgld *big.Float
+
+	// EgldTokens and MexTokens are the amounts to be invested: the balances, the delegated and unbonding EGLD, the pending rewards
+	// and the share of the liquidity provided
+	EgldTokens *big.Float
+	MexTokens  *big.Float
+	// StakingProvider is the known staking provider with the largest delegation
+	StakingProvider string
+}
+
+// WalletDelegationJSON represents a WalletDelegation formatted for the API responses
+type WalletDelegationJSON struct {
+	Contract               string
+	StakingProvider        string
+	APR                    float64
+	ActiveStakeInEgld      string
+	ClaimableRewardsInEgld string
+	UnbondingInEgld        string
+}
+
+// WalletHoldingsJSON represents WalletHoldings formatted for the API responses
+type WalletHoldingsJSON struct {
+	Address              string
+	EgldBalance          string
+	MexBalance           string
+	LockedMexBalance     string
+	LPBalance            string
+	LPValueInUsd         string `json:",omitempty"`
+	Delegations          []WalletDelegationJSON
+	PendingRewardsInEgld string
+	EgldTokens           string
+	MexTokens            string
+	StakingProvider      string
+}
+
+// MarshallToJSON formats the holdings for the API responses
+func (h *WalletHoldings) MarshallToJSON() WalletHoldingsJSON {
+	result := WalletHoldingsJSON{
+		Address:              h.Address,
+		EgldBalance:          h.EgldBalance.Text('f', FloatingPointAccuracy),
+		MexBalance:           h.MexBalance.Text('f', FloatingPointAccuracy),
+		LockedMexBalance:     h.LockedMexBalance.Text('f', FloatingPointAccuracy),
+		LPBalance:            h.LPBalance.Text('f', FloatingPointAccuracy),
+		Delegations:          make([]WalletDelegationJSON, 0, len(h.Delegations)),
+		PendingRewardsInEgld: h.PendingRewardsInEgld.Text('f', FloatingPointAccuracy),
+		EgldTokens:           h.EgldTokens.Text('f', FloatingPointAccuracy),
+		MexTokens:            h.MexTokens.Text('f', FloatingPointAccuracy),
+		StakingProvider:      h.StakingProvider,
+	}
+	if h.LPValueInUsd != nil {
+		result.LPValueInUsd = h.LPValueInUsd.Text('f', FloatingPointAccuracy)
+	}
+
+	for _, delegation := range h.Delegations {
+		result.Delegations = append(result.Delegations, WalletDelegationJSON{
+			Contract:               delegation.Contract,
+			StakingProvider:        delegation.StakingProvider,
+			APR:                    delegation.APR,
+			ActiveStakeInEgld:      delegation.ActiveStake.Text('f', FloatingPointAccuracy),
+			ClaimableRewardsInEgld: delegation.ClaimableRewards.Text('f', FloatingPointAccuracy),
+			UnbondingInEgld:        delegation.Unbonding.Text('f', FloatingPointAccuracy),
+		})
+	}
+
+	return result
+}
+
+// GetWalletHoldings fetches the balances and the delegations of the wallet and sums them up as the amounts of EGLD and
+// MEX to be invested; the EGLD-MEX LP tokens are split at the current prices, as the pool holds the same value of
+// both tokens
+func (s *Service) GetWalletHoldings(address string, egldStakingProviders []fetcher.EgldStakingProvider, economics Economics) (*WalletHoldings, error) {
+	if s.WalletFetcher == nil {
+		return nil, ErrWalletUnavailable
+	}
+	if !fetcher.IsValidAddress(address) {
+		return nil, fmt.Errorf("%w: '%s' is not an erd1 address", ErrInvalidAddress, address)
+	}
+
+	wallet, err := s.WalletFetcher.FetchWallet(address)
+	if err != nil {
+		log.Error("error fetching the wallet %s: %s", address, err)
+		return nil, err
+	}
+
+	egldPrice, _, err := big.ParseFloat(economics.Prices.EGLD, 10, 0, big.ToNearestEven)
+	if err != nil {
+		log.Error("error converting EGLD price string to float: %s", err)
+		return nil, err
+	}
+	mexPrice := economics.mexEconomics.Price
+
+	lpIdentifierPrefix := egldMexLPIdentifierPrefix
+	if pool, ok := egldMexPool(economics); ok {
+		lpIdentifierPrefix = pool.LPTokenIdentifier
+	}
+
+	holdings := &WalletHoldings{
+		Address:              address,
+		EgldBalance:          wallet.EgldBalance,
+		MexBalance:           &big.Float{},
+		LockedMexBalance:     &big.Float{},
+		LPBalance:            &big.Float{},
+		PendingRewardsInEgld: &big.Float{},
+		EgldTokens:           new(big.Float).Set(wallet.EgldBalance),
+		MexTokens:            &big.Float{},
+	}
+
+	for _, token := range wallet.Tokens {
+		switch {
+		case strings.HasPrefix(token.Identifier, mexIdentifierPrefix):
+			holdings.MexBalance.Add(holdings.MexBalance, token.Balance)
+		case hasAnyPrefix(token.Identifier, lockedMexCollectionPrefixes):
+			holdings.LockedMexBalance.Add(holdings.LockedMexBalance, token.Balance)
+		case strings.HasPrefix(token.Identifier, lpIdentifierPrefix):
+			holdings.LPBalance.Add(holdings.LPBalance, token.Balance)
+			if token.ValueInUsd != nil {
+				if holdings.LPValueInUsd == nil {
+					holdings.LPValueInUsd = &big.Float{}
+				}
+				holdings.LPValueInUsd.Add(holdings.LPValueInUsd, token.ValueInUsd)
+			}
+		}
+	}
+	holdings.MexTokens.Add(holdings.MexTokens, holdings.MexBalance)
+
+	if holdings.LPValueInUsd != nil && egldPrice.Sign() > 0 && mexPrice != nil && mexPrice.Sign() > 0 {
+		halfValue := new(big.Float).Quo(holdings.LPValueInUsd, big.NewFloat(2))
+		holdings.EgldTokens.Add(holdings.EgldTokens, new(big.Float).Quo(halfValue, egldPrice))
+		holdings.MexTokens.Add(holdings.MexTokens, new(big.Float).Quo(halfValue, mexPrice))
+	}
+
+	providersByAddress := make(map[string]fetcher.EgldStakingProvider, len(egldStakingProviders))
+	for _, provider := range egldStakingProviders {
+		providersByAddress[provider.Address] = provider
+	}
+
+	var largestDelegation *big.Float
+	for _, delegation := range wallet.Delegations {
+		provider := providersByAddress[delegation.Contract]
+		holdings.Delegations = append(holdings.Delegations, WalletDelegation{
+			Contract:         delegation.Contract,
+			StakingProvider:  provider.Identity,
+			APR:              provider.APR,
+			ActiveStake:      delegation.ActiveStake,
+			ClaimableRewards: delegation.ClaimableRewards,
+			Unbonding:        delegation.Unbonding,
+		})
+
+		holdings.PendingRewardsInEgld.Add(holdings.PendingRewardsInEgld, delegation.ClaimableRewards)
+		holdings.EgldTokens.Add(holdings.EgldTokens, delegation.ActiveStake)
+		holdings.EgldTokens.Add(holdings.EgldTokens, delegation.ClaimableRewards)
+		holdings.EgldTokens.Add(holdings.EgldTokens, delegation.Unbonding)
+
+		if provider.Identity != "" && (largestDelegation == nil || delegation.ActiveStake.Cmp(largestDelegation) > 0) {
+			largestDelegation = delegation.ActiveStake
+			holdings.StakingProvider = provider.Identity
+		}
+	}
+
+	return holdings, nil
+}
+
+// hasAnyPrefix returns true if value starts with any of the prefixes
+func hasAnyPrefix(value string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(value, prefix) {
+			return true
+		}
+	}
+	return false
+}
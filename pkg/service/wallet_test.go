@@ -0,0 +1,78 @@
+package service
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockWalletFetcher struct {
+	wallet fetcher.Wallet
+}
+
+func (m *mockWalletFetcher) FetchWallet(address string) (fetcher.Wallet, error) {
+	wallet := m.wallet
+	wallet.Address = address
+	return wallet, nil
+}
+
+func TestService_GetWalletHoldings(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// test setup
+	const address = "erd1qyu5wthldzr8wx5c9ucg8kjagg0jfs53s8nr3zpz3hypefsdd8ssycr6th"
+	service := Service{
+		WalletFetcher: &mockWalletFetcher{wallet: fetcher.Wallet{
+			EgldBalance: big.NewFloat(1.5),
+			Tokens: []fetcher.WalletToken{
+				{Identifier: "MEX-455c57", Balance: big.NewFloat(1000000)},
+				{Identifier: "LKMEX-aab910", Balance: big.NewFloat(500000)},
+				{Identifier: "EGLDMEX-0be9e5", Balance: big.NewFloat(3), ValueInUsd: big.NewFloat(500)},
+				{Identifier: "USDC-c76f1f", Balance: big.NewFloat(100), ValueInUsd: big.NewFloat(100)},
+			},
+			Delegations: []fetcher.Delegation{
+				{Contract: "erd1istari", ActiveStake: big.NewFloat(10), ClaimableRewards: big.NewFloat(0.25), Unbonding: &big.Float{}},
+				{Contract: "erd1other", ActiveStake: big.NewFloat(20), ClaimableRewards: big.NewFloat(0.5), Unbonding: big.NewFloat(1)},
+			},
+		}},
+	}
+	economics := Economics{
+		Prices:       Prices{EGLD: "250"},
+		mexEconomics: fetcher.MexEconomics{Price: big.NewFloat(0.0002)},
+	}
+	providers := []fetcher.EgldStakingProvider{{APR: 12, Identity: "istari", Address: "erd1istari"}}
+
+	t.Run("holdings", func(t *testing.T) {
+		holdings, err := service.GetWalletHoldings(address, providers, economics)
+		require.NoError(t, err)
+
+		assert.Equal(t, "500000", holdings.LockedMexBalance.Text('f', 0))
+		assert.Equal(t, "0.75", holdings.PendingRewardsInEgld.Text('f', 2))
+
+		// 1.5 EGLD, 30 EGLD delegated, 0.75 EGLD of rewards, 1 EGLD unbonding and 250 USD of LP tokens
+		assert.Equal(t, "34.25", holdings.EgldTokens.Text('f', 2))
+		// 1000000 MEX and 250 USD of LP tokens
+		assert.Equal(t, "2250000", holdings.MexTokens.Text('f', 0))
+
+		// the largest delegation is to an unknown contract
+		assert.Equal(t, "istari", holdings.StakingProvider)
+		require.Len(t, holdings.Delegations, 2)
+		assert.Equal(t, 12.0, holdings.Delegations[0].APR)
+		assert.Equal(t, "", holdings.Delegations[1].StakingProvider)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		_, err := service.GetWalletHoldings("erd1invalid", providers, economics)
+		assert.True(t, errors.Is(err, ErrInvalidAddress), "expected ErrInvalidAddress, got %v", err)
+	})
+
+	t.Run("no wallet fetcher", func(t *testing.T) {
+		_, err := (&Service{}).GetWalletHoldings(address, providers, economics)
+		assert.True(t, errors.Is(err, ErrWalletUnavailable), "expected ErrWalletUnavailable, got %v", err)
+	})
+}
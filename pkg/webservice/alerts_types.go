@@ -0,0 +1,35 @@
+package webservice
+
+import (
+	"fmt"
+
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// AlertRuleRequestPayload is the payload registering an alert rule
+type AlertRuleRequestPayload struct {
+	// Kind is "egld-price-below", "egld-price-above", "provider-apr-below" or "mex-locked-apr-change"
+	Kind string `json:"kind"`
+	// Threshold is a USD price, an APR percentage or a change of the APR in percentage points, depending on Kind
+	Threshold       string `json:"threshold"`
+	StakingProvider string `json:"egld-staking-provider"`
+	WebhookURL      string `json:"webhook-url"`
+	// Secret is the optional secret signing the webhooks; a random one is generated if it is empty
+	Secret string `json:"secret"`
+}
+
+// ToAlertRule returns the parsed alert rule
+func (payload *AlertRuleRequestPayload) ToAlertRule() (*service.AlertRule, error) {
+	threshold, err := parseBigFloat(payload.Threshold)
+	if err != nil {
+		return nil, fmt.Errorf("failed parsing field 'Threshold': %w", err)
+	}
+
+	return &service.AlertRule{
+		Kind:            service.AlertKind(payload.Kind),
+		Threshold:       threshold,
+		StakingProvider: payload.StakingProvider,
+		WebhookURL:      payload.WebhookURL,
+		Secret:          payload.Secret,
+	}, nil
+}
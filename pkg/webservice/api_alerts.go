@@ -0,0 +1,126 @@
+package webservice
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// alertSecretHeader is the header carrying the secret of the alert rule, required to read and delete it
+const alertSecretHeader = "X-Alert-Secret"
+
+// HandlePostAlert registers an alert rule; the response contains the secret signing the webhooks, which is required
+// to read the delivery log and to delete the rule
+func (api *API) HandlePostAlert(c *gin.Context) {
+	var requestPayload AlertRuleRequestPayload
+
+	err := c.BindJSON(&requestPayload)
+	if err != nil {
+		log.Error("error binding the request payload: %s", err)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	rule, err := requestPayload.ToAlertRule()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"errors": []string{err.Error()},
+		})
+		return
+	}
+
+	if err := api.service.CreateAlertRule(rule); err != nil {
+		if errors.Is(err, service.ErrInvalidAlertRule) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+		if errors.Is(err, service.ErrAlertRulesLimit) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"alert": rule,
+	})
+}
+
+// HandleGetAlert returns an alert rule, without its secret, and its delivery log
+func (api *API) HandleGetAlert(c *gin.Context) {
+	rule, ok := api.authorizedAlertRule(c)
+	if !ok {
+		return
+	}
+
+	deliveries, err := api.service.GetAlertDeliveries(rule.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	rule.Secret = ""
+	c.JSON(http.StatusOK, gin.H{
+		"alert":      rule,
+		"deliveries": deliveries,
+	})
+}
+
+// HandleDeleteAlert deletes an alert rule and its delivery log
+func (api *API) HandleDeleteAlert(c *gin.Context) {
+	rule, ok := api.authorizedAlertRule(c)
+	if !ok {
+		return
+	}
+
+	if err := api.service.DeleteAlertRule(rule.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// authorizedAlertRule returns the alert rule of the request if the secret header matches it, or writes the error
+// response and returns false
+func (api *API) authorizedAlertRule(c *gin.Context) (*service.AlertRule, bool) {
+	rule, err := api.service.GetAlertRule(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrAlertRuleNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"message": err.Error(),
+			})
+			return nil, false
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return nil, false
+	}
+
+	if subtle.ConstantTimeCompare([]byte(c.GetHeader(alertSecretHeader)), []byte(rule.Secret)) != 1 {
+		c.JSON(http.StatusForbidden, gin.H{
+			"message": "invalid alert secret",
+		})
+		return nil, false
+	}
+
+	return rule, true
+}
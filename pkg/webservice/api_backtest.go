@@ -0,0 +1,63 @@
+package webservice
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// HandlePostBacktest returns the results the strategies would have had for an investment started in the past, using
+// the recorded prices and APRs
+func (api *API) HandlePostBacktest(c *gin.Context) {
+	var requestPayload BacktestRequestPayload
+
+	err := c.BindJSON(&requestPayload)
+	if err != nil {
+		log.Error("error binding the request payload: %s", err)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	strategiesInput, errs := requestPayload.ToBacktestInput()
+	if errs != nil {
+		writeValidationErrors(c, errs)
+		return
+	}
+
+	history, err := api.service.GetHistory(service.BacktestPeriod(strategiesInput))
+	if err != nil {
+		log.Error("error retrieving the history: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	results, err := api.service.Backtest(strategiesInput, history)
+	if err != nil {
+		if errors.Is(err, service.ErrInsufficientHistory) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+		if errors.Is(err, service.ErrUnknownStrategy) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results": results,
+	})
+}
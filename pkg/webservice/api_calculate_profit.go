@@ -1,10 +1,13 @@
 package webservice
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
 	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/service"
 )
 
 func (api *API) HandlePostCalculateProfit(c *gin.Context) {
@@ -17,15 +20,10 @@ func (api *API) HandlePostCalculateProfit(c *gin.Context) {
 		return
 	}
 
-	strategiesInput, errs := requestPayload.ToStrategiesInput()
-	if errs != nil {
-		errsStrings := make([]string, len(errs))
-		for i, err := range errs {
-			errsStrings[i] = err.Error()
-		}
-
+	format, ok := exportFormat(c)
+	if !ok {
 		c.JSON(http.StatusBadRequest, gin.H{
-			"errors": errsStrings,
+			"errors": []string{"failed validating query parameter 'format': the value must be json, csv, xlsx or pdf"},
 		})
 		return
 	}
@@ -45,18 +43,89 @@ func (api *API) HandlePostCalculateProfit(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err,
 		})
+		return
 	}
 
-	results, err := api.service.CalculateStrategies(strategiesInput, egldStakingProviders, economics)
+	response, ok := api.calculateProfit(c, &requestPayload, egldStakingProviders, economics)
+	if !ok {
+		return
+	}
+
+	if format != "" {
+		api.writeExport(c, format, &requestPayload, response["results"].(map[string]service.StrategyResultJSON), egldStakingProviders, economics)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// calculateProfit returns the response of the profit calculation of the payload against the given market data, or
+// writes the error response and returns false
+func (api *API) calculateProfit(c *gin.Context, requestPayload *CalculateStrategiesRequestPayload, egldStakingProviders []fetcher.EgldStakingProvider, economics service.Economics) (gin.H, bool) {
+	calculation, errs, err := api.evaluateProfit(requestPayload, egldStakingProviders, economics)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err,
 		})
-		return
+		return nil, false
+	}
+	if errs != nil {
+		writeValidationErrors(c, errs)
+		return nil, false
+	}
+
+	response := gin.H{
+		"results": calculation.Results,
+		"prices":  calculation.Prices,
+	}
+	if calculation.Swap != nil {
+		response["swap"] = calculation.Swap
+	}
+	if calculation.Unavailable != nil {
+		response["unavailable"] = calculation.Unavailable
+	}
+
+	return response, true
+}
+
+// evaluateProfit calculates the strategies of the payload against the given market data; it returns the errors of
+// the invalid payload, or the error of the calculation
+func (api *API) evaluateProfit(requestPayload *CalculateStrategiesRequestPayload, egldStakingProviders []fetcher.EgldStakingProvider, economics service.Economics) (*calculateProfitResponse, []error, error) {
+	strategiesInput, errs := requestPayload.ToStrategiesInput()
+	if errs != nil {
+		return nil, errs, nil
+	}
+	if err := validateStakingProvider("egld-staking-provider", strategiesInput.StakingProvider, egldStakingProviders); err != nil {
+		return nil, []error{err}, nil
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"results": results,
-		"prices":  economics.Prices,
-	})
-}
\ No newline at end of file
+	results, err := api.service.CalculateStrategies(strategiesInput, egldStakingProviders, economics)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownStrategy) {
+			return nil, []error{err}, nil
+		}
+
+		return nil, nil, err
+	}
+
+	calculation := &calculateProfitResponse{
+		Results:     results,
+		Prices:      economics.Prices,
+		Unavailable: api.unavailableStrategies(strategiesInput.Strategies, economics),
+	}
+	if strategiesInput.Swap != nil {
+		swap := strategiesInput.Swap.MarshallToJSON()
+		calculation.Swap = &swap
+	}
+
+	return calculation, nil, nil
+}
+
+// unavailableStrategies returns the requested strategies which are skipped because their market data is not available
+func (api *API) unavailableStrategies(names []string, economics service.Economics) []string {
+	strategies, err := api.service.Registry().Select(names)
+	if err != nil {
+		return nil
+	}
+	return service.UnavailableStrategies(strategies, economics)
+}
@@ -0,0 +1,158 @@
+package webservice
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// mimeNDJSON is the content type of the streamed responses, with a JSON document per line
+const mimeNDJSON = "application/x-ndjson"
+
+// HandlePostCalculateProfitBatch calculates the profit of several payloads against the same market data, and streams
+// a line of NDJSON for each of them, in the order of the payloads; the size of the batch is limited by the tier of
+// the client
+func (api *API) HandlePostCalculateProfitBatch(c *gin.Context) {
+	var requestPayloads []CalculateStrategiesRequestPayload
+
+	err := c.BindJSON(&requestPayloads)
+	if err != nil {
+		log.Error("error binding the request payload: %s", err)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	tier := clientTier(c)
+	if len(requestPayloads) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"errors": []string{"the batch must contain at least one payload"},
+		})
+		return
+	}
+	if len(requestPayloads) > tier.BatchMaxItems {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{
+			"errors": []string{fmt.Sprintf("the batch must contain at most %d payloads", tier.BatchMaxItems)},
+		})
+		return
+	}
+
+	// all the payloads are calculated against the same snapshot of the market data
+	egldStakingProviders, err := api.service.GetStakingProviders()
+	if err != nil {
+		log.Error("error retrieving the EGLD staking providers: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	economics, err := api.service.GetEconomics()
+	if err != nil {
+		log.Error("error retrieving the economics: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.Header("Content-Type", mimeNDJSON)
+	c.Status(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Writer)
+	calculate := func(index int) CalculateProfitBatchItem {
+		return api.calculateBatchItem(index, &requestPayloads[index], egldStakingProviders, economics)
+	}
+	write := func(item CalculateProfitBatchItem) error {
+		if err := encoder.Encode(item); err != nil {
+			return err
+		}
+		c.Writer.Flush()
+		return nil
+	}
+
+	if err := runBatch(c.Request.Context(), len(requestPayloads), tier.BatchConcurrency, calculate, write); err != nil {
+		log.Error("error streaming the batch calculation: %s", err)
+	}
+}
+
+// calculateBatchItem returns the line of the response of the payload at the index of the batch
+func (api *API) calculateBatchItem(index int, requestPayload *CalculateStrategiesRequestPayload, egldStakingProviders []fetcher.EgldStakingProvider, economics service.Economics) CalculateProfitBatchItem {
+	item := CalculateProfitBatchItem{
+		Index:  index,
+		Prices: economics.Prices,
+	}
+
+	calculation, errs, err := api.evaluateProfit(requestPayload, egldStakingProviders, economics)
+	switch {
+	case err != nil:
+		log.Error("error calculating the payload %d of the batch: %s", index, err)
+		item.Error = "error calculating the strategies"
+	case errs != nil:
+		for _, err := range errs {
+			item.Errors = append(item.Errors, err.Error())
+		}
+		item.FieldErrors = fieldErrors(errs)
+	default:
+		item.Results = calculation.Results
+		item.Swap = calculation.Swap
+		item.Unavailable = calculation.Unavailable
+	}
+
+	return item
+}
+
+// runBatch calculates the items 0 to count-1 with 'concurrency' workers, and writes them in order as soon as they
+// are available; it stops when the context is done or an item can't be written
+func runBatch(ctx context.Context, count, concurrency int, calculate func(index int) CalculateProfitBatchItem, write func(item CalculateProfitBatchItem) error) error {
+	if concurrency > count {
+		concurrency = count
+	}
+
+	// the channels are buffered, so that the workers never wait for the items to be written
+	items := make([]chan CalculateProfitBatchItem, count)
+	for i := range items {
+		items[i] = make(chan CalculateProfitBatchItem, 1)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	indexes := make(chan int)
+	go func() {
+		defer close(indexes)
+		for i := 0; i < count; i++ {
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for i := 0; i < concurrency; i++ {
+		go func() {
+			for index := range indexes {
+				items[index] <- calculate(index)
+			}
+		}()
+	}
+
+	for _, item := range items {
+		select {
+		case result := <-item:
+			if err := write(result); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package webservice
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBatch(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	t.Run("writes the items in order", func(t *testing.T) {
+		const count = 20
+		var running, maxRunning, othersCalculated int32
+		// the first item is only calculated once all the others are, so it is calculated last
+		othersDone := make(chan struct{})
+		calculate := func(index int) CalculateProfitBatchItem {
+			current := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				max := atomic.LoadInt32(&maxRunning)
+				if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+					break
+				}
+			}
+
+			if index == 0 {
+				<-othersDone
+			} else if atomic.AddInt32(&othersCalculated, 1) == count-1 {
+				close(othersDone)
+			}
+			return CalculateProfitBatchItem{Index: index}
+		}
+
+		var written []int
+		write := func(item CalculateProfitBatchItem) error {
+			written = append(written, item.Index)
+			return nil
+		}
+
+		require.NoError(t, runBatch(context.Background(), count, 4, calculate, write))
+		assert.Equal(t, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19}, written)
+		assert.LessOrEqual(t, atomic.LoadInt32(&maxRunning), int32(4))
+	})
+
+	t.Run("stops when an item can't be written", func(t *testing.T) {
+		var calculated int32
+		// the items after the first one are calculated once the batch stopped, so only the items already taken by
+		// the workers are calculated
+		release := make(chan struct{})
+		calculate := func(index int) CalculateProfitBatchItem {
+			atomic.AddInt32(&calculated, 1)
+			if index != 0 {
+				<-release
+			}
+			return CalculateProfitBatchItem{Index: index}
+		}
+		errWrite := errors.New("connection closed")
+		write := func(item CalculateProfitBatchItem) error {
+			return errWrite
+		}
+
+		assert.ErrorIs(t, runBatch(context.Background(), 100, 2, calculate, write), errWrite)
+		// the first item and at most one item per worker
+		assert.LessOrEqual(t, atomic.LoadInt32(&calculated), int32(3))
+		close(release)
+	})
+
+	t.Run("stops when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// the first item cancels the context and is only calculated once the batch stopped
+		release := make(chan struct{})
+		calculate := func(index int) CalculateProfitBatchItem {
+			if index == 0 {
+				cancel()
+			}
+			<-release
+			return CalculateProfitBatchItem{Index: index}
+		}
+		write := func(item CalculateProfitBatchItem) error {
+			return nil
+		}
+
+		assert.ErrorIs(t, runBatch(ctx, 100, 2, calculate, write), context.Canceled)
+		close(release)
+	})
+}
+
+func TestAPI_HandlePostCalculateProfitBatch(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	api := NewAPI(&service.Service{})
+	basic := service.APITiers["basic"]
+
+	for _, tt := range []struct {
+		name   string
+		body   string
+		status int
+	}{
+		{"invalid JSON", "{", http.StatusBadRequest},
+		{"empty batch", "[]", http.StatusBadRequest},
+		{"too many payloads for the tier", "[" + strings.Repeat("{},", basic.BatchMaxItems) + "{}]", http.StatusRequestEntityTooLarge},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(recorder)
+			c.Request = httptest.NewRequest(http.MethodPost, "/api/calculate_profit/batch", strings.NewReader(tt.body))
+			c.Set(tierContextKey, basic)
+			api.HandlePostCalculateProfitBatch(c)
+
+			assert.Equal(t, tt.status, recorder.Code)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package webservice
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// HandlePostCompare calculates the strategies for several named configurations against the same market data and
+// returns them ranked, with the deltas against a baseline configuration
+func (api *API) HandlePostCompare(c *gin.Context) {
+	var requestPayload CompareRequestPayload
+
+	err := c.BindJSON(&requestPayload)
+	if err != nil {
+		log.Error("error binding the request payload: %s", err)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	variants, errs := requestPayload.ToCompareVariants()
+	if errs != nil {
+		writeValidationErrors(c, errs)
+		return
+	}
+
+	egldStakingProviders, err := api.service.GetStakingProviders()
+	if err != nil {
+		log.Error("error retrieving the EGLD staking providers: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+	var providerErrs []error
+	for i, variant := range variants {
+		if err := validateStakingProvider(variantField(i, "egld-staking-provider"), variant.Input.StakingProvider, egldStakingProviders); err != nil {
+			providerErrs = append(providerErrs, fmt.Errorf("variant '%s': %w", variant.Name, err))
+		}
+	}
+	if providerErrs != nil {
+		writeValidationErrors(c, providerErrs)
+		return
+	}
+
+	economics, err := api.service.GetEconomics()
+	if err != nil {
+		log.Error("error retrieving the economics: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	comparison, err := api.service.Compare(variants, requestPayload.Baseline, egldStakingProviders, economics)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownStrategy) || errors.Is(err, service.ErrInvalidComparison) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"comparison": comparison,
+		"prices":     economics.Prices,
+	})
+}
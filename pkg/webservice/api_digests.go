@@ -0,0 +1,106 @@
+package webservice
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// DigestSubscriptionRequestPayload is the payload subscribing an email address to the weekly digest of a scenario
+type DigestSubscriptionRequestPayload struct {
+	Email      string `json:"email"`
+	ScenarioID string `json:"scenario-id"`
+}
+
+// HandlePostDigest subscribes an email address to the weekly digest of a saved scenario; the digests are only sent
+// once the subscription is confirmed from the link emailed to the address
+func (api *API) HandlePostDigest(c *gin.Context) {
+	var requestPayload DigestSubscriptionRequestPayload
+
+	err := c.BindJSON(&requestPayload)
+	if err != nil {
+		log.Error("error binding the request payload: %s", err)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	subscription, err := api.service.SubscribeDigest(requestPayload.Email, requestPayload.ScenarioID)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDigestSubscription) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+		if errors.Is(err, service.ErrDigestSubscriptionsLimit) {
+			c.JSON(http.StatusConflict, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrDigestUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	// the token is only sent by email, so only the owner of the address can confirm the subscription
+	subscription.Token = ""
+	c.JSON(http.StatusAccepted, gin.H{
+		"subscription": subscription,
+	})
+}
+
+// HandleGetDigestConfirm confirms a digest subscription; it is a GET request so it can be linked from the emails
+func (api *API) HandleGetDigestConfirm(c *gin.Context) {
+	err := api.service.ConfirmDigest(c.Param("id"), c.Query("token"))
+	if err != nil {
+		if errors.Is(err, service.ErrDigestSubscriptionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "subscribed to the weekly digest",
+	})
+}
+
+// HandleGetDigestUnsubscribe deletes a digest subscription; it is a GET request so it can be linked from the emails
+func (api *API) HandleGetDigestUnsubscribe(c *gin.Context) {
+	err := api.service.UnsubscribeDigest(c.Param("id"), c.Query("token"))
+	if err != nil {
+		if errors.Is(err, service.ErrDigestSubscriptionNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "unsubscribed from the weekly digest",
+	})
+}
@@ -0,0 +1,42 @@
+package webservice
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+type strategyDescription struct {
+	Name            string                       `json:"name"`
+	SupportedTokens []string                     `json:"supported_tokens"`
+	InputSchema     []service.StrategyInputField `json:"input_schema"`
+}
+
+// HandleGetStrategies returns a JSON containing the strategies which can be requested when calculating the profit
+func (api *API) HandleGetStrategies(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"strategies": api.strategyDescriptions(),
+	})
+}
+
+// strategyDescriptions returns the description of the registered strategies
+func (api *API) strategyDescriptions() []strategyDescription {
+	strategies := api.service.Registry().Strategies()
+
+	descriptions := make([]strategyDescription, 0, len(strategies))
+	for _, strategy := range strategies {
+		tokens := make([]string, 0, len(strategy.SupportedTokens()))
+		for _, tokenType := range strategy.SupportedTokens() {
+			tokens = append(tokens, tokenType.String())
+		}
+
+		descriptions = append(descriptions, strategyDescription{
+			Name:            strategy.Name(),
+			SupportedTokens: tokens,
+			InputSchema:     strategy.InputSchema(),
+		})
+	}
+
+	return descriptions
+}
@@ -0,0 +1,55 @@
+package webservice
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// mimeEventStream is the content type of the Server-Sent Events
+	mimeEventStream = "text/event-stream"
+
+	// marketEvent is the name of the Server-Sent Events carrying a service.MarketUpdate
+	marketEvent = "market"
+
+	// liveKeepAliveInterval is the interval of the comments keeping the idle connections open through the proxies
+	liveKeepAliveInterval = 30 * time.Second
+)
+
+// HandleGetLive streams the market data as Server-Sent Events: the current data when the client connects, then an
+// update after each refresh of the cache
+func (api *API) HandleGetLive(c *gin.Context) {
+	updates, unsubscribe := api.live.Subscribe()
+	defer unsubscribe()
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", mimeEventStream)
+	c.Header("Cache-Control", "no-cache")
+	// disable the buffering of the response by nginx
+	c.Header("X-Accel-Buffering", "no")
+
+	// the market data is not available until the cache is refreshed for the first time, then it is sent as an update
+	if update, err := api.service.GetMarketUpdate(); err == nil {
+		c.SSEvent(marketEvent, update)
+	}
+	c.Writer.Flush()
+
+	keepAlive := time.NewTicker(liveKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case update := <-updates:
+			c.SSEvent(marketEvent, update)
+			return true
+		case <-keepAlive.C:
+			_, err := io.WriteString(w, ": keep-alive\n\n")
+			return err == nil
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
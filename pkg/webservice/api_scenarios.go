@@ -0,0 +1,205 @@
+package webservice
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// scenarioTokenHeader is the header carrying the token required to delete a scenario
+const scenarioTokenHeader = "X-Scenario-Token"
+
+// HandlePostScenario calculates the profit of the payload like HandlePostCalculateProfit and saves the payload, the
+// market data and the response as a scenario; the optional query parameter 'expires-in-days' sets how long it is kept
+func (api *API) HandlePostScenario(c *gin.Context) {
+	payload, err := c.GetRawData()
+	if err != nil {
+		log.Error("error reading the request payload: %s", err)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	var requestPayload CalculateStrategiesRequestPayload
+	if err := json.Unmarshal(payload, &requestPayload); err != nil {
+		log.Error("error binding the request payload: %s", err)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	ttl := service.DefaultScenarioTTL
+	if expiresInDays := c.Query("expires-in-days"); expiresInDays != "" {
+		days, err := strconv.Atoi(expiresInDays)
+		if err != nil || days < 1 || time.Duration(days)*24*time.Hour > service.MaxScenarioTTL {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{"failed validating query parameter 'expires-in-days': the value must be between 1 and 365"},
+			})
+			return
+		}
+		ttl = time.Duration(days) * 24 * time.Hour
+	}
+
+	egldStakingProviders, err := api.service.GetStakingProviders()
+	if err != nil {
+		log.Error("error retrieving the EGLD staking providers: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	economics, err := api.service.GetEconomics()
+	if err != nil {
+		log.Error("error retrieving the economics: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	response, ok := api.calculateProfit(c, &requestPayload, egldStakingProviders, economics)
+	if !ok {
+		return
+	}
+
+	responseData, err := json.Marshal(response)
+	if err != nil {
+		log.Error("error marshalling the scenario response to JSON: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	// the payload is parsed again, as the calculation completes the input with the market data
+	strategiesInput, _ := requestPayload.ToStrategiesInput()
+
+	scenario := &service.Scenario{
+		Payload:  payload,
+		Input:    strategiesInput,
+		Snapshot: service.NewMarketSnapshot(egldStakingProviders, economics),
+		Response: responseData,
+	}
+	token, err := api.service.SaveScenario(scenario, ttl)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	response["scenario"] = scenarioMetadata(scenario)
+	response["token"] = token
+	c.JSON(http.StatusCreated, response)
+}
+
+// HandleGetScenario returns the saved response of a scenario, or re-runs its payload against the current market data
+// if the query parameter 'rerun' is true
+func (api *API) HandleGetScenario(c *gin.Context) {
+	scenario, err := api.service.GetScenario(c.Param("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrScenarioNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	if c.Query("rerun") != "true" {
+		var response gin.H
+		if err := json.Unmarshal(scenario.Response, &response); err != nil {
+			log.Error("error unmarshalling the response of the scenario %s: %s", scenario.ID, err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": err,
+			})
+			return
+		}
+
+		response["scenario"] = scenarioMetadata(scenario)
+		c.JSON(http.StatusOK, response)
+		return
+	}
+
+	var requestPayload CalculateStrategiesRequestPayload
+	if err := json.Unmarshal(scenario.Payload, &requestPayload); err != nil {
+		log.Error("error unmarshalling the payload of the scenario %s: %s", scenario.ID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	egldStakingProviders, err := api.service.GetStakingProviders()
+	if err != nil {
+		log.Error("error retrieving the EGLD staking providers: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	economics, err := api.service.GetEconomics()
+	if err != nil {
+		log.Error("error retrieving the economics: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	response, ok := api.calculateProfit(c, &requestPayload, egldStakingProviders, economics)
+	if !ok {
+		return
+	}
+
+	response["scenario"] = scenarioMetadata(scenario)
+	response["saved_prices"] = scenario.Snapshot.Prices
+	c.JSON(http.StatusOK, response)
+}
+
+// HandleDeleteScenario deletes a scenario, given the token returned when it was saved in the X-Scenario-Token header
+func (api *API) HandleDeleteScenario(c *gin.Context) {
+	err := api.service.DeleteScenario(c.Param("id"), c.GetHeader(scenarioTokenHeader))
+	if err != nil {
+		if errors.Is(err, service.ErrScenarioNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidScenarioToken) {
+			c.JSON(http.StatusForbidden, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// scenarioMetadata returns the ID, the dates and the payload of the scenario for the API responses
+func scenarioMetadata(scenario *service.Scenario) gin.H {
+	return gin.H{
+		"id":         scenario.ID,
+		"created_at": scenario.CreatedAt,
+		"expires_at": scenario.ExpiresAt,
+		"input":      scenario.Payload,
+	}
+}
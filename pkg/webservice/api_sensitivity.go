@@ -0,0 +1,70 @@
+package webservice
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// HandlePostSensitivity returns the TotalBalanceInUsd and ROI of the strategies for a grid of values of two inputs
+func (api *API) HandlePostSensitivity(c *gin.Context) {
+	var requestPayload SensitivityRequestPayload
+
+	err := c.BindJSON(&requestPayload)
+	if err != nil {
+		log.Error("error binding the request payload: %s", err)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	strategiesInput, sensitivityInput, errs := requestPayload.ToSensitivityInput()
+	if errs != nil {
+		writeValidationErrors(c, errs)
+		return
+	}
+
+	egldStakingProviders, err := api.service.GetStakingProviders()
+	if err != nil {
+		log.Error("error retrieving the EGLD staking providers: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+	if err := validateStakingProvider("egld-staking-provider", strategiesInput.StakingProvider, egldStakingProviders); err != nil {
+		writeValidationErrors(c, []error{err})
+		return
+	}
+
+	economics, err := api.service.GetEconomics()
+	if err != nil {
+		log.Error("error retrieving the economics: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	grid, err := api.service.GetSensitivityGrid(strategiesInput, sensitivityInput, egldStakingProviders, economics)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownStrategy) || errors.Is(err, service.ErrInvalidSensitivityInput) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"sensitivity": grid,
+		"prices":      economics.Prices,
+	})
+}
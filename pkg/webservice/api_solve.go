@@ -0,0 +1,77 @@
+package webservice
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// HandlePostSolve returns the value of the price or of the duration for which the result of a strategy reaches the
+// requested target
+func (api *API) HandlePostSolve(c *gin.Context) {
+	var requestPayload SolveRequestPayload
+
+	err := c.BindJSON(&requestPayload)
+	if err != nil {
+		log.Error("error binding the request payload: %s", err)
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	strategiesInput, solveInput, errs := requestPayload.ToSolveInput()
+	if errs != nil {
+		writeValidationErrors(c, errs)
+		return
+	}
+
+	egldStakingProviders, err := api.service.GetStakingProviders()
+	if err != nil {
+		log.Error("error retrieving the EGLD staking providers: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+	if err := validateStakingProvider("egld-staking-provider", strategiesInput.StakingProvider, egldStakingProviders); err != nil {
+		writeValidationErrors(c, []error{err})
+		return
+	}
+
+	economics, err := api.service.GetEconomics()
+	if err != nil {
+		log.Error("error retrieving the economics: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	solution, err := api.service.Solve(strategiesInput, solveInput, egldStakingProviders, economics)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownStrategy) || errors.Is(err, service.ErrInvalidSolveInput) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+		if errors.Is(err, service.ErrNoSolution) {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"solution": solution.MarshallToJSON(solveInput.Variable),
+		"prices":   economics.Prices,
+	})
+}
@@ -0,0 +1,171 @@
+package webservice
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// writeErrorV2 writes the error envelope of the v2 responses
+func writeErrorV2(c *gin.Context, status int, apiErr ErrorV2) {
+	c.AbortWithStatusJSON(status, ErrorResponseV2{Error: apiErr})
+}
+
+// writeInternalErrorV2 logs the error, which is not exposed, and writes an internal error response
+func writeInternalErrorV2(c *gin.Context, message string, err error) {
+	log.Error("%s: %s", message, err)
+	writeErrorV2(c, http.StatusInternalServerError, ErrorV2{Code: ErrorCodeInternal, Message: message})
+}
+
+// marketDataV2 returns the staking providers and the economics, or writes the error response and returns false
+func (api *API) marketDataV2(c *gin.Context) ([]fetcher.EgldStakingProvider, service.Economics, bool) {
+	egldStakingProviders, err := api.service.GetStakingProviders()
+	if err != nil {
+		writeMarketDataErrorV2(c, "error retrieving the EGLD staking providers", err)
+		return nil, service.Economics{}, false
+	}
+
+	economics, err := api.service.GetEconomics()
+	if err != nil {
+		writeMarketDataErrorV2(c, "error retrieving the economics", err)
+		return nil, service.Economics{}, false
+	}
+
+	return egldStakingProviders, economics, true
+}
+
+// writeMarketDataErrorV2 writes the response to a failure to read the market data, which is unavailable until the
+// cache is refreshed for the first time
+func writeMarketDataErrorV2(c *gin.Context, message string, err error) {
+	if errors.Is(err, redis.Nil) {
+		writeErrorV2(c, http.StatusServiceUnavailable, ErrorV2{
+			Code:    ErrorCodeUnavailable,
+			Message: "the market data is not available yet",
+		})
+		return
+	}
+
+	writeInternalErrorV2(c, message, err)
+}
+
+// HandleGetPricesV2 returns the live USD prices of EGLD and MEX
+func (api *API) HandleGetPricesV2(c *gin.Context) {
+	economics, err := api.service.GetEconomics()
+	if err != nil {
+		writeMarketDataErrorV2(c, "error retrieving the economics", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, PricesResponseV2{Prices: newPricesV2(economics.Prices)})
+}
+
+// HandleGetEgldStakingProvidersV2 returns the EGLD staking providers
+func (api *API) HandleGetEgldStakingProvidersV2(c *gin.Context) {
+	stakingProviders, err := api.service.GetStakingProviders()
+	if err != nil {
+		writeMarketDataErrorV2(c, "error retrieving the EGLD staking providers", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, newStakingProvidersResponseV2(stakingProviders))
+}
+
+// HandleGetStrategiesV2 returns the strategies which can be requested when calculating the profit
+func (api *API) HandleGetStrategiesV2(c *gin.Context) {
+	c.JSON(http.StatusOK, StrategiesResponseV2{Strategies: api.strategyDescriptions()})
+}
+
+// HandleGetWalletV2 returns the holdings of a wallet and the inputs of a profit calculation investing them
+func (api *API) HandleGetWalletV2(c *gin.Context) {
+	egldStakingProviders, economics, ok := api.marketDataV2(c)
+	if !ok {
+		return
+	}
+
+	holdings, err := api.service.GetWalletHoldings(c.Param("address"), egldStakingProviders, economics)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAddress):
+			writeErrorV2(c, http.StatusBadRequest, ErrorV2{Code: ErrorCodeInvalidField, Message: err.Error(), Field: "address"})
+		case errors.Is(err, service.ErrWalletUnavailable):
+			writeErrorV2(c, http.StatusServiceUnavailable, ErrorV2{Code: ErrorCodeUnavailable, Message: err.Error()})
+		default:
+			log.Error("error retrieving the holdings of the wallet: %s", err)
+			writeErrorV2(c, http.StatusBadGateway, ErrorV2{Code: ErrorCodeUpstream, Message: "error retrieving the holdings of the wallet"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, newWalletResponseV2(holdings.MarshallToJSON(), walletInput(holdings)))
+}
+
+// HandlePostCalculateProfitV2 returns the results of the strategies for the payload
+func (api *API) HandlePostCalculateProfitV2(c *gin.Context) {
+	var requestPayload CalculateStrategiesRequestPayload
+	if err := c.ShouldBindJSON(&requestPayload); err != nil {
+		writeErrorV2(c, http.StatusBadRequest, ErrorV2{
+			Code:    ErrorCodeInvalidRequest,
+			Message: "the body is not a valid JSON payload: " + err.Error(),
+		})
+		return
+	}
+
+	egldStakingProviders, economics, ok := api.marketDataV2(c)
+	if !ok {
+		return
+	}
+
+	strategiesInput, errs := requestPayload.ToStrategiesInput()
+	if errs == nil {
+		if err := validateStakingProvider("egld-staking-provider", strategiesInput.StakingProvider, egldStakingProviders); err != nil {
+			errs = []error{err}
+		}
+	}
+	if errs != nil {
+		writeErrorV2(c, http.StatusBadRequest, validationErrorV2(errs))
+		return
+	}
+
+	results, err := api.service.CalculateStrategies(strategiesInput, egldStakingProviders, economics)
+	if err != nil {
+		if errors.Is(err, service.ErrUnknownStrategy) {
+			writeErrorV2(c, http.StatusBadRequest, ErrorV2{Code: ErrorCodeInvalidField, Message: err.Error(), Field: "strategies"})
+			return
+		}
+
+		writeInternalErrorV2(c, "error calculating the strategies", err)
+		return
+	}
+
+	response := newCalculateProfitResponseV2(strategiesInput, results, economics.Prices)
+	response.Unavailable = api.unavailableStrategies(strategiesInput.Strategies, economics)
+	c.JSON(http.StatusOK, response)
+}
+
+// validationErrorV2 returns the error listing the invalid fields of a payload
+func validationErrorV2(errs []error) ErrorV2 {
+	apiErr := ErrorV2{
+		Code:    ErrorCodeInvalidRequest,
+		Message: "the payload has invalid fields",
+		Details: make([]ErrorV2, 0, len(errs)),
+	}
+	for _, err := range errs {
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			apiErr.Details = append(apiErr.Details, ErrorV2{
+				Code:    fieldErr.Code,
+				Message: fieldErr.Message,
+				Field:   fieldErr.Field,
+				Params:  fieldErr.Params,
+			})
+			continue
+		}
+		apiErr.Details = append(apiErr.Details, ErrorV2{Code: ErrorCodeInvalidField, Message: err.Error()})
+	}
+	return apiErr
+}
@@ -0,0 +1,87 @@
+package webservice
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_HandlePostCalculateProfitV2(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	api := NewAPI(&service.Service{})
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodPost, "/api/v2/calculate_profit", strings.NewReader("{"))
+	api.HandlePostCalculateProfitV2(c)
+
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+
+	var response ErrorResponseV2
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, ErrorCodeInvalidRequest, response.Error.Code)
+	assert.NotEmpty(t, response.Error.Message)
+}
+
+func TestNewCalculateProfitResponseV2(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	input := &service.StrategiesInput{
+		Contribution: &service.Contribution{TokenType: service.TokenTypeMex, Amount: big.NewFloat(100)},
+	}
+	results := map[string]service.StrategyResultJSON{
+		"mex_hold": {
+			TotalBalanceInMex: "1000", TotalBalanceInUsd: "50", ROI: "0", TotalContributedInTokens: "200",
+			TotalContributedInUsd: "10", AverageCostBasis: "0.05", NetProfitInUsd: "40", ContributionsCount: 2,
+		},
+		"egld_mex_lp_farm": {
+			TotalBalanceInUsd: "1200", ROI: "20", HoldBalanceInUsd: "1250", ImpermanentLossInUsd: "50",
+			ImpermanentLossPercentage: "4", UnbondingPeriodInDays: 10,
+		},
+		"egld_stake": {
+			TotalBalanceInEgld: "11", ROI: "10", UnbondingPeriodInDays: 10, LiquidAt: "2026-01-11",
+			AssumedAPRCurve: []service.APRPeriodJSON{{StartDate: "2026-01-01", EndDate: "2027-01-01", APR: "8"}},
+		},
+	}
+
+	response := newCalculateProfitResponseV2(input, results, service.Prices{EGLD: "40", MEX: "0.05"})
+
+	require.Len(t, response.Results, 3)
+	assert.Equal(t, Amount{Value: "40", Unit: UnitUsd}, response.Prices.EGLD)
+	assert.Nil(t, response.Swap)
+
+	lp := response.Results[0]
+	assert.Equal(t, "egld_mex_lp", lp.Token)
+	assert.Equal(t, "farm", lp.Strategy)
+	require.NotNil(t, lp.ImpermanentLoss)
+	assert.Equal(t, Amount{Value: "4", Unit: UnitPercent}, lp.ImpermanentLoss.Percentage)
+
+	stake := response.Results[1]
+	assert.Equal(t, "egld", stake.Token)
+	assert.Equal(t, "stake", stake.Strategy)
+	assert.Equal(t, Amount{Value: "11", Unit: UnitEgld}, stake.TotalBalance.EGLD)
+	assert.Equal(t, Amount{Value: "10", Unit: UnitDays}, stake.Unbonding.Period)
+	assert.Equal(t, "2026-01-11", stake.Unbonding.LiquidAt)
+	assert.Nil(t, stake.Unbonding.LostYield)
+	require.Len(t, stake.AssumedAPRCurve, 1)
+	assert.Nil(t, stake.AssumedAPRCurve[0].InflationRate)
+	assert.Nil(t, stake.Contributions)
+
+	hold := response.Results[2]
+	assert.Equal(t, "mex", hold.Token)
+	require.NotNil(t, hold.Contributions)
+	assert.Equal(t, Amount{Value: "200", Unit: UnitMex}, hold.Contributions.TotalTokens)
+	assert.Equal(t, 2, hold.Contributions.Count)
+	assert.Nil(t, hold.LockedRewards)
+}
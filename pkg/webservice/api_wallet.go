@@ -0,0 +1,73 @@
+package webservice
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// HandleGetWallet returns the holdings of a wallet and the inputs of a profit calculation investing them; the target
+// prices and the duration have to be completed by the user
+func (api *API) HandleGetWallet(c *gin.Context) {
+	egldStakingProviders, err := api.service.GetStakingProviders()
+	if err != nil {
+		log.Error("error retrieving the EGLD staking providers: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	economics, err := api.service.GetEconomics()
+	if err != nil {
+		log.Error("error retrieving the economics: %s", err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	holdings, err := api.service.GetWalletHoldings(c.Param("address"), egldStakingProviders, economics)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAddress) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"errors": []string{err.Error()},
+			})
+			return
+		}
+		if errors.Is(err, service.ErrWalletUnavailable) {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"message": err.Error(),
+			})
+			return
+		}
+
+		c.JSON(http.StatusBadGateway, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"wallet": holdings.MarshallToJSON(),
+		"input":  walletInput(holdings),
+	})
+}
+
+// walletInput returns the payload of a profit calculation investing the holdings
+func walletInput(holdings *service.WalletHoldings) CalculateStrategiesRequestPayload {
+	input := CalculateStrategiesRequestPayload{
+		EGLDTokensInvested: holdings.EgldTokens.Text('f', service.FloatingPointAccuracy),
+		MEXTokensInvested:  holdings.MexTokens.Text('f', service.FloatingPointAccuracy),
+		StakingProvider:    holdings.StakingProvider,
+	}
+	if holdings.LockedMexBalance.Sign() > 0 {
+		input.RewardsInLockedMEX = true
+		input.LockedMexHeld = holdings.LockedMexBalance.Text('f', service.FloatingPointAccuracy)
+	}
+
+	return input
+}
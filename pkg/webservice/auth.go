@@ -0,0 +1,115 @@
+package webservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// apiKeyHeader is the header of the API key of the programmatic clients
+const apiKeyHeader = "X-API-Key"
+
+// the keys of the client of the request in the gin context
+const (
+	apiKeyContextKey = "api_key"
+	tierContextKey   = "api_tier"
+)
+
+// authenticate is a middleware identifying the client by its API key; the clients without an API key have the
+// anonymous tier
+func (api *API) authenticate(c *gin.Context) {
+	key := c.GetHeader(apiKeyHeader)
+	if key == "" {
+		c.Set(tierContextKey, service.APITiers[service.AnonymousTier])
+		return
+	}
+
+	apiKey, err := api.service.GetAPIKey(key)
+	if err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			api.rejectAPIKey(c)
+			return
+		}
+
+		log.Error("error authenticating the API key: %s", err)
+		abortWithMessage(c, http.StatusInternalServerError, ErrorCodeInternal, "error authenticating the API key")
+		return
+	}
+
+	tier, ok := service.APITiers[apiKey.Tier]
+	if !ok {
+		// the tier was removed since the key was created
+		log.Error("unknown tier '%s' of the API key %s", apiKey.Tier, apiKey.ID)
+		tier = service.APITiers[service.AnonymousTier]
+	}
+
+	c.Set(apiKeyContextKey, apiKey)
+	c.Set(tierContextKey, tier)
+}
+
+// rejectAPIKey aborts the request with an invalid API key; the failed authentications take a token from the bucket of
+// the IP address of the client, so the keys can't be guessed faster than the anonymous clients can send requests
+func (api *API) rejectAPIKey(c *gin.Context) {
+	ctx, cc := context.WithTimeout(context.Background(), time.Second*5)
+	defer cc()
+
+	// the client has no API key in the context, so it is identified by its IP address
+	client := rateLimitClient(c)
+	limit, err := takeToken(ctx, api.service.Cache, rateLimitKeyBase+":"+client, service.APITiers[service.AnonymousTier].RequestsPerMinute)
+	if err != nil {
+		log.Error("error rate limiting the failed authentication of the client %s: %s", client, err)
+	} else {
+		writeRateLimitHeaders(c, limit)
+		if !limit.Allowed {
+			abortWithMessage(c, http.StatusTooManyRequests, ErrorCodeRateLimited, "too many requests")
+			return
+		}
+	}
+
+	abortWithMessage(c, http.StatusUnauthorized, ErrorCodeUnauthorized, "the API key is not valid")
+}
+
+// clientTier returns the tier of the client of the request, set by authenticate
+func clientTier(c *gin.Context) service.APITier {
+	if tier, ok := c.Get(tierContextKey); ok {
+		return tier.(service.APITier)
+	}
+	return service.APITiers[service.AnonymousTier]
+}
+
+// clientAPIKey returns the API key of the client of the request, or nil if it is anonymous
+func clientAPIKey(c *gin.Context) *service.APIKey {
+	if apiKey, ok := c.Get(apiKeyContextKey); ok {
+		return apiKey.(*service.APIKey)
+	}
+	return nil
+}
+
+// requireFeature is a middleware rejecting the clients whose tier doesn't include the feature
+func requireFeature(feature string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !clientTier(c).HasFeature(feature) {
+			abortWithMessage(c, http.StatusForbidden, ErrorCodeForbidden,
+				fmt.Sprintf("the feature '%s' requires an API key of a tier including it", feature))
+		}
+	}
+}
+
+// abortWithMessage aborts the request with the error envelope of the version of the API of the route
+func abortWithMessage(c *gin.Context, status int, code, message string) {
+	if strings.HasPrefix(c.FullPath(), "/api/v2/") {
+		writeErrorV2(c, status, ErrorV2{Code: code, Message: message})
+		return
+	}
+
+	c.AbortWithStatusJSON(status, gin.H{
+		"message": message,
+	})
+}
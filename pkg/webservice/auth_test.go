@@ -0,0 +1,83 @@
+package webservice
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPI_authenticate(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// the clients without an API key don't reach the cache
+	api := NewAPI(&service.Service{})
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/prices", nil)
+	api.authenticate(c)
+
+	assert.False(t, c.IsAborted())
+	assert.Equal(t, service.APITiers[service.AnonymousTier], clientTier(c))
+	assert.Nil(t, clientAPIKey(c))
+}
+
+func TestAPI_rejectAPIKey(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// the invalid keys are still rejected if the failed authentication can't be counted
+	api := NewAPI(&service.Service{Cache: redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})})
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	c.Request = httptest.NewRequest(http.MethodGet, "/api/prices", nil)
+	api.rejectAPIKey(c)
+
+	assert.True(t, c.IsAborted())
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.JSONEq(t, `{"message":"the API key is not valid"}`, recorder.Body.String())
+}
+
+func TestRequireFeature(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	engine := gin.New()
+	setTier := func(tier string) gin.HandlerFunc {
+		return func(c *gin.Context) {
+			c.Set(tierContextKey, service.APITiers[tier])
+		}
+	}
+	ok := func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	}
+	engine.POST("/api/anonymous", setTier(service.AnonymousTier), requireFeature(service.FeatureBatch), ok)
+	engine.POST("/api/v2/anonymous", setTier(service.AnonymousTier), requireFeature(service.FeatureBatch), ok)
+	engine.POST("/api/basic", setTier("basic"), requireFeature(service.FeatureBatch), ok)
+
+	recorder := httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/basic", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/anonymous", nil))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.JSONEq(t, `{"message":"the feature 'batch' requires an API key of a tier including it"}`, recorder.Body.String())
+
+	// the v2 routes respond with the v2 error envelope
+	recorder = httptest.NewRecorder()
+	engine.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/api/v2/anonymous", nil))
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	var response ErrorResponseV2
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &response))
+	assert.Equal(t, ErrorCodeForbidden, response.Error.Code)
+}
@@ -0,0 +1,35 @@
+package webservice
+
+import (
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// BacktestRequestPayload is the payload of a backtest: the inputs of a profit calculation and the date in the past
+// when the investment started
+type BacktestRequestPayload struct {
+	CalculateStrategiesRequestPayload
+
+	// StartDate is formatted as YYYY-MM-DD; the investment has to end before today
+	StartDate string `json:"start-date"`
+}
+
+// ToBacktestInput returns the parsed inputs of the backtested profit calculation, and a list of errors for invalid
+// fields
+func (payload *BacktestRequestPayload) ToBacktestInput() (*service.StrategiesInput, []error) {
+	var errs []error
+
+	// the target prices are replaced by the recorded prices, so they don't have to be provided
+	strategiesInput, inputErrs := payload.CalculateStrategiesRequestPayload.toStrategiesInput("egld-price-target", "mex-price-target")
+	errs = append(errs, inputErrs...)
+
+	v := newValidator()
+	startDate := v.Date("start-date", payload.StartDate)
+	errs = append(errs, v.Errors()...)
+
+	if len(errs) != 0 {
+		return nil, errs
+	}
+
+	strategiesInput.StartDate = startDate
+	return strategiesInput, nil
+}
@@ -0,0 +1,58 @@
+package webservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBacktestRequestPayload_ToBacktestInput(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// validBacktestPayload returns a payload of a backtest starting a year ago, which passes the validation
+	validBacktestPayload := func() BacktestRequestPayload {
+		payload := BacktestRequestPayload{
+			CalculateStrategiesRequestPayload: validPayload(),
+			StartDate:                         "2022-01-01",
+		}
+		payload.EgldTargetPrice = ""
+		payload.MexTargetPrice = ""
+		return payload
+	}
+
+	t.Run("valid payload", func(t *testing.T) {
+		payload := validBacktestPayload()
+		input, errs := payload.ToBacktestInput()
+		require.Nil(t, errs)
+		assert.Equal(t, "2022-01-01", input.StartDate.Format("2006-01-02"))
+	})
+
+	for _, tt := range []struct {
+		name   string
+		modify func(payload *BacktestRequestPayload)
+		code   string
+		params map[string]string
+	}{
+		{"missing start date", func(p *BacktestRequestPayload) { p.StartDate = "" }, ValidationRequired, nil},
+		{"start date not a date", func(p *BacktestRequestPayload) { p.StartDate = "01/01/2022" }, ValidationInvalidDate,
+			map[string]string{"value": "01/01/2022", "format": "YYYY-MM-DD"}},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			payload := validBacktestPayload()
+			tt.modify(&payload)
+
+			input, errs := payload.ToBacktestInput()
+			assert.Nil(t, input)
+			require.Len(t, errs, 1)
+
+			fieldErrs := fieldErrors(errs)
+			require.Len(t, fieldErrs, 1)
+			assert.Equal(t, "start-date", fieldErrs[0].Field)
+			assert.Equal(t, tt.code, fieldErrs[0].Code)
+			assert.Equal(t, tt.params, fieldErrs[0].Params)
+		})
+	}
+}
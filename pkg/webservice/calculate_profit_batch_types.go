@@ -0,0 +1,22 @@
+package webservice
+
+import (
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// CalculateProfitBatchItem is a line of the NDJSON response of a batch calculation, with either the results or the
+// errors of the payload at Index
+type CalculateProfitBatchItem struct {
+	Index   int                                   `json:"index"`
+	Results map[string]service.StrategyResultJSON `json:"results,omitempty"`
+	// Prices are the prices all the payloads of the batch are calculated with
+	Prices service.Prices          `json:"prices"`
+	Swap   *service.SwapResultJSON `json:"swap,omitempty"`
+	// Unavailable are the requested strategies which were skipped because their market data is not available
+	Unavailable []string `json:"unavailable,omitempty"`
+	// Errors and FieldErrors are set if the payload is invalid
+	Errors      []string      `json:"errors,omitempty"`
+	FieldErrors []*FieldError `json:"field_errors,omitempty"`
+	// Error is set if the calculation failed on the server side
+	Error string `json:"error,omitempty"`
+}
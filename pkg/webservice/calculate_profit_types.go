@@ -1,10 +1,10 @@
 package webservice
 
 import (
-	"errors"
 	"fmt"
 	"math/big"
-	
+	"strconv"
+
 	"github.com/silviutroscot/istari-vision/pkg/service"
 )
 
@@ -26,101 +26,135 @@ type CalculateStrategiesRequestPayload struct {
 	InvestmentDurationInDays int    `json:"target-date-days"`
 	RedelegationPeriodInDays int    `json:"redelegation-interval"`
 	StakingProvider          string `json:"egld-staking-provider"`
+
+	// LiquidAtTargetDate is true if the user needs their tokens to be liquid at the target date, so the exit from the
+	// strategies with an unbonding period starts before it
+	LiquidAtTargetDate bool `json:"liquid-at-target-date"`
+
+	// EgldStakingRatio is the optional percentage of the EGLD supply assumed to be staked, used to project the EGLD APR
+	EgldStakingRatio string `json:"egld-staking-ratio"`
+	// EgldStakingRatioChange is the optional yearly change of EgldStakingRatio in percentage points
+	EgldStakingRatioChange string `json:"egld-staking-ratio-change"`
+
+	// MexLockPeriodInDays is the number of days the locked MEX is locked for (360, 720 or 1440); 0 means 1440
+	MexLockPeriodInDays int `json:"mex-lock-period-days"`
+	// LockedMexHeld is the optional amount of locked MEX the user already holds, which boosts the locked rewards
+	LockedMexHeld string `json:"mex-locked-held"`
+
+	// ContributionAmount is the optional amount bought periodically after the start, in USD if ContributionInUsd is
+	// true and in tokens otherwise; ContributionToken is "egld" or "mex" and ContributionFrequency "weekly" or "monthly"
+	ContributionAmount    string `json:"contribution-amount"`
+	ContributionInUsd     bool   `json:"contribution-in-usd"`
+	ContributionToken     string `json:"contribution-token"`
+	ContributionFrequency string `json:"contribution-frequency"`
+
+	// Strategies is the optional list of strategies to be calculated (e.g. ["stake", "redelegate"]); all the
+	// registered strategies are calculated if it is empty
+	Strategies []string `json:"strategies"`
 }
 
 // ToStrategiesInput returns an instance of service.StrategiesInput representing the parsed inputs and a list of
-// errors for invalid fields
-// todo: add unit tests
-// todo: refactor use of repetitive parsing into generic function
+// *FieldError for invalid fields
 func (payload *CalculateStrategiesRequestPayload) ToStrategiesInput() (*service.StrategiesInput, []error) {
-	var err error
-	var errs []error
+	return payload.toStrategiesInput()
+}
+
+// toStrategiesInput parses the payload without checking the rules of the skipped fields, which are varied by the
+// solver and the sensitivity analysis
+func (payload *CalculateStrategiesRequestPayload) toStrategiesInput(skippedFields ...string) (*service.StrategiesInput, []error) {
+	v := newValidator(skippedFields...)
 
 	strategiesInput := &service.StrategiesInput{
-		EgldTokensInvested:          &big.Float{},
-		MexTokensInvested:           &big.Float{},
-		PercentageOfPortfolioInEgld: &big.Float{},
-		PercentageOfPortfolioInMex:  &big.Float{},
-		RewardsInLockedMEX:          payload.RewardsInLockedMEX,
-		EgldTargetPrice:             &big.Float{},
-		MexTargetPrice:              &big.Float{},
-		EgldAPR:                     &big.Float{},
-		MexAPRLocked:                &big.Float{},
-		MexAPRUnlocked:              &big.Float{},
-		InvestmentDurationInDays:    0,
-		RedelegationIntervalInDays:  0,
-		StakingProvider:             payload.StakingProvider,
+		EgldTokensInvested:            orZero(v.Decimal("egld-tokens-invested", payload.EGLDTokensInvested, false, minDecimal(0))),
+		MexTokensInvested:             orZero(v.Decimal("mex-tokens-invested", payload.MEXTokensInvested, false, minDecimal(0))),
+		PercentageOfPortfolioInEgld:   orZero(v.Decimal("egld-pct", payload.PercentageOfPortfolioInEGLD, false, minDecimal(0), maxDecimal(100))),
+		PercentageOfPortfolioInMex:    orZero(v.Decimal("mex-pct", payload.PercentageOfPortfolioInMEX, false, minDecimal(0), maxDecimal(100))),
+		RewardsInLockedMEX:            payload.RewardsInLockedMEX,
+		EgldTargetPrice:               orZero(v.Decimal("egld-price-target", payload.EgldTargetPrice, true, minDecimal(0))),
+		MexTargetPrice:                orZero(v.Decimal("mex-price-target", payload.MexTargetPrice, false, minDecimal(0))),
+		EgldAPR:                       &big.Float{},
+		MexAPRLocked:                  &big.Float{},
+		MexAPRUnlocked:                &big.Float{},
+		InvestmentDurationInDays:      payload.InvestmentDurationInDays,
+		RedelegationIntervalInDays:    payload.RedelegationPeriodInDays,
+		StakingProvider:               payload.StakingProvider,
+		LiquidAtTargetDate:            payload.LiquidAtTargetDate,
+		MexLockPeriodInDays:           payload.MexLockPeriodInDays,
+		Strategies:                    payload.Strategies,
+		EgldStakingRatio:              v.Decimal("egld-staking-ratio", payload.EgldStakingRatio, false, greaterThanDecimal(0), maxDecimal(100)),
+		EgldStakingRatioChangePerYear: v.Decimal("egld-staking-ratio-change", payload.EgldStakingRatioChange, false),
+		LockedMexHeld:                 v.Decimal("mex-locked-held", payload.LockedMexHeld, false, minDecimal(0)),
 	}
 
-	if payload.EGLDTokensInvested != "" {
-		strategiesInput.EgldTokensInvested, err = parseBigFloat(payload.EGLDTokensInvested)
-		if err != nil {
-			errs = append(errs, fmt.Errorf("failed parsing field 'EGLDTokensInvested': %w", err))
-		} else if strategiesInput.EgldTokensInvested.Cmp(service.BigFloatZero) == -1 {
-			errs = append(errs, fmt.Errorf("failed validating field 'EGLDTokensInvested' value '%s': %w", payload.EGLDTokensInvested, err))
-		}
-	}
+	v.Required("egld-staking-provider", payload.StakingProvider)
 
-	if payload.MEXTokensInvested != "" {
-		strategiesInput.MexTokensInvested, err = parseBigFloat(payload.MEXTokensInvested)
-		if err != nil {
-			errs = append(errs, fmt.Errorf("failed parsing field 'MexTokensInvested': %w", err))
-		} else if strategiesInput.MexTokensInvested.Cmp(service.BigFloatZero) == -1 {
-			errs = append(errs, fmt.Errorf("failed validating field 'MexTokensInvested' value '%s': %w", payload.MEXTokensInvested, err))
+	for _, name := range payload.Strategies {
+		if _, ok := service.DefaultStrategyRegistry.Get(name); !ok {
+			v.fail("strategies", ValidationUnknownStrategy, fmt.Sprintf("the strategy '%s' is not registered", name),
+				map[string]string{"value": name})
 		}
 	}
 
-	if payload.PercentageOfPortfolioInEGLD != "" {
-		strategiesInput.PercentageOfPortfolioInEgld, err = parseBigFloat(payload.PercentageOfPortfolioInEGLD)
-		if err != nil {
-			errs = append(errs, fmt.Errorf("failed parsing field 'PercentageOfPortfolioInEgld': %w", err))
-		} else if strategiesInput.PercentageOfPortfolioInEgld.Cmp(service.BigFloatZero) == -1 || strategiesInput.PercentageOfPortfolioInEgld.Cmp(service.BigFloatOneHundred) == 1 {
-			errs = append(errs, fmt.Errorf("failed validating field 'PercentageOfPortfolioInEgld' value '%s': %w", payload.PercentageOfPortfolioInEGLD, err))
-		}
+	// the duration and the redelegation interval are only required by some strategies; the payloads are validated
+	// against the default strategies, a custom registry of the service is checked by the calculation
+	requiredInputs := requiredStrategyInputs(service.DefaultStrategyRegistry, payload.Strategies)
+	minDuration := 0
+	if requiredInputs["InvestmentDurationInDays"] {
+		minDuration = 1
 	}
+	v.IntRange("target-date-days", payload.InvestmentDurationInDays, minDuration, service.MaxInvestmentDurationInDays)
 
-	if payload.PercentageOfPortfolioInMEX != "" {
-		strategiesInput.PercentageOfPortfolioInMex, err = parseBigFloat(payload.PercentageOfPortfolioInMEX)
-		if err != nil {
-			errs = append(errs, fmt.Errorf("failed parsing field 'PercentageOfPortfolioInMex': %w", err))
-		} else if strategiesInput.PercentageOfPortfolioInMex.Cmp(service.BigFloatZero) == -1 || strategiesInput.PercentageOfPortfolioInMex.Cmp(service.BigFloatOneHundred) == 1 {
-			errs = append(errs, fmt.Errorf("failed validating field 'PercentageOfPortfolioInMex' value '%s': %w", payload.PercentageOfPortfolioInMEX, err))
-		}
+	minRedelegationInterval := 0
+	if requiredInputs["RedelegationIntervalInDays"] {
+		minRedelegationInterval = 1
 	}
-
-	if payload.MexTargetPrice != "" {
-		strategiesInput.MexTargetPrice, err = parseBigFloat(payload.MexTargetPrice)
-		if err != nil {
-			errs = append(errs, fmt.Errorf("failed parsing field 'MexTargetPrice': %w", err))
-		} else if strategiesInput.MexTargetPrice.Cmp(service.BigFloatZero) == -1 {
-			errs = append(errs, fmt.Errorf("failed validating field 'MexTargetPrice' value '%s': %w", payload.MexTargetPrice, err))
-		}
+	if v.IntRange("redelegation-interval", payload.RedelegationPeriodInDays, minRedelegationInterval, service.MaxInvestmentDurationInDays) {
+		v.LessOrEqualField("redelegation-interval", payload.RedelegationPeriodInDays, "target-date-days", payload.InvestmentDurationInDays)
 	}
 
-	strategiesInput.EgldTargetPrice, err = parseBigFloat(payload.EgldTargetPrice)
-	if err != nil {
-		errs = append(errs, fmt.Errorf("failed parsing field 'EgldTargetPrice': %w", err))
-	} else if strategiesInput.EgldTargetPrice.Cmp(service.BigFloatZero) == -1 {
-		errs = append(errs, fmt.Errorf("failed validating field 'EgldTargetPrice' value '%s': %w", payload.EgldTargetPrice, err))
+	allowedLockPeriods := []string{"0"}
+	for _, lockPeriod := range service.LockedMexLockPeriodsInDays {
+		allowedLockPeriods = append(allowedLockPeriods, strconv.Itoa(lockPeriod))
+	}
+	v.OneOf("mex-lock-period-days", strconv.Itoa(payload.MexLockPeriodInDays), allowedLockPeriods...)
+
+	if payload.ContributionAmount != "" {
+		contribution := &service.Contribution{
+			TokenType:   service.ParseTokenType(payload.ContributionToken),
+			Amount:      v.Decimal("contribution-amount", payload.ContributionAmount, true, greaterThanDecimal(0)),
+			AmountInUsd: payload.ContributionInUsd,
+			Frequency:   service.ContributionFrequency(payload.ContributionFrequency),
+		}
+		validToken := v.OneOf("contribution-token", payload.ContributionToken, service.TokenTypeEgld.String(), service.TokenTypeMex.String())
+		validFrequency := v.OneOf("contribution-frequency", payload.ContributionFrequency, string(service.ContributionWeekly), string(service.ContributionMonthly))
+		if contribution.Amount != nil && validToken && validFrequency {
+			strategiesInput.Contribution = contribution
+		}
 	}
-
-	strategiesInput.InvestmentDurationInDays = payload.InvestmentDurationInDays
-	strategiesInput.RedelegationIntervalInDays = payload.RedelegationPeriodInDays
 
 	// verify that the sum of percentages in MEX and EGLD is 100
 	percentageSum := &big.Float{}
 	percentageSum.Add(strategiesInput.PercentageOfPortfolioInEgld, strategiesInput.PercentageOfPortfolioInMex)
 	if !service.BigFloatsAreEqual(*percentageSum, *service.BigFloatOneHundred) {
-		errs = append(errs, errors.New("the sum of the pecentages is not 100%"))
+		v.fail("egld-pct", ValidationSum, "the sum of the percentages of the portfolio in EGLD and MEX must be 100",
+			map[string]string{"fields": "egld-pct,mex-pct", "sum": "100"})
 	}
 
-	if len(errs) != 0 {
+	if errs := v.Errors(); len(errs) != 0 {
 		return nil, errs
 	}
 
 	return strategiesInput, nil
 }
 
+// orZero returns the value, or 0 if it is nil
+func orZero(value *big.Float) *big.Float {
+	if value == nil {
+		return &big.Float{}
+	}
+	return value
+}
+
 func parseBigFloat(input string) (*big.Float, error) {
 	f, _, err := big.ParseFloat(input, 10, 0, big.ToNearestEven)
 	return f, err
@@ -0,0 +1,120 @@
+package webservice
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validPayload returns a payload of a profit calculation which passes the validation
+func validPayload() CalculateStrategiesRequestPayload {
+	return CalculateStrategiesRequestPayload{
+		EGLDTokensInvested:          "10",
+		PercentageOfPortfolioInEGLD: "100",
+		EgldTargetPrice:             "100",
+		MexTargetPrice:              "0.0001",
+		InvestmentDurationInDays:    365,
+		RedelegationPeriodInDays:    7,
+		StakingProvider:             "istari",
+	}
+}
+
+func TestCalculateStrategiesRequestPayload_ToStrategiesInput(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	t.Run("valid payload", func(t *testing.T) {
+		payload := validPayload()
+		input, errs := payload.ToStrategiesInput()
+		require.Nil(t, errs)
+		assert.Equal(t, "10", input.EgldTokensInvested.String())
+		assert.Equal(t, "0", input.MexTokensInvested.String())
+		assert.Equal(t, 365, input.InvestmentDurationInDays)
+		assert.Nil(t, input.EgldStakingRatio)
+	})
+
+	for _, tt := range []struct {
+		name   string
+		modify func(payload *CalculateStrategiesRequestPayload)
+		field  string
+		code   string
+		params map[string]string
+	}{
+		{"negative tokens", func(p *CalculateStrategiesRequestPayload) { p.EGLDTokensInvested = "-1" }, "egld-tokens-invested", ValidationMin, map[string]string{"min": "0"}},
+		{"not a number", func(p *CalculateStrategiesRequestPayload) { p.MEXTokensInvested = "abc" }, "mex-tokens-invested", ValidationNotANumber, map[string]string{"value": "abc"}},
+		{"missing target price", func(p *CalculateStrategiesRequestPayload) { p.EgldTargetPrice = "" }, "egld-price-target", ValidationRequired, nil},
+		{"percentage above 100", func(p *CalculateStrategiesRequestPayload) { p.PercentageOfPortfolioInMEX = "101" }, "mex-pct", ValidationMax, map[string]string{"max": "100"}},
+		{"percentages sum", func(p *CalculateStrategiesRequestPayload) { p.PercentageOfPortfolioInEGLD = "50" }, "egld-pct", ValidationSum, map[string]string{"fields": "egld-pct,mex-pct", "sum": "100"}},
+		{"staking ratio of 0", func(p *CalculateStrategiesRequestPayload) { p.EgldStakingRatio = "0" }, "egld-staking-ratio", ValidationGreaterThan, map[string]string{"min": "0"}},
+		{"missing duration", func(p *CalculateStrategiesRequestPayload) {
+			p.InvestmentDurationInDays = 0
+			p.RedelegationPeriodInDays = 0
+			p.Strategies = []string{"stake"}
+		}, "target-date-days", ValidationMin, map[string]string{"min": "1"}},
+		{"duration too long", func(p *CalculateStrategiesRequestPayload) { p.InvestmentDurationInDays = 3651 }, "target-date-days", ValidationMax, map[string]string{"max": "3650"}},
+		{"missing redelegation interval", func(p *CalculateStrategiesRequestPayload) { p.RedelegationPeriodInDays = 0 }, "redelegation-interval", ValidationMin, map[string]string{"min": "1"}},
+		{"redelegation interval longer than the duration", func(p *CalculateStrategiesRequestPayload) { p.RedelegationPeriodInDays = 400 }, "redelegation-interval", ValidationLessOrEqualField, map[string]string{"field": "target-date-days", "other": "365"}},
+		{"missing staking provider", func(p *CalculateStrategiesRequestPayload) { p.StakingProvider = "" }, "egld-staking-provider", ValidationRequired, nil},
+		{"unknown strategy", func(p *CalculateStrategiesRequestPayload) { p.Strategies = []string{"moon"} }, "strategies", ValidationUnknownStrategy, map[string]string{"value": "moon"}},
+		{"lock period", func(p *CalculateStrategiesRequestPayload) { p.MexLockPeriodInDays = 100 }, "mex-lock-period-days", ValidationOneOf, map[string]string{"value": "100", "values": "0,360,720,1440"}},
+		{"contribution frequency", func(p *CalculateStrategiesRequestPayload) {
+			p.ContributionAmount = "10"
+			p.ContributionToken = "egld"
+			p.ContributionFrequency = "daily"
+		}, "contribution-frequency", ValidationOneOf, map[string]string{"value": "daily", "values": "weekly,monthly"}},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			payload := validPayload()
+			tt.modify(&payload)
+
+			input, errs := payload.ToStrategiesInput()
+			assert.Nil(t, input)
+			require.Len(t, errs, 1)
+
+			fieldErrs := fieldErrors(errs)
+			require.Len(t, fieldErrs, 1)
+			assert.Equal(t, tt.field, fieldErrs[0].Field)
+			assert.Equal(t, tt.code, fieldErrs[0].Code)
+			assert.Equal(t, tt.params, fieldErrs[0].Params)
+			assert.NotContains(t, errs[0].Error(), "%!")
+		})
+	}
+
+	t.Run("the redelegation interval is only required by the redelegating strategies", func(t *testing.T) {
+		payload := validPayload()
+		payload.RedelegationPeriodInDays = 0
+		payload.Strategies = []string{"stake", "hold"}
+
+		_, errs := payload.ToStrategiesInput()
+		assert.Nil(t, errs)
+	})
+
+	t.Run("skipped fields", func(t *testing.T) {
+		payload := validPayload()
+		payload.EgldTargetPrice = ""
+		payload.InvestmentDurationInDays = 0
+
+		input, errs := payload.toStrategiesInput("egld-price-target", "target-date-days")
+		require.Nil(t, errs)
+		assert.Equal(t, "0", input.EgldTargetPrice.String())
+	})
+}
+
+func TestValidateStakingProvider(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	providers := []fetcher.EgldStakingProvider{{Identity: "istari"}}
+	assert.NoError(t, validateStakingProvider("egld-staking-provider", "istari", providers))
+
+	err := validateStakingProvider("egld-staking-provider", "unknown", providers)
+	require.Error(t, err)
+	fieldErr := fieldErrors([]error{err})
+	require.Len(t, fieldErr, 1)
+	assert.Equal(t, ValidationUnknownStakingProvider, fieldErr[0].Code)
+	assert.True(t, strings.HasPrefix(err.Error(), "failed validating field 'egld-staking-provider'"))
+}
@@ -0,0 +1,53 @@
+package webservice
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// CompareVariantPayload is a named configuration of the strategies to be compared
+type CompareVariantPayload struct {
+	Name  string                            `json:"name"`
+	Input CalculateStrategiesRequestPayload `json:"input"`
+}
+
+// CompareRequestPayload is the payload of the comparison of several configurations of the strategies
+type CompareRequestPayload struct {
+	Variants []CompareVariantPayload `json:"variants"`
+	// Baseline is the optional name of the variant the deltas are computed against; it defaults to the first variant
+	Baseline string `json:"baseline"`
+}
+
+// ToCompareVariants returns the parsed variants, and a list of errors for invalid fields prefixed by the name of the
+// variant
+func (payload *CompareRequestPayload) ToCompareVariants() ([]service.CompareVariant, []error) {
+	var errs []error
+
+	variants := make([]service.CompareVariant, 0, len(payload.Variants))
+	for i, variantPayload := range payload.Variants {
+		input, inputErrs := variantPayload.Input.ToStrategiesInput()
+		for _, err := range inputErrs {
+			// the fields are named by their path in the payload
+			var fieldErr *FieldError
+			if errors.As(err, &fieldErr) {
+				err = &FieldError{Field: variantField(i, fieldErr.Field), Code: fieldErr.Code, Message: fieldErr.Message, Params: fieldErr.Params}
+			}
+			errs = append(errs, fmt.Errorf("variant '%s': %w", variantPayload.Name, err))
+		}
+
+		variants = append(variants, service.CompareVariant{Name: variantPayload.Name, Input: input})
+	}
+
+	if len(errs) != 0 {
+		return nil, errs
+	}
+
+	return variants, nil
+}
+
+// variantField returns the path of a field of the input of the variant at index i
+func variantField(i int, field string) string {
+	return fmt.Sprintf("variants[%d].input.%s", i, field)
+}
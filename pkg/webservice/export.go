@@ -0,0 +1,183 @@
+package webservice
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/export"
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// exportFormat returns the export format requested by the 'format' query parameter or else by the Accept header,
+// empty for JSON; it returns false if the query parameter is an unknown format
+func exportFormat(c *gin.Context) (export.Format, bool) {
+	if format := c.Query("format"); format != "" {
+		if format == "json" {
+			return "", true
+		}
+		_, ok := export.ContentTypes[export.Format(format)]
+		return export.Format(format), ok
+	}
+
+	accept := c.GetHeader("Accept")
+	for format, contentType := range export.ContentTypes {
+		if strings.Contains(accept, contentType) {
+			return format, true
+		}
+	}
+	return "", true
+}
+
+// writeExport writes the results of the profit calculation as a file of the format, with the inputs, the market data
+// and, if the query parameter 'timeline' is true, the results at regular intervals
+func (api *API) writeExport(c *gin.Context, format export.Format, requestPayload *CalculateStrategiesRequestPayload, results map[string]service.StrategyResultJSON, egldStakingProviders []fetcher.EgldStakingProvider, economics service.Economics) {
+	tables := []export.Table{
+		inputsTable(requestPayload),
+		marketTable(requestPayload, egldStakingProviders, economics),
+		resultsTable(results),
+	}
+
+	if c.Query("timeline") == "true" {
+		// the input is parsed again, as the calculation completed it with the market data
+		strategiesInput, _ := requestPayload.ToStrategiesInput()
+		timeline, err := api.service.Timeline(strategiesInput, egldStakingProviders, economics)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": err,
+			})
+			return
+		}
+		tables = append(tables, timelineTable(timeline))
+	}
+
+	var file bytes.Buffer
+	var err error
+	switch format {
+	case export.FormatCSV:
+		err = export.WriteCSV(&file, tables)
+	case export.FormatXLSX:
+		err = export.WriteXLSX(&file, tables)
+	case export.FormatPDF:
+		err = export.WritePDF(&file, "Istari Vision profit report - "+time.Now().UTC().Format("2006-01-02 15:04 MST"), tables)
+	}
+	if err != nil {
+		log.Error("error exporting the results to %s: %s", format, err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err,
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="istari-vision-results.%s"`, format))
+	c.Data(http.StatusOK, export.ContentTypes[format], file.Bytes())
+}
+
+// inputsTable returns the fields of the payload which are set, ordered by name
+func inputsTable(requestPayload *CalculateStrategiesRequestPayload) export.Table {
+	table := export.Table{Name: "Inputs", Header: []string{"Field", "Value"}}
+
+	data, err := json.Marshal(requestPayload)
+	if err != nil {
+		return table
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return table
+	}
+
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		var value string
+		switch fieldValue := fields[name].(type) {
+		case nil:
+			continue
+		case []interface{}:
+			values := make([]string, len(fieldValue))
+			for i, v := range fieldValue {
+				values[i] = fmt.Sprint(v)
+			}
+			value = strings.Join(values, " ")
+		default:
+			value = fmt.Sprint(fieldValue)
+		}
+		if value == "" || value == "0" || value == "false" {
+			continue
+		}
+		table.Rows = append(table.Rows, []string{name, value})
+	}
+
+	return table
+}
+
+// marketTable returns the market data the calculation ran against
+func marketTable(requestPayload *CalculateStrategiesRequestPayload, egldStakingProviders []fetcher.EgldStakingProvider, economics service.Economics) export.Table {
+	table := export.Table{
+		Name:   "Market",
+		Header: []string{"Data", "Value"},
+		Rows: [][]string{
+			{"Date", time.Now().UTC().Format(time.RFC3339)},
+			{"EGLD price (USD)", economics.Prices.EGLD},
+			{"MEX price (USD)", economics.Prices.MEX},
+		},
+	}
+
+	mexEconomics := economics.MexEconomics()
+	if mexEconomics.LockedRewardsAPR != nil && mexEconomics.UnlockedRewardsAPR != nil {
+		table.Rows = append(table.Rows,
+			[]string{"MEX locked rewards APR", mexEconomics.LockedRewardsAPR.Text('f', service.FloatingPointAccuracy)},
+			[]string{"MEX unlocked rewards APR", mexEconomics.UnlockedRewardsAPR.Text('f', service.FloatingPointAccuracy)})
+	}
+	for _, provider := range egldStakingProviders {
+		if provider.Identity == requestPayload.StakingProvider {
+			table.Rows = append(table.Rows, []string{"APR of " + provider.Identity, fmt.Sprint(provider.APR)})
+		}
+	}
+
+	return table
+}
+
+// resultsTable returns a row per strategy result, ordered by key
+func resultsTable(results map[string]service.StrategyResultJSON) export.Table {
+	table := export.Table{
+		Name: "Results",
+		Header: []string{"Strategy", "TotalBalanceInEgld", "TotalBalanceInMex", "TotalBalanceInUsd", "ProfitInEgld",
+			"ProfitInMex", "ProfitInUSD", "ROI", "UnbondingPeriodInDays", "LiquidAt", "NetProfitInUsd"},
+	}
+
+	keys := make([]string, 0, len(results))
+	for key := range results {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		result := results[key]
+		table.Rows = append(table.Rows, []string{key, result.TotalBalanceInEgld, result.TotalBalanceInMex,
+			result.TotalBalanceInUsd, result.ProfitInEgld, result.ProfitInMex, result.ProfitInUSD, result.ROI,
+			fmt.Sprint(result.UnbondingPeriodInDays), result.LiquidAt, result.NetProfitInUsd})
+	}
+
+	return table
+}
+
+// timelineTable returns a row per point of the timeline
+func timelineTable(timeline []service.TimelinePointJSON) export.Table {
+	table := export.Table{Name: "Timeline", Header: []string{"Day", "Date", "Strategy", "TotalBalanceInUsd", "ROI"}}
+	for _, point := range timeline {
+		table.Rows = append(table.Rows, []string{fmt.Sprint(point.Day), point.Date, point.Strategy, point.TotalBalanceInUsd, point.ROI})
+	}
+	return table
+}
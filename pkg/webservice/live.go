@@ -0,0 +1,121 @@
+package webservice
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+const (
+	// liveResubscribeBackoff is the delay before resubscribing when the subscription is lost, doubled for each
+	// consecutive attempt up to liveResubscribeMaxBackoff
+	liveResubscribeBackoff    = time.Second
+	liveResubscribeMaxBackoff = time.Minute
+)
+
+// liveHub fans out the market updates of a single subscription to the live clients of the instance; the subscription
+// is only open while there are clients, and is reopened if it is lost while they are connected
+type liveHub struct {
+	subscribe func(ctx context.Context) <-chan string
+	// backoff and maxBackoff bound the delay before resubscribing
+	backoff    time.Duration
+	maxBackoff time.Duration
+
+	mutex   sync.Mutex
+	clients map[chan string]struct{}
+	cancel  context.CancelFunc
+}
+
+func newLiveHub(subscribe func(ctx context.Context) <-chan string) *liveHub {
+	return &liveHub{
+		subscribe:  subscribe,
+		backoff:    liveResubscribeBackoff,
+		maxBackoff: liveResubscribeMaxBackoff,
+		clients:    make(map[chan string]struct{}),
+	}
+}
+
+// Subscribe registers a client, which receives the updates until the returned function is called
+func (h *liveHub) Subscribe() (<-chan string, func()) {
+	client := make(chan string, 1)
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if len(h.clients) == 0 {
+		ctx, cancel := context.WithCancel(context.Background())
+		h.cancel = cancel
+		go h.broadcast(ctx, h.subscribe(ctx))
+	}
+	h.clients[client] = struct{}{}
+
+	return client, func() {
+		h.unsubscribe(client)
+	}
+}
+
+func (h *liveHub) unsubscribe(client chan string) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	delete(h.clients, client)
+	if len(h.clients) == 0 && h.cancel != nil {
+		h.cancel()
+		h.cancel = nil
+	}
+}
+
+// broadcast sends the updates to the clients until the context is done; when the subscription is lost, e.g. because
+// the connection to the cache dropped, it resubscribes with an exponential backoff
+func (h *liveHub) broadcast(ctx context.Context, updates <-chan string) {
+	backoff := h.backoff
+	for {
+		if h.forward(ctx, updates) {
+			backoff = h.backoff
+		}
+		// the subscription was closed because the last client disconnected
+		if ctx.Err() != nil {
+			return
+		}
+
+		log.Error("the subscription to the market updates was lost, resubscribing in %s", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			return
+		}
+		backoff *= 2
+		if backoff > h.maxBackoff {
+			backoff = h.maxBackoff
+		}
+
+		updates = h.subscribe(ctx)
+	}
+}
+
+// forward sends the updates of the subscription to the clients until it is closed, and returns whether any update was
+// received; a slow client misses the intermediate updates, as the pending update is replaced by the latest one
+func (h *liveHub) forward(ctx context.Context, updates <-chan string) bool {
+	received := false
+	for update := range updates {
+		received = true
+
+		h.mutex.Lock()
+		// the subscription was closed, and the clients may already receive the updates of a new one
+		if ctx.Err() != nil {
+			h.mutex.Unlock()
+			return received
+		}
+		for client := range h.clients {
+			select {
+			case <-client:
+			default:
+			}
+			client <- update
+		}
+		h.mutex.Unlock()
+	}
+	return received
+}
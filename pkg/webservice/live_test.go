@@ -0,0 +1,163 @@
+package webservice
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSubscription is a subscription to the market updates which are sent on its channel; a value sent on lost closes
+// the open subscription as if the connection to the cache dropped
+type fakeSubscription struct {
+	updates chan string
+	lost    chan struct{}
+	opened  int32
+	closed  chan struct{}
+}
+
+func newFakeSubscription() *fakeSubscription {
+	return &fakeSubscription{
+		updates: make(chan string),
+		lost:    make(chan struct{}),
+		closed:  make(chan struct{}, 10),
+	}
+}
+
+func (f *fakeSubscription) subscribe(ctx context.Context) <-chan string {
+	atomic.AddInt32(&f.opened, 1)
+	updates := make(chan string)
+	go func() {
+		defer close(updates)
+		for {
+			select {
+			case update := <-f.updates:
+				updates <- update
+			case <-f.lost:
+				return
+			case <-ctx.Done():
+				f.closed <- struct{}{}
+				return
+			}
+		}
+	}()
+	return updates
+}
+
+func TestLiveHub(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	subscription := newFakeSubscription()
+	hub := newLiveHub(subscription.subscribe)
+
+	first, unsubscribeFirst := hub.Subscribe()
+	second, unsubscribeSecond := hub.Subscribe()
+	// the clients share a single subscription
+	assert.Equal(t, int32(1), atomic.LoadInt32(&subscription.opened))
+
+	subscription.updates <- "update 1"
+	assert.Equal(t, "update 1", <-first)
+	assert.Equal(t, "update 1", <-second)
+
+	// the second client doesn't read the updates, so it only receives the latest one
+	subscription.updates <- "update 2"
+	assert.Equal(t, "update 2", <-first)
+	subscription.updates <- "update 3"
+	assert.Equal(t, "update 3", <-first)
+	assert.Equal(t, "update 3", <-second)
+
+	unsubscribeFirst()
+	select {
+	case <-subscription.closed:
+		t.Fatal("the subscription was closed while a client is connected")
+	default:
+	}
+
+	unsubscribeSecond()
+	select {
+	case <-subscription.closed:
+	case <-time.After(time.Second):
+		t.Fatal("the subscription was not closed after the last client disconnected")
+	}
+
+	// a new client opens a new subscription
+	_, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&subscription.opened))
+}
+
+func TestLiveHub_Resubscribe(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	subscription := newFakeSubscription()
+	hub := newLiveHub(subscription.subscribe)
+	hub.backoff = time.Millisecond
+
+	client, unsubscribe := hub.Subscribe()
+	defer unsubscribe()
+
+	subscription.updates <- "update 1"
+	assert.Equal(t, "update 1", <-client)
+
+	// the client keeps receiving the updates after the subscription is lost
+	subscription.lost <- struct{}{}
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&subscription.opened) == 2
+	}, time.Second, time.Millisecond)
+
+	subscription.updates <- "update 2"
+	assert.Equal(t, "update 2", <-client)
+}
+
+func TestAPI_HandleGetLive(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// the cache is not reachable, so the current market data is not sent
+	api := NewAPI(&service.Service{Cache: redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", MaxRetries: -1})})
+	subscription := newFakeSubscription()
+	api.live = newLiveHub(subscription.subscribe)
+
+	engine := gin.New()
+	engine.GET("/api/live", api.HandleGetLive)
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/api/live", nil)
+	require.NoError(t, err)
+
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	defer response.Body.Close()
+
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, "no-cache", response.Header.Get("Cache-Control"))
+
+	subscription.updates <- `{"prices":{"egld":"40","mex":"0.00005"}}`
+
+	reader := bufio.NewReader(response.Body)
+	var lines []string
+	for len(lines) < 2 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	assert.Equal(t, []string{"event:market", `data:{"prices":{"egld":"40","mex":"0.00005"}}`}, lines)
+	assert.True(t, strings.HasPrefix(response.Header.Get("Content-Type"), mimeEventStream))
+}
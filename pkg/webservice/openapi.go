@@ -0,0 +1,522 @@
+package webservice
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/export"
+	"github.com/silviutroscot/istari-vision/pkg/openapi"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+const (
+	// apiVersion is the version of the API in the OpenAPI specification
+	apiVersion = "1.0.0"
+
+	// swaggerUIVersion is the version of Swagger UI loaded by the documentation page
+	swaggerUIVersion = "4.15.5"
+)
+
+// route is an endpoint of the API, described for the OpenAPI specification
+type route struct {
+	Method  string
+	Path    string
+	Handler gin.HandlerFunc
+
+	Summary string
+	Tag     string
+	// Parameters are the query and header parameters; the path parameters are read from Path
+	Parameters []openapi.Parameter
+	// Request is a value of the type of the JSON body, nil if there is none
+	Request interface{}
+	// Responses maps the status codes to a value of the type of their JSON body, nil if there is none
+	Responses map[int]interface{}
+	// Exports is true if the response can also be a CSV, XLSX or PDF file
+	Exports bool
+	// RateLimit is the number of requests per minute of each client to the route, in addition to the limit of its
+	// tier; 0 if only the limit of the tier applies
+	RateLimit int64
+	// Feature is the feature of the API the tier of the client must include, empty if the route is available to all
+	Feature string
+	// Stream is the content type of the successful response if it is a stream of values of the type of its body, e.g.
+	// NDJSON lines
+	Stream string
+}
+
+// routes returns the endpoints of the version 1 of the API, served under /api; they are both registered and documented from this list, so that
+// the OpenAPI specification can't drift from the handlers
+func (api *API) routes() []route {
+	return []route{
+		{
+			Method: http.MethodGet, Path: "/egld_staking_providers", Handler: api.HandleGetEgldStakingProviders,
+			Summary: "List the EGLD staking providers", Tag: "market",
+			Responses: map[int]interface{}{
+				http.StatusOK:                  stakingProvidersResponse{},
+				http.StatusNotFound:            messageResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/prices", Handler: api.HandleGetPrices,
+			Summary: "Get the live EGLD and MEX prices", Tag: "market",
+			Responses: map[int]interface{}{
+				http.StatusOK:                  pricesResponse{},
+				http.StatusNotFound:            errorResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/live", Handler: api.HandleGetLive,
+			Summary: "Stream the prices and APRs as Server-Sent Events after each refresh", Tag: "market",
+			Responses: map[int]interface{}{
+				http.StatusOK: service.MarketUpdate{},
+			},
+			Stream: mimeEventStream,
+		},
+		{
+			Method: http.MethodGet, Path: "/strategies", Handler: api.HandleGetStrategies,
+			Summary: "List the strategies which can be calculated", Tag: "strategies",
+			Responses: map[int]interface{}{
+				http.StatusOK: strategiesResponse{},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/wallet/:address", Handler: api.HandleGetWallet,
+			Summary: "Import the holdings of a wallet as a calculation input", Tag: "strategies",
+			RateLimit: 30,
+			Responses: map[int]interface{}{
+				http.StatusOK:                  walletResponse{},
+				http.StatusBadRequest:          errorsResponse{},
+				http.StatusInternalServerError: errorResponse{},
+				http.StatusBadGateway:          errorResponse{},
+				http.StatusServiceUnavailable:  messageResponse{},
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/calculate_profit", Handler: api.HandlePostCalculateProfit,
+			Summary: "Calculate the profit of the strategies", Tag: "strategies",
+			Parameters: []openapi.Parameter{
+				queryParameter("format", "the format of the response: json, csv, xlsx or pdf; the Accept header is used if it is not set"),
+				queryParameter("timeline", "true to add the balances at regular intervals to the exported files"),
+			},
+			Request: CalculateStrategiesRequestPayload{},
+			Responses: map[int]interface{}{
+				http.StatusOK:                  calculateProfitResponse{},
+				http.StatusBadRequest:          errorsResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+			Exports: true,
+		},
+		{
+			Method: http.MethodPost, Path: "/calculate_profit/batch", Handler: api.HandlePostCalculateProfitBatch,
+			Summary: "Calculate the profit of several payloads against the same prices", Tag: "strategies",
+			RateLimit: 10,
+			Request:   []CalculateStrategiesRequestPayload{},
+			Responses: map[int]interface{}{
+				http.StatusOK:                    CalculateProfitBatchItem{},
+				http.StatusBadRequest:            errorsResponse{},
+				http.StatusRequestEntityTooLarge: errorsResponse{},
+				http.StatusInternalServerError:   errorResponse{},
+			},
+			Feature: service.FeatureBatch,
+			Stream:  mimeNDJSON,
+		},
+		{
+			Method: http.MethodPost, Path: "/solve", Handler: api.HandlePostSolve,
+			Summary: "Find the value of an input reaching a target", Tag: "strategies",
+			RateLimit: 30,
+			Request:   SolveRequestPayload{},
+			Responses: map[int]interface{}{
+				http.StatusOK:                  solveResponse{},
+				http.StatusBadRequest:          errorsResponse{},
+				http.StatusUnprocessableEntity: errorsResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/sensitivity", Handler: api.HandlePostSensitivity,
+			Summary: "Calculate the strategies over a grid of two inputs", Tag: "strategies",
+			RateLimit: 20,
+			Request:   SensitivityRequestPayload{},
+			Responses: map[int]interface{}{
+				http.StatusOK:                  sensitivityResponse{},
+				http.StatusBadRequest:          errorsResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/backtest", Handler: api.HandlePostBacktest,
+			Summary: "Replay the strategies against the recorded market data", Tag: "strategies",
+			RateLimit: 20,
+			Request:   BacktestRequestPayload{},
+			Responses: map[int]interface{}{
+				http.StatusOK:                  backtestResponse{},
+				http.StatusBadRequest:          errorsResponse{},
+				http.StatusUnprocessableEntity: errorsResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/compare", Handler: api.HandlePostCompare,
+			Summary: "Rank several configurations of the strategies", Tag: "strategies",
+			RateLimit: 30,
+			Request:   CompareRequestPayload{},
+			Responses: map[int]interface{}{
+				http.StatusOK:                  compareResponse{},
+				http.StatusBadRequest:          errorsResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/scenarios", Handler: api.HandlePostScenario,
+			Summary: "Calculate the profit and save it as a scenario", Tag: "scenarios",
+			Parameters: []openapi.Parameter{
+				queryParameter("expires-in-days", "the number of days the scenario is kept, between 1 and 365; 30 by default"),
+			},
+			Request: CalculateStrategiesRequestPayload{},
+			Responses: map[int]interface{}{
+				http.StatusCreated:             savedScenarioResponse{},
+				http.StatusBadRequest:          errorsResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/scenarios/:id", Handler: api.HandleGetScenario,
+			Summary: "Get a saved scenario", Tag: "scenarios",
+			Parameters: []openapi.Parameter{
+				queryParameter("rerun", "true to calculate the scenario again against the current market data"),
+			},
+			Responses: map[int]interface{}{
+				http.StatusOK:                  scenarioResponse{},
+				http.StatusNotFound:            messageResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+		},
+		{
+			Method: http.MethodDelete, Path: "/scenarios/:id", Handler: api.HandleDeleteScenario,
+			Summary: "Delete a saved scenario", Tag: "scenarios",
+			Parameters: []openapi.Parameter{
+				headerParameter(scenarioTokenHeader, "the token returned when the scenario was saved"),
+			},
+			Responses: map[int]interface{}{
+				http.StatusNoContent:           nil,
+				http.StatusForbidden:           messageResponse{},
+				http.StatusNotFound:            messageResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/alerts", Handler: api.HandlePostAlert,
+			Summary: "Register an alert rule delivered to a webhook", Tag: "alerts",
+			Request: AlertRuleRequestPayload{},
+			Responses: map[int]interface{}{
+				http.StatusCreated:             alertResponse{},
+				http.StatusBadRequest:          errorsResponse{},
+				http.StatusServiceUnavailable:  messageResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+			RateLimit: 10,
+			Feature:   service.FeatureAlerts,
+		},
+		{
+			Method: http.MethodGet, Path: "/alerts/:id", Handler: api.HandleGetAlert,
+			Summary: "Get an alert rule and its deliveries", Tag: "alerts",
+			Parameters: []openapi.Parameter{
+				headerParameter(alertSecretHeader, "the secret signing the webhooks of the rule"),
+			},
+			Responses: map[int]interface{}{
+				http.StatusOK:                  alertDeliveriesResponse{},
+				http.StatusForbidden:           messageResponse{},
+				http.StatusNotFound:            messageResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+		},
+		{
+			Method: http.MethodDelete, Path: "/alerts/:id", Handler: api.HandleDeleteAlert,
+			Summary: "Delete an alert rule", Tag: "alerts",
+			Parameters: []openapi.Parameter{
+				headerParameter(alertSecretHeader, "the secret signing the webhooks of the rule"),
+			},
+			Responses: map[int]interface{}{
+				http.StatusNoContent:           nil,
+				http.StatusForbidden:           messageResponse{},
+				http.StatusNotFound:            messageResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/digests", Handler: api.HandlePostDigest,
+			Summary: "Subscribe to the weekly digest of a scenario, pending the confirmation of the email address", Tag: "scenarios",
+			Request: DigestSubscriptionRequestPayload{},
+			Responses: map[int]interface{}{
+				http.StatusAccepted:            digestSubscriptionResponse{},
+				http.StatusBadRequest:          errorsResponse{},
+				http.StatusConflict:            messageResponse{},
+				http.StatusServiceUnavailable:  messageResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+			RateLimit: 5,
+		},
+		{
+			Method: http.MethodGet, Path: "/digests/:id/confirm", Handler: api.HandleGetDigestConfirm,
+			Summary: "Confirm the subscription to the weekly digest of a scenario", Tag: "scenarios",
+			Parameters: []openapi.Parameter{
+				queryParameter("token", "the token of the confirmation link"),
+			},
+			Responses: map[int]interface{}{
+				http.StatusOK:                  messageResponse{},
+				http.StatusNotFound:            messageResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+			RateLimit: 30,
+		},
+		{
+			Method: http.MethodGet, Path: "/digests/:id/unsubscribe", Handler: api.HandleGetDigestUnsubscribe,
+			Summary: "Unsubscribe from the weekly digest of a scenario", Tag: "scenarios",
+			Parameters: []openapi.Parameter{
+				queryParameter("token", "the token of the unsubscribe link of the digests"),
+			},
+			Responses: map[int]interface{}{
+				http.StatusOK:                  messageResponse{},
+				http.StatusNotFound:            messageResponse{},
+				http.StatusInternalServerError: errorResponse{},
+			},
+			RateLimit: 30,
+		},
+	}
+}
+
+// routesV2 returns the endpoints of the version 2 of the API, served under /api/v2, whose responses are typed and
+// whose errors share a single envelope
+func (api *API) routesV2() []route {
+	return []route{
+		{
+			Method: http.MethodGet, Path: "/v2/egld_staking_providers", Handler: api.HandleGetEgldStakingProvidersV2,
+			Summary: "List the EGLD staking providers", Tag: "v2",
+			Responses: map[int]interface{}{
+				http.StatusOK:                  StakingProvidersResponseV2{},
+				http.StatusInternalServerError: ErrorResponseV2{},
+				http.StatusServiceUnavailable:  ErrorResponseV2{},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/v2/prices", Handler: api.HandleGetPricesV2,
+			Summary: "Get the live EGLD and MEX prices", Tag: "v2",
+			Responses: map[int]interface{}{
+				http.StatusOK:                  PricesResponseV2{},
+				http.StatusInternalServerError: ErrorResponseV2{},
+				http.StatusServiceUnavailable:  ErrorResponseV2{},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/v2/strategies", Handler: api.HandleGetStrategiesV2,
+			Summary: "List the strategies which can be calculated", Tag: "v2",
+			Responses: map[int]interface{}{
+				http.StatusOK: StrategiesResponseV2{},
+			},
+		},
+		{
+			Method: http.MethodGet, Path: "/v2/wallet/:address", Handler: api.HandleGetWalletV2,
+			Summary: "Import the holdings of a wallet as a calculation input", Tag: "v2",
+			RateLimit: 30,
+			Responses: map[int]interface{}{
+				http.StatusOK:                  WalletResponseV2{},
+				http.StatusBadRequest:          ErrorResponseV2{},
+				http.StatusInternalServerError: ErrorResponseV2{},
+				http.StatusBadGateway:          ErrorResponseV2{},
+				http.StatusServiceUnavailable:  ErrorResponseV2{},
+			},
+		},
+		{
+			Method: http.MethodPost, Path: "/v2/calculate_profit", Handler: api.HandlePostCalculateProfitV2,
+			Summary: "Calculate the profit of the strategies", Tag: "v2",
+			Request: CalculateStrategiesRequestPayload{},
+			Responses: map[int]interface{}{
+				http.StatusOK:                  CalculateProfitResponseV2{},
+				http.StatusBadRequest:          ErrorResponseV2{},
+				http.StatusInternalServerError: ErrorResponseV2{},
+				http.StatusServiceUnavailable:  ErrorResponseV2{},
+			},
+		},
+	}
+}
+
+// rateLimitHeaders returns the headers describing the rate limit of the client, set on the responses of the
+// authenticated requests
+func rateLimitHeaders(limited bool) map[string]openapi.Header {
+	integer := &openapi.Schema{Type: "integer"}
+	headers := map[string]openapi.Header{
+		rateLimitLimitHeader:     {Description: "the number of requests allowed in a minute", Schema: integer},
+		rateLimitRemainingHeader: {Description: "the number of requests which can still be sent", Schema: integer},
+		rateLimitResetHeader:     {Description: "the number of seconds until the limit is fully replenished", Schema: integer},
+	}
+	if limited {
+		headers[retryAfterHeader] = openapi.Header{Description: "the number of seconds until a request is allowed", Schema: integer}
+	}
+	return headers
+}
+
+func queryParameter(name, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "query", Description: description, Schema: &openapi.Schema{Type: "string"}}
+}
+
+func headerParameter(name, description string) openapi.Parameter {
+	return openapi.Parameter{Name: name, In: "header", Description: description, Required: true, Schema: &openapi.Schema{Type: "string"}}
+}
+
+// OpenAPI returns the OpenAPI specification of the endpoints served under /api
+func OpenAPI() *openapi.Document {
+	generator := openapi.NewGenerator()
+	document := &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "Istari Vision API",
+			Description: "Calculates the profit of the EGLD and MEX investment strategies",
+			Version:     apiVersion,
+		},
+		Paths: map[string]openapi.PathItem{},
+	}
+
+	// the handlers are not called, so the routes are listed without a service
+	api := &API{}
+	for _, r := range append(api.routes(), api.routesV2()...) {
+		path, parameters := openapi.PathParameters("/api" + r.Path)
+
+		operation := &openapi.Operation{
+			OperationID: operationID(r.Method, r.Path),
+			Summary:     r.Summary,
+			Tags:        []string{r.Tag},
+			Parameters:  append(parameters, r.Parameters...),
+			Responses:   map[string]openapi.Response{},
+		}
+		if r.RateLimit != 0 {
+			operation.Description = fmt.Sprintf("Limited to %d requests per minute, in addition to the limit of the tier of the client.", r.RateLimit)
+		}
+		if r.Request != nil {
+			operation.RequestBody = &openapi.RequestBody{
+				Required: true,
+				Content:  map[string]openapi.MediaType{gin.MIMEJSON: {Schema: generator.Schema(r.Request)}},
+			}
+		}
+		// the schemas are generated in a fixed order, so that the specification is stable
+		statuses := make([]int, 0, len(r.Responses))
+		for status := range r.Responses {
+			statuses = append(statuses, status)
+		}
+		sort.Ints(statuses)
+		for _, status := range statuses {
+			body := r.Responses[status]
+			response := openapi.Response{Description: http.StatusText(status)}
+			if body != nil {
+				contentType := gin.MIMEJSON
+				if r.Stream != "" && status == http.StatusOK {
+					contentType = r.Stream
+				}
+				response.Content = map[string]openapi.MediaType{contentType: {Schema: generator.Schema(body)}}
+			}
+			if r.Exports && status == http.StatusOK {
+				for _, contentType := range export.ContentTypes {
+					response.Content[contentType] = openapi.MediaType{Schema: &openapi.Schema{Type: "string", Format: "binary"}}
+				}
+			}
+			operation.Responses[strconv.Itoa(status)] = response
+		}
+		// the requests with an invalid API key are rejected, some features are restricted to the tiers including them,
+		// and every endpoint is rate limited
+		authStatuses := []int{http.StatusUnauthorized, http.StatusTooManyRequests}
+		if r.Feature != "" {
+			authStatuses = append(authStatuses, http.StatusForbidden)
+		}
+		var authBody interface{} = messageResponse{}
+		if strings.HasPrefix(r.Path, "/v2/") {
+			authBody = ErrorResponseV2{}
+		}
+		for _, status := range authStatuses {
+			if _, ok := operation.Responses[strconv.Itoa(status)]; ok {
+				continue
+			}
+			operation.Responses[strconv.Itoa(status)] = openapi.Response{
+				Description: http.StatusText(status),
+				Content:     map[string]openapi.MediaType{gin.MIMEJSON: {Schema: generator.Schema(authBody)}},
+			}
+		}
+		// the clients are authenticated before being rate limited
+		for status, response := range operation.Responses {
+			if status == strconv.Itoa(http.StatusUnauthorized) {
+				continue
+			}
+			response.Headers = rateLimitHeaders(status == strconv.Itoa(http.StatusTooManyRequests))
+			operation.Responses[status] = response
+		}
+
+		if document.Paths[path] == nil {
+			document.Paths[path] = openapi.PathItem{}
+		}
+		document.Paths[path][strings.ToLower(r.Method)] = operation
+	}
+
+	document.Components = generator.Components()
+	document.Components.SecuritySchemes = map[string]openapi.SecurityScheme{
+		"apiKey": {
+			Type:        "apiKey",
+			Description: "the API key of a programmatic client, which sets its rate limit, batch size and features",
+			Name:        apiKeyHeader,
+			In:          "header",
+		},
+	}
+	// the API key is optional, the anonymous clients have the lowest tier
+	document.Security = []openapi.SecurityRequirement{{}, {"apiKey": {}}}
+	return document
+}
+
+// operationID returns a camel case identifier of the operation, e.g. getScenariosId for GET /scenarios/:id
+func operationID(method, path string) string {
+	id := strings.ToLower(method)
+	for _, word := range strings.FieldsFunc(path, func(r rune) bool {
+		return r == '/' || r == '_' || r == '-' || r == ':'
+	}) {
+		id += strings.ToUpper(word[:1]) + word[1:]
+	}
+	return id
+}
+
+// documentedPaths returns the method and path of every documented operation, sorted, e.g. "GET /api/prices"
+func documentedPaths(document *openapi.Document) []string {
+	var paths []string
+	for path, item := range document.Paths {
+		for method := range item {
+			paths = append(paths, strings.ToUpper(method)+" "+path)
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// HandleGetOpenAPI returns the OpenAPI specification of the API
+func (api *API) HandleGetOpenAPI(c *gin.Context) {
+	c.JSON(http.StatusOK, OpenAPI())
+}
+
+// HandleGetDocs returns a Swagger UI page rendering the OpenAPI specification
+func (api *API) HandleGetDocs(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(`<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Istari Vision API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@`+swaggerUIVersion+`/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@`+swaggerUIVersion+`/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({url: "/api/openapi.json", dom_id: "#swagger-ui"});
+  </script>
+</body>
+</html>
+`))
+}
@@ -0,0 +1,51 @@
+package webservice
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"testing"
+
+	"github.com/silviutroscot/istari-vision/pkg/openapi"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPI(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	t.Run("every route is documented", func(t *testing.T) {
+		api := NewAPI(&service.Service{})
+		require.NoError(t, api.Setup())
+
+		var registered []string
+		for _, r := range api.engine.Routes() {
+			switch r.Path {
+			case "/health.txt", "/api/openapi.json", "/api/docs":
+				continue
+			}
+			path, _ := openapi.PathParameters(r.Path)
+			registered = append(registered, r.Method+" "+path)
+		}
+		sort.Strings(registered)
+
+		assert.Equal(t, registered, documentedPaths(OpenAPI()))
+	})
+
+	t.Run("the committed specification is up to date", func(t *testing.T) {
+		committed, err := os.ReadFile("../../docs/openapi.json")
+		require.NoError(t, err)
+
+		generated, err := json.MarshalIndent(OpenAPI(), "", "  ")
+		require.NoError(t, err)
+
+		assert.JSONEq(t, string(committed), string(generated), "run `make openapi` to update docs/openapi.json")
+	})
+
+	t.Run("operation IDs", func(t *testing.T) {
+		assert.Equal(t, "getScenariosId", operationID("GET", "/scenarios/:id"))
+		assert.Equal(t, "postCalculateProfit", operationID("POST", "/calculate_profit"))
+	})
+}
@@ -0,0 +1,106 @@
+package webservice
+
+import (
+	"time"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// the types below document the bodies of the responses, which the handlers build as gin.H
+
+// errorsResponse is the body of the responses to invalid requests
+type errorsResponse struct {
+	Errors []string `json:"errors"`
+	// FieldErrors are set for the invalid fields of the payloads
+	FieldErrors []FieldError `json:"field_errors,omitempty"`
+}
+
+// errorResponse is the body of the responses to requests which failed on the server side
+type errorResponse struct {
+	Error interface{} `json:"error"`
+}
+
+// messageResponse is the body of the responses explaining the status
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+type pricesResponse struct {
+	Prices service.Prices `json:"prices"`
+}
+
+type stakingProvidersResponse struct {
+	StakingProviders []fetcher.EgldStakingProvider `json:"staking_providers"`
+}
+
+type strategiesResponse struct {
+	Strategies []strategyDescription `json:"strategies"`
+}
+
+type walletResponse struct {
+	Wallet service.WalletHoldingsJSON `json:"wallet"`
+	// Input is a payload of the profit calculation investing the holdings of the wallet
+	Input CalculateStrategiesRequestPayload `json:"input"`
+}
+
+type calculateProfitResponse struct {
+	Results map[string]service.StrategyResultJSON `json:"results"`
+	Prices  service.Prices                        `json:"prices"`
+	Swap    *service.SwapResultJSON               `json:"swap,omitempty"`
+	// Unavailable are the requested strategies which were skipped because their market data is not available
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+type solveResponse struct {
+	Solution service.SolveResultJSON `json:"solution"`
+	Prices   service.Prices          `json:"prices"`
+}
+
+type sensitivityResponse struct {
+	Sensitivity service.SensitivityGridJSON `json:"sensitivity"`
+	Prices      service.Prices              `json:"prices"`
+}
+
+type backtestResponse struct {
+	Results map[string]service.StrategyResultJSON `json:"results"`
+}
+
+type compareResponse struct {
+	Comparison service.ComparisonJSON `json:"comparison"`
+	Prices     service.Prices         `json:"prices"`
+}
+
+type scenarioMetadataResponse struct {
+	ID        string                            `json:"id"`
+	CreatedAt time.Time                         `json:"created_at"`
+	ExpiresAt time.Time                         `json:"expires_at"`
+	Input     CalculateStrategiesRequestPayload `json:"input"`
+}
+
+type savedScenarioResponse struct {
+	calculateProfitResponse
+	Scenario scenarioMetadataResponse `json:"scenario"`
+	// Token authorizes deleting the scenario
+	Token string `json:"token"`
+}
+
+type scenarioResponse struct {
+	calculateProfitResponse
+	Scenario scenarioMetadataResponse `json:"scenario"`
+	// SavedPrices are the prices when the scenario was saved, only set when it is re-run
+	SavedPrices *service.Prices `json:"saved_prices,omitempty"`
+}
+
+type alertResponse struct {
+	Alert service.AlertRule `json:"alert"`
+}
+
+type alertDeliveriesResponse struct {
+	Alert      service.AlertRule       `json:"alert"`
+	Deliveries []service.AlertDelivery `json:"deliveries"`
+}
+
+type digestSubscriptionResponse struct {
+	Subscription service.DigestSubscription `json:"subscription"`
+}
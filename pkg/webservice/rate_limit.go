@@ -0,0 +1,170 @@
+package webservice
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/silviutroscot/istari-vision/pkg/log"
+)
+
+const (
+	// rateLimitKeyBase is the prefix of the Redis keys of the token buckets
+	rateLimitKeyBase = "rate_limit"
+	// rateLimitWindow is the period the limits are defined over; an empty bucket is refilled in a window
+	rateLimitWindow = time.Minute
+)
+
+// the headers describing the limit of the client, set on every response
+const (
+	rateLimitLimitHeader     = "X-RateLimit-Limit"
+	rateLimitRemainingHeader = "X-RateLimit-Remaining"
+	// rateLimitResetHeader is the number of seconds until the bucket is full again
+	rateLimitResetHeader = "X-RateLimit-Reset"
+	retryAfterHeader     = "Retry-After"
+)
+
+// tokenBucketScript takes a token from the bucket KEYS[1], holding up to ARGV[1] tokens and refilled continuously
+// with ARGV[1] tokens every ARGV[2] milliseconds. It returns 1 if the request is allowed, the remaining tokens, the
+// milliseconds until a token is available and the milliseconds until the bucket is full. The time of the Redis server
+// is used, so that the instances of the API share the same clock.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local rate = capacity / window
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated_at')
+local tokens = tonumber(bucket[1])
+local updatedAt = tonumber(bucket[2])
+if tokens == nil or updatedAt == nil then
+	tokens = capacity
+	updatedAt = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - updatedAt) * rate)
+
+local allowed = 0
+local retryAfter = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retryAfter = math.ceil((1 - tokens) / rate)
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tokens, 'updated_at', now)
+redis.call('PEXPIRE', KEYS[1], window)
+
+return {allowed, math.floor(tokens), retryAfter, math.ceil((capacity - tokens) / rate)}
+`)
+
+// rateLimit is the state of a token bucket after a request
+type rateLimit struct {
+	Allowed   bool
+	Limit     int64
+	Remaining int64
+	// RetryAfter is the time until a request is allowed, 0 if the request was allowed
+	RetryAfter time.Duration
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+}
+
+// takeToken takes a token from the bucket of the key, which is refilled with 'limit' tokens every rateLimitWindow
+func takeToken(ctx context.Context, cache *redis.Client, key string, limit int64) (rateLimit, error) {
+	values, err := tokenBucketScript.Run(ctx, cache, []string{key}, limit, rateLimitWindow.Milliseconds()).Int64Slice()
+	if err != nil {
+		return rateLimit{}, err
+	}
+
+	return rateLimit{
+		Allowed:    values[0] == 1,
+		Limit:      limit,
+		Remaining:  values[1],
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+		Reset:      time.Duration(values[3]) * time.Millisecond,
+	}, nil
+}
+
+// mostRestrictive returns the limit the client is the closest to exceed: a rejected limit, then the one with the
+// fewest remaining requests
+func mostRestrictive(limits ...rateLimit) rateLimit {
+	result := limits[0]
+	for _, limit := range limits[1:] {
+		switch {
+		case result.Allowed && !limit.Allowed:
+			result = limit
+		case result.Allowed == limit.Allowed && !limit.Allowed && limit.RetryAfter > result.RetryAfter:
+			result = limit
+		case result.Allowed == limit.Allowed && limit.Allowed && limit.Remaining < result.Remaining:
+			result = limit
+		}
+	}
+	return result
+}
+
+// writeRateLimitHeaders sets the headers describing the limit on the response
+func writeRateLimitHeaders(c *gin.Context, limit rateLimit) {
+	c.Header(rateLimitLimitHeader, strconv.FormatInt(limit.Limit, 10))
+	c.Header(rateLimitRemainingHeader, strconv.FormatInt(limit.Remaining, 10))
+	c.Header(rateLimitResetHeader, strconv.FormatInt(ceilSeconds(limit.Reset), 10))
+	if !limit.Allowed {
+		c.Header(retryAfterHeader, strconv.FormatInt(ceilSeconds(limit.RetryAfter), 10))
+	}
+}
+
+// ceilSeconds returns the duration in seconds, rounded up
+func ceilSeconds(d time.Duration) int64 {
+	return int64((d + time.Second - 1) / time.Second)
+}
+
+// rateLimitClient returns the identifier of the client of the request: its API key, or its IP address if it is
+// anonymous; the IP address is only read from the forwarding headers set by the trusted proxies
+func rateLimitClient(c *gin.Context) string {
+	if apiKey := clientAPIKey(c); apiKey != nil {
+		return "key:" + apiKey.ID
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// handleRateLimiting is a middleware limiting the requests of each client to the requests per minute of its tier and,
+// if routeLimit is not 0, the requests to the route to routeLimit per minute; the limits are token buckets, so the
+// requests can be spread over the minute or sent in a burst
+func (api *API) handleRateLimiting(routeLimit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx, cc := context.WithTimeout(context.Background(), time.Second*5)
+		defer cc()
+
+		client := rateLimitClient(c)
+		limits := make([]rateLimit, 0, 2)
+
+		limit, err := takeToken(ctx, api.service.Cache, rateLimitKeyBase+":"+client, clientTier(c).RequestsPerMinute)
+		if err != nil {
+			log.Error("error rate limiting the client %s: %s", client, err)
+			abortWithMessage(c, http.StatusInternalServerError, ErrorCodeInternal, "error rate limiting the request")
+			return
+		}
+		limits = append(limits, limit)
+
+		if routeLimit != 0 {
+			key := rateLimitKeyBase + ":" + c.Request.Method + ":" + c.FullPath() + ":" + client
+			limit, err := takeToken(ctx, api.service.Cache, key, routeLimit)
+			if err != nil {
+				log.Error("error rate limiting the client %s on %s: %s", client, c.FullPath(), err)
+				abortWithMessage(c, http.StatusInternalServerError, ErrorCodeInternal, "error rate limiting the request")
+				return
+			}
+			limits = append(limits, limit)
+		}
+
+		limit = mostRestrictive(limits...)
+		writeRateLimitHeaders(c, limit)
+		if !limit.Allowed {
+			abortWithMessage(c, http.StatusTooManyRequests, ErrorCodeRateLimited, "too many requests")
+		}
+	}
+}
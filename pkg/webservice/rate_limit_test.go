@@ -0,0 +1,94 @@
+package webservice
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMostRestrictive(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	tier := rateLimit{Allowed: true, Limit: 200, Remaining: 150}
+	route := rateLimit{Allowed: true, Limit: 20, Remaining: 5}
+	rejected := rateLimit{Allowed: false, Limit: 20, RetryAfter: 3 * time.Second}
+	rejectedLonger := rateLimit{Allowed: false, Limit: 200, RetryAfter: 10 * time.Second}
+
+	assert.Equal(t, tier, mostRestrictive(tier))
+	assert.Equal(t, route, mostRestrictive(tier, route))
+	assert.Equal(t, route, mostRestrictive(route, tier))
+	assert.Equal(t, rejected, mostRestrictive(tier, rejected))
+	assert.Equal(t, rejected, mostRestrictive(rejected, route))
+	assert.Equal(t, rejectedLonger, mostRestrictive(rejected, rejectedLonger))
+}
+
+func TestWriteRateLimitHeaders(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	writeRateLimitHeaders(c, rateLimit{Allowed: true, Limit: 200, Remaining: 199, Reset: 300 * time.Millisecond})
+
+	assert.Equal(t, "200", recorder.Header().Get(rateLimitLimitHeader))
+	assert.Equal(t, "199", recorder.Header().Get(rateLimitRemainingHeader))
+	assert.Equal(t, "1", recorder.Header().Get(rateLimitResetHeader))
+	assert.Empty(t, recorder.Header().Get(retryAfterHeader))
+
+	recorder = httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(recorder)
+	writeRateLimitHeaders(c, rateLimit{Allowed: false, Limit: 20, RetryAfter: 2001 * time.Millisecond, Reset: time.Minute})
+
+	assert.Equal(t, "0", recorder.Header().Get(rateLimitRemainingHeader))
+	assert.Equal(t, "60", recorder.Header().Get(rateLimitResetHeader))
+	assert.Equal(t, "3", recorder.Header().Get(retryAfterHeader))
+}
+
+func TestRateLimitClient(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	engine := gin.New()
+	require.NoError(t, engine.SetTrustedProxies([]string{"10.0.0.1"}))
+
+	var client string
+	engine.GET("/api/prices", func(c *gin.Context) {
+		if c.GetHeader(apiKeyHeader) != "" {
+			c.Set(apiKeyContextKey, &service.APIKey{ID: "abc"})
+		}
+		client = rateLimitClient(c)
+	})
+
+	for _, tt := range []struct {
+		name          string
+		remoteAddr    string
+		forwardedFor  string
+		apiKey        string
+		expectedValue string
+	}{
+		{"direct connection", "203.0.113.7:1234", "", "", "ip:203.0.113.7"},
+		{"spoofed header from an untrusted address", "203.0.113.7:1234", "198.51.100.1", "", "ip:203.0.113.7"},
+		{"header set by the trusted proxy", "10.0.0.1:1234", "198.51.100.1", "", "ip:198.51.100.1"},
+		{"header spoofed through the trusted proxy", "10.0.0.1:1234", "192.0.2.1, 198.51.100.1", "", "ip:198.51.100.1"},
+		{"API key", "203.0.113.7:1234", "", "iv_key", "key:abc"},
+	} {
+		request := httptest.NewRequest(http.MethodGet, "/api/prices", nil)
+		request.RemoteAddr = tt.remoteAddr
+		if tt.forwardedFor != "" {
+			request.Header.Set("X-Forwarded-For", tt.forwardedFor)
+		}
+		if tt.apiKey != "" {
+			request.Header.Set(apiKeyHeader, tt.apiKey)
+		}
+		engine.ServeHTTP(httptest.NewRecorder(), request)
+
+		assert.Equal(t, tt.expectedValue, client, tt.name)
+	}
+}
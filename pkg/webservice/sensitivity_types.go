@@ -0,0 +1,87 @@
+package webservice
+
+import (
+	"fmt"
+
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// SensitivityAxisPayload describes an input varied by the sensitivity analysis
+type SensitivityAxisPayload struct {
+	// Parameter is "egld-price", "mex-price", "egld-apr", "duration" or "redelegation-interval"
+	Parameter string `json:"parameter"`
+	Min       string `json:"min"`
+	Max       string `json:"max"`
+	Steps     int    `json:"steps"`
+}
+
+// SensitivityRequestPayload is the payload of the sensitivity analysis: the inputs of a profit calculation and the two
+// inputs to be varied
+type SensitivityRequestPayload struct {
+	CalculateStrategiesRequestPayload
+
+	X SensitivityAxisPayload `json:"x"`
+	Y SensitivityAxisPayload `json:"y"`
+}
+
+// sensitivityFields maps the parameters varied by the sensitivity analysis to the fields of the payload they replace
+var sensitivityFields = map[service.SensitivityParameter][]string{
+	service.SensitivityEgldPrice:            {"egld-price-target"},
+	service.SensitivityMexPrice:             {"mex-price-target"},
+	service.SensitivityDuration:             {"target-date-days"},
+	service.SensitivityRedelegationInterval: {"redelegation-interval"},
+}
+
+// ToSensitivityInput returns the parsed inputs of the profit calculation and of the sensitivity analysis, and a list
+// of errors for invalid fields
+func (payload *SensitivityRequestPayload) ToSensitivityInput() (*service.StrategiesInput, service.SensitivityInput, []error) {
+	var errs []error
+
+	// the varied inputs don't have to be provided
+	var variedFields []string
+	for _, axis := range []SensitivityAxisPayload{payload.X, payload.Y} {
+		variedFields = append(variedFields, sensitivityFields[service.SensitivityParameter(axis.Parameter)]...)
+	}
+
+	strategiesInput, inputErrs := payload.CalculateStrategiesRequestPayload.toStrategiesInput(variedFields...)
+	errs = append(errs, inputErrs...)
+
+	v := newValidator()
+	x := payload.X.toSensitivityAxis(v, "x")
+	y := payload.Y.toSensitivityAxis(v, "y")
+	errs = append(errs, v.Errors()...)
+
+	sensitivityInput := service.SensitivityInput{X: x, Y: y}
+	if len(errs) != 0 {
+		return nil, sensitivityInput, errs
+	}
+
+	return strategiesInput, sensitivityInput, nil
+}
+
+// toSensitivityAxis returns the parsed axis, whose fields are validated as "<name>.<field>"
+func (axis *SensitivityAxisPayload) toSensitivityAxis(v *validator, name string) service.SensitivityAxis {
+	result := service.SensitivityAxis{
+		Parameter: service.SensitivityParameter(axis.Parameter),
+		Steps:     axis.Steps,
+	}
+
+	v.OneOf(name+".parameter", axis.Parameter, string(service.SensitivityEgldPrice), string(service.SensitivityMexPrice),
+		string(service.SensitivityEgldAPR), string(service.SensitivityDuration), string(service.SensitivityRedelegationInterval))
+	v.IntRange(name+".steps", axis.Steps, 1, service.MaxSensitivitySteps)
+
+	// the numbers of days are bounded like the inputs of the calculation
+	rules := []decimalRule{minDecimal(0)}
+	if result.Parameter.InDays() {
+		rules = []decimalRule{minDecimal(1), maxDecimal(service.MaxInvestmentDurationInDays)}
+	}
+	result.Min = v.Decimal(name+".min", axis.Min, true, rules...)
+	result.Max = v.Decimal(name+".max", axis.Max, true, rules...)
+
+	if result.Min != nil && result.Max != nil && result.Min.Cmp(result.Max) > 0 {
+		v.fail(name+".min", ValidationLessOrEqualField, fmt.Sprintf("the value must be at most the value of '%s.max' (%s)", name, axis.Max),
+			map[string]string{"field": name + ".max", "other": axis.Max})
+	}
+
+	return result
+}
@@ -0,0 +1,68 @@
+package webservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSensitivityRequestPayload_ToSensitivityInput(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// validSensitivityPayload returns a payload varying the EGLD price and the duration, which passes the validation
+	validSensitivityPayload := func() SensitivityRequestPayload {
+		payload := SensitivityRequestPayload{
+			CalculateStrategiesRequestPayload: validPayload(),
+			X:                                 SensitivityAxisPayload{Parameter: "egld-price", Min: "100", Max: "300", Steps: 3},
+			Y:                                 SensitivityAxisPayload{Parameter: "duration", Min: "30", Max: "365", Steps: 2},
+		}
+		payload.EgldTargetPrice = ""
+		return payload
+	}
+
+	t.Run("valid payload", func(t *testing.T) {
+		payload := validSensitivityPayload()
+		input, sensitivityInput, errs := payload.ToSensitivityInput()
+		require.Nil(t, errs)
+		require.NotNil(t, input)
+		assert.Equal(t, "300", sensitivityInput.X.Max.String())
+		assert.Equal(t, 2, sensitivityInput.Y.Steps)
+	})
+
+	for _, tt := range []struct {
+		name   string
+		modify func(payload *SensitivityRequestPayload)
+		field  string
+		code   string
+		params map[string]string
+	}{
+		{"unknown parameter", func(p *SensitivityRequestPayload) {
+			p.X.Parameter = "yolo"
+			p.EgldTargetPrice = "100"
+		}, "x.parameter", ValidationOneOf, map[string]string{"value": "yolo", "values": "egld-price,mex-price,egld-apr,duration,redelegation-interval"}},
+		{"too many steps", func(p *SensitivityRequestPayload) { p.X.Steps = 26 }, "x.steps", ValidationMax, map[string]string{"max": "25"}},
+		{"missing bound", func(p *SensitivityRequestPayload) { p.X.Min = "" }, "x.min", ValidationRequired, nil},
+		{"negative price", func(p *SensitivityRequestPayload) { p.X.Min = "-1" }, "x.min", ValidationMin, map[string]string{"min": "0"}},
+		{"duration too long", func(p *SensitivityRequestPayload) { p.Y.Max = "1e30" }, "y.max", ValidationMax, map[string]string{"max": "3650"}},
+		{"duration of 0 days", func(p *SensitivityRequestPayload) { p.Y.Min = "0" }, "y.min", ValidationMin, map[string]string{"min": "1"}},
+		{"inverted range", func(p *SensitivityRequestPayload) { p.X.Min = "400" }, "x.min", ValidationLessOrEqualField, map[string]string{"field": "x.max", "other": "300"}},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			payload := validSensitivityPayload()
+			tt.modify(&payload)
+
+			input, _, errs := payload.ToSensitivityInput()
+			assert.Nil(t, input)
+			require.Len(t, errs, 1)
+
+			fieldErrs := fieldErrors(errs)
+			require.Len(t, fieldErrs, 1)
+			assert.Equal(t, tt.field, fieldErrs[0].Field)
+			assert.Equal(t, tt.code, fieldErrs[0].Code)
+			assert.Equal(t, tt.params, fieldErrs[0].Params)
+		})
+	}
+}
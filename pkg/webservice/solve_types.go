@@ -0,0 +1,95 @@
+package webservice
+
+import (
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// SolveRequestPayload is the payload of the solver: the inputs of a profit calculation, the strategy and the target
+// its result has to reach
+type SolveRequestPayload struct {
+	CalculateStrategiesRequestPayload
+
+	// Strategy and Token select the strategy result to be solved, e.g. "stake" and "egld"
+	Strategy string `json:"strategy"`
+	Token    string `json:"token"`
+
+	// SolveFor is the input searched by the solver: "egld-price", "mex-price" or "duration"; the value of the input in
+	// the payload is not used
+	SolveFor string `json:"solve-for"`
+
+	// TargetMetric is "usd-balance", "roi" or "parity"; TargetValue is required for "roi", and defaults to the USD
+	// value of the portfolio for "usd-balance"
+	TargetMetric string `json:"target-metric"`
+	TargetValue  string `json:"target-value"`
+
+	// OtherStrategy and OtherToken select the strategy result compared with for the "parity" metric
+	OtherStrategy string `json:"other-strategy"`
+	OtherToken    string `json:"other-token"`
+
+	// Min and Max are the optional bounds of the searched input
+	Min string `json:"min"`
+	Max string `json:"max"`
+}
+
+// solvedFields maps the inputs searched by the solver to the fields of the payload they replace
+var solvedFields = map[service.SolveVariable][]string{
+	service.SolveForEgldPrice: {"egld-price-target"},
+	service.SolveForMexPrice:  {"mex-price-target"},
+	service.SolveForDuration:  {"target-date-days"},
+}
+
+// solveTokens are the names of the tokens a solved strategy result can be selected by
+var solveTokens = []string{service.TokenTypeEgld.String(), service.TokenTypeMex.String(), service.TokenTypeEgldMexLP.String()}
+
+// ToSolveInput returns the parsed inputs of the profit calculation and of the solver, and a list of errors for
+// invalid fields
+func (payload *SolveRequestPayload) ToSolveInput() (*service.StrategiesInput, service.SolveInput, []error) {
+	var errs []error
+
+	solveInput := service.SolveInput{
+		Strategy:       payload.Strategy,
+		TokenType:      service.ParseTokenType(payload.Token),
+		Variable:       service.SolveVariable(payload.SolveFor),
+		Metric:         service.SolveMetric(payload.TargetMetric),
+		OtherStrategy:  payload.OtherStrategy,
+		OtherTokenType: service.ParseTokenType(payload.OtherToken),
+	}
+
+	// the solved input doesn't have to be provided
+	strategiesInput, inputErrs := payload.CalculateStrategiesRequestPayload.toStrategiesInput(solvedFields[solveInput.Variable]...)
+	errs = append(errs, inputErrs...)
+
+	v := newValidator()
+	v.Required("strategy", payload.Strategy)
+	v.OneOf("token", payload.Token, solveTokens...)
+	v.OneOf("target-metric", payload.TargetMetric,
+		string(service.SolveMetricUsdBalance), string(service.SolveMetricROI), string(service.SolveMetricParity))
+	if solveInput.Metric == service.SolveMetricParity {
+		v.Required("other-strategy", payload.OtherStrategy)
+		v.OneOf("other-token", payload.OtherToken, solveTokens...)
+	}
+	solveInput.Target = v.Decimal("target-value", payload.TargetValue, solveInput.Metric == service.SolveMetricROI)
+
+	// the durations are whole days within the calculated range, and the prices are positive
+	var boundRules []decimalRule
+	switch solveInput.Variable {
+	case service.SolveForEgldPrice, service.SolveForMexPrice:
+		boundRules = []decimalRule{greaterThanDecimal(0)}
+	case service.SolveForDuration:
+		boundRules = []decimalRule{minDecimal(1), maxDecimal(service.MaxInvestmentDurationInDays)}
+	default:
+		v.OneOf("solve-for", payload.SolveFor,
+			string(service.SolveForEgldPrice), string(service.SolveForMexPrice), string(service.SolveForDuration))
+	}
+	if boundRules != nil {
+		solveInput.Min = v.Decimal("min", payload.Min, false, boundRules...)
+		solveInput.Max = v.Decimal("max", payload.Max, false, boundRules...)
+	}
+	errs = append(errs, v.Errors()...)
+
+	if len(errs) != 0 {
+		return nil, solveInput, errs
+	}
+
+	return strategiesInput, solveInput, nil
+}
@@ -0,0 +1,85 @@
+package webservice
+
+import (
+	"testing"
+
+	"github.com/silviutroscot/istari-vision/pkg/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSolveRequestPayload_ToSolveInput(t *testing.T) {
+	// allow the tests to run in parallel
+	t.Parallel()
+
+	// validSolvePayload returns a payload solving the duration, which passes the validation
+	validSolvePayload := func() SolveRequestPayload {
+		payload := SolveRequestPayload{
+			CalculateStrategiesRequestPayload: validPayload(),
+			Strategy:                          "stake",
+			Token:                             "egld",
+			SolveFor:                          "duration",
+			TargetMetric:                      "roi",
+			TargetValue:                       "50",
+			Min:                               "30",
+			Max:                               "3650",
+		}
+		payload.InvestmentDurationInDays = 0
+		return payload
+	}
+
+	t.Run("valid payload", func(t *testing.T) {
+		payload := validSolvePayload()
+		input, solveInput, errs := payload.ToSolveInput()
+		require.Nil(t, errs)
+		require.NotNil(t, input)
+		assert.Equal(t, service.SolveForDuration, solveInput.Variable)
+		assert.Equal(t, "30", solveInput.Min.String())
+		assert.Equal(t, "3650", solveInput.Max.String())
+	})
+
+	for _, tt := range []struct {
+		name   string
+		modify func(payload *SolveRequestPayload)
+		field  string
+		code   string
+		params map[string]string
+	}{
+		{"missing strategy", func(p *SolveRequestPayload) { p.Strategy = "" }, "strategy", ValidationRequired, nil},
+		{"unknown token", func(p *SolveRequestPayload) { p.Token = "btc" }, "token", ValidationOneOf, map[string]string{"value": "btc", "values": "egld,mex,egld_mex_lp"}},
+		{"unknown variable", func(p *SolveRequestPayload) {
+			p.SolveFor = "apr"
+			p.InvestmentDurationInDays = 365
+		}, "solve-for", ValidationOneOf, map[string]string{"value": "apr", "values": "egld-price,mex-price,duration"}},
+		{"missing target value", func(p *SolveRequestPayload) { p.TargetValue = "" }, "target-value", ValidationRequired, nil},
+		{"duration bound of 0 days", func(p *SolveRequestPayload) { p.Min = "0" }, "min", ValidationMin, map[string]string{"min": "1"}},
+		{"duration bound too long", func(p *SolveRequestPayload) { p.Max = "1e30" }, "max", ValidationMax, map[string]string{"max": "3650"}},
+		{"negative price bound", func(p *SolveRequestPayload) {
+			p.SolveFor = "egld-price"
+			p.InvestmentDurationInDays = 365
+			p.EgldTargetPrice = ""
+			p.Min = "-1"
+			p.Max = ""
+		}, "min", ValidationGreaterThan, map[string]string{"min": "0"}},
+		{"missing other token", func(p *SolveRequestPayload) {
+			p.TargetMetric = "parity"
+			p.OtherStrategy = "hold"
+		}, "other-token", ValidationOneOf, map[string]string{"value": "", "values": "egld,mex,egld_mex_lp"}},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			payload := validSolvePayload()
+			tt.modify(&payload)
+
+			input, _, errs := payload.ToSolveInput()
+			assert.Nil(t, input)
+			require.Len(t, errs, 1)
+
+			fieldErrs := fieldErrors(errs)
+			require.Len(t, fieldErrs, 1)
+			assert.Equal(t, tt.field, fieldErrs[0].Field)
+			assert.Equal(t, tt.code, fieldErrs[0].Code)
+			assert.Equal(t, tt.params, fieldErrs[0].Params)
+		})
+	}
+}
@@ -0,0 +1,384 @@
+package webservice
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// the units of the amounts of the v2 responses
+const (
+	UnitEgld    = "EGLD"
+	UnitMex     = "MEX"
+	UnitUsd     = "USD"
+	UnitPercent = "percent"
+	UnitDays    = "days"
+	// UnitLP is the unit of the tokens of the EGLD-MEX liquidity pool
+	UnitLP = "LP"
+)
+
+// the codes of the errors of the v2 responses
+const (
+	ErrorCodeInvalidRequest = "invalid_request"
+	ErrorCodeInvalidField   = "invalid_field"
+	ErrorCodeNotFound       = "not_found"
+	ErrorCodeUnprocessable  = "unprocessable"
+	ErrorCodeUnavailable    = "unavailable"
+	ErrorCodeUpstream       = "upstream_error"
+	ErrorCodeInternal       = "internal_error"
+	ErrorCodeUnauthorized   = "unauthorized"
+	ErrorCodeForbidden      = "forbidden"
+	ErrorCodeRateLimited    = "rate_limited"
+)
+
+// ErrorV2 is the error of the v2 responses; Details lists the individual errors, e.g. one per invalid field
+type ErrorV2 struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Field is the JSON name of the field of the request which caused the error, if any
+	Field string `json:"field,omitempty"`
+	// Params are the parameters of the message, e.g. the bounds of the value, for it to be localized
+	Params  map[string]string `json:"params,omitempty"`
+	Details []ErrorV2         `json:"details,omitempty"`
+}
+
+// ErrorResponseV2 is the body of every v2 response with an error status
+type ErrorResponseV2 struct {
+	Error ErrorV2 `json:"error"`
+}
+
+// Amount is a decimal number formatted as a string, to keep its precision, with its unit
+type Amount struct {
+	Value string `json:"value"`
+	Unit  string `json:"unit"`
+}
+
+// amount returns the Amount of the value, or nil if the value is empty
+func amount(value, unit string) *Amount {
+	if value == "" {
+		return nil
+	}
+	return &Amount{Value: value, Unit: unit}
+}
+
+// PricesV2 are the USD prices of the tokens
+type PricesV2 struct {
+	EGLD Amount `json:"egld"`
+	MEX  Amount `json:"mex"`
+}
+
+func newPricesV2(prices service.Prices) PricesV2 {
+	return PricesV2{
+		EGLD: Amount{Value: prices.EGLD, Unit: UnitUsd},
+		MEX:  Amount{Value: prices.MEX, Unit: UnitUsd},
+	}
+}
+
+type PricesResponseV2 struct {
+	Prices PricesV2 `json:"prices"`
+}
+
+// StakingProviderV2 is an EGLD staking provider
+type StakingProviderV2 struct {
+	Identity string `json:"identity"`
+	// Address is the address of the delegation contract
+	Address    string `json:"address"`
+	APR        Amount `json:"apr"`
+	ServiceFee Amount `json:"service_fee"`
+}
+
+type StakingProvidersResponseV2 struct {
+	StakingProviders []StakingProviderV2 `json:"staking_providers"`
+}
+
+func newStakingProvidersResponseV2(providers []fetcher.EgldStakingProvider) StakingProvidersResponseV2 {
+	response := StakingProvidersResponseV2{StakingProviders: make([]StakingProviderV2, 0, len(providers))}
+	for _, provider := range providers {
+		response.StakingProviders = append(response.StakingProviders, StakingProviderV2{
+			Identity:   provider.Identity,
+			Address:    provider.Address,
+			APR:        Amount{Value: formatFloat(provider.APR), Unit: UnitPercent},
+			ServiceFee: Amount{Value: formatFloat(provider.ServiceFee), Unit: UnitPercent},
+		})
+	}
+	return response
+}
+
+type StrategiesResponseV2 struct {
+	Strategies []strategyDescription `json:"strategies"`
+}
+
+// BalanceV2 is a value expressed in each token and in USD
+type BalanceV2 struct {
+	EGLD Amount `json:"egld"`
+	MEX  Amount `json:"mex"`
+	USD  Amount `json:"usd"`
+}
+
+// ImpermanentLossV2 is the impermanent loss of a liquidity pool strategy
+type ImpermanentLossV2 struct {
+	HoldBalance Amount `json:"hold_balance"`
+	Loss        Amount `json:"loss"`
+	Percentage  Amount `json:"percentage"`
+}
+
+// UnbondingV2 is the exit from a strategy with an unbonding period
+type UnbondingV2 struct {
+	Period    Amount `json:"period"`
+	StartDate string `json:"start_date,omitempty"`
+	LiquidAt  string `json:"liquid_at,omitempty"`
+	// LostYield is the value of the rewards not received because the exit starts before the target date
+	LostYield *Amount `json:"lost_yield,omitempty"`
+}
+
+// LockedRewardsV2 are the MEX rewards still locked at the target date
+type LockedRewardsV2 struct {
+	LockedMexAtTarget Amount            `json:"locked_mex_at_target"`
+	SellableBalance   Amount            `json:"sellable_balance"`
+	UnlockSchedule    []UnlockTrancheV2 `json:"unlock_schedule"`
+}
+
+type UnlockTrancheV2 struct {
+	Date   string `json:"date"`
+	Amount Amount `json:"amount"`
+}
+
+// ContributionsV2 are the periodic purchases made after the initial investment
+type ContributionsV2 struct {
+	Count            int    `json:"count"`
+	TotalTokens      Amount `json:"total_tokens"`
+	TotalContributed Amount `json:"total_contributed"`
+	AverageCostBasis Amount `json:"average_cost_basis"`
+	NetProfit        Amount `json:"net_profit"`
+}
+
+type APRPeriodV2 struct {
+	StartDate     string  `json:"start_date"`
+	EndDate       string  `json:"end_date"`
+	APR           Amount  `json:"apr"`
+	InflationRate *Amount `json:"inflation_rate,omitempty"`
+	StakingRatio  *Amount `json:"staking_ratio,omitempty"`
+}
+
+// StrategyResultV2 is the result of a strategy applied on a token
+type StrategyResultV2 struct {
+	Strategy string `json:"strategy"`
+	Token    string `json:"token"`
+
+	TotalBalance BalanceV2 `json:"total_balance"`
+	Profit       BalanceV2 `json:"profit"`
+	ROI          Amount    `json:"roi"`
+
+	ImpermanentLoss *ImpermanentLossV2 `json:"impermanent_loss,omitempty"`
+	Unbonding       UnbondingV2        `json:"unbonding"`
+	LockedRewards   *LockedRewardsV2   `json:"locked_rewards,omitempty"`
+	Contributions   *ContributionsV2   `json:"contributions,omitempty"`
+	AssumedAPRCurve []APRPeriodV2      `json:"assumed_apr_curve,omitempty"`
+}
+
+// SwapV2 is the swap of a part of the investment into the other token
+type SwapV2 struct {
+	AmountIn  Amount `json:"amount_in"`
+	AmountOut Amount `json:"amount_out"`
+	Fee       Amount `json:"fee"`
+	FeeInUsd  Amount `json:"fee_in_usd"`
+	// the prices are amounts of the token out per token in
+	SpotPrice      string `json:"spot_price"`
+	EffectivePrice string `json:"effective_price"`
+	PriceImpact    Amount `json:"price_impact"`
+}
+
+type CalculateProfitResponseV2 struct {
+	// Results are ordered by token and strategy
+	Results []StrategyResultV2 `json:"results"`
+	Prices  PricesV2           `json:"prices"`
+	Swap    *SwapV2            `json:"swap,omitempty"`
+	// Unavailable are the requested strategies which were skipped because their market data is not available
+	Unavailable []string `json:"unavailable,omitempty"`
+}
+
+// newCalculateProfitResponseV2 returns the typed response of the results of the profit calculation
+func newCalculateProfitResponseV2(input *service.StrategiesInput, results map[string]service.StrategyResultJSON, prices service.Prices) CalculateProfitResponseV2 {
+	response := CalculateProfitResponseV2{
+		Results: make([]StrategyResultV2, 0, len(results)),
+		Prices:  newPricesV2(prices),
+	}
+
+	keys := make([]string, 0, len(results))
+	for key := range results {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		response.Results = append(response.Results, newStrategyResultV2(key, results[key], input))
+	}
+
+	if input.Swap != nil {
+		swap := input.Swap.MarshallToJSON()
+		response.Swap = &SwapV2{
+			AmountIn:       Amount{Value: swap.AmountIn, Unit: tokenUnit(swap.TokenIn)},
+			AmountOut:      Amount{Value: swap.AmountOut, Unit: tokenUnit(swap.TokenOut)},
+			Fee:            Amount{Value: swap.Fee, Unit: tokenUnit(swap.TokenIn)},
+			FeeInUsd:       Amount{Value: swap.FeeInUsd, Unit: UnitUsd},
+			SpotPrice:      swap.SpotPrice,
+			EffectivePrice: swap.EffectivePrice,
+			PriceImpact:    Amount{Value: swap.PriceImpactPercentage, Unit: UnitPercent},
+		}
+	}
+
+	return response
+}
+
+func newStrategyResultV2(key string, result service.StrategyResultJSON, input *service.StrategiesInput) StrategyResultV2 {
+	token, strategy := splitResultKey(key)
+
+	resultV2 := StrategyResultV2{
+		Strategy: strategy,
+		Token:    token,
+		TotalBalance: BalanceV2{
+			EGLD: Amount{Value: result.TotalBalanceInEgld, Unit: UnitEgld},
+			MEX:  Amount{Value: result.TotalBalanceInMex, Unit: UnitMex},
+			USD:  Amount{Value: result.TotalBalanceInUsd, Unit: UnitUsd},
+		},
+		Profit: BalanceV2{
+			EGLD: Amount{Value: result.ProfitInEgld, Unit: UnitEgld},
+			MEX:  Amount{Value: result.ProfitInMex, Unit: UnitMex},
+			USD:  Amount{Value: result.ProfitInUSD, Unit: UnitUsd},
+		},
+		ROI: Amount{Value: result.ROI, Unit: UnitPercent},
+		Unbonding: UnbondingV2{
+			Period:    Amount{Value: formatInt(result.UnbondingPeriodInDays), Unit: UnitDays},
+			StartDate: result.UnbondingStartDate,
+			LiquidAt:  result.LiquidAt,
+			LostYield: amount(result.LostYieldInUsd, UnitUsd),
+		},
+	}
+
+	if result.HoldBalanceInUsd != "" {
+		resultV2.ImpermanentLoss = &ImpermanentLossV2{
+			HoldBalance: Amount{Value: result.HoldBalanceInUsd, Unit: UnitUsd},
+			Loss:        Amount{Value: result.ImpermanentLossInUsd, Unit: UnitUsd},
+			Percentage:  Amount{Value: result.ImpermanentLossPercentage, Unit: UnitPercent},
+		}
+	}
+
+	if result.LockedMexAtTarget != "" {
+		resultV2.LockedRewards = &LockedRewardsV2{
+			LockedMexAtTarget: Amount{Value: result.LockedMexAtTarget, Unit: UnitMex},
+			SellableBalance:   Amount{Value: result.SellableBalanceInUsd, Unit: UnitUsd},
+			UnlockSchedule:    make([]UnlockTrancheV2, 0, len(result.UnlockSchedule)),
+		}
+		for _, tranche := range result.UnlockSchedule {
+			resultV2.LockedRewards.UnlockSchedule = append(resultV2.LockedRewards.UnlockSchedule, UnlockTrancheV2{
+				Date:   tranche.Date,
+				Amount: Amount{Value: tranche.AmountInMex, Unit: UnitMex},
+			})
+		}
+	}
+
+	if result.TotalContributedInUsd != "" && input.Contribution != nil {
+		resultV2.Contributions = &ContributionsV2{
+			Count:            result.ContributionsCount,
+			TotalTokens:      Amount{Value: result.TotalContributedInTokens, Unit: tokenUnit(input.Contribution.TokenType.String())},
+			TotalContributed: Amount{Value: result.TotalContributedInUsd, Unit: UnitUsd},
+			AverageCostBasis: Amount{Value: result.AverageCostBasis, Unit: UnitUsd},
+			NetProfit:        Amount{Value: result.NetProfitInUsd, Unit: UnitUsd},
+		}
+	}
+
+	for _, period := range result.AssumedAPRCurve {
+		resultV2.AssumedAPRCurve = append(resultV2.AssumedAPRCurve, APRPeriodV2{
+			StartDate:     period.StartDate,
+			EndDate:       period.EndDate,
+			APR:           Amount{Value: period.APR, Unit: UnitPercent},
+			InflationRate: amount(period.InflationRate, UnitPercent),
+			StakingRatio:  amount(period.StakingRatio, UnitPercent),
+		})
+	}
+
+	return resultV2
+}
+
+// splitResultKey returns the token and the strategy of a key of the strategies results, '<token>_<strategy>'
+func splitResultKey(key string) (string, string) {
+	// the longest names first, as the LP token name starts with the EGLD one
+	for _, tokenType := range []service.TokenType{service.TokenTypeEgldMexLP, service.TokenTypeEgld, service.TokenTypeMex} {
+		if strings.HasPrefix(key, tokenType.String()+"_") {
+			return tokenType.String(), strings.TrimPrefix(key, tokenType.String()+"_")
+		}
+	}
+	return "", key
+}
+
+// tokenUnit returns the unit of the amounts of the token named name
+func tokenUnit(name string) string {
+	switch service.ParseTokenType(name) {
+	case service.TokenTypeEgld:
+		return UnitEgld
+	case service.TokenTypeMex:
+		return UnitMex
+	default:
+		return strings.ToUpper(name)
+	}
+}
+
+type WalletResponseV2 struct {
+	Address     string               `json:"address"`
+	EgldBalance Amount               `json:"egld_balance"`
+	MexBalance  Amount               `json:"mex_balance"`
+	LockedMex   Amount               `json:"locked_mex_balance"`
+	LPBalance   Amount               `json:"lp_balance"`
+	LPValue     *Amount              `json:"lp_value,omitempty"`
+	Delegations []WalletDelegationV2 `json:"delegations"`
+	// PendingRewards are the claimable delegation rewards
+	PendingRewards Amount `json:"pending_rewards"`
+	// Input is a payload of the profit calculation investing the holdings of the wallet
+	Input CalculateStrategiesRequestPayload `json:"input"`
+}
+
+type WalletDelegationV2 struct {
+	Contract         string `json:"contract"`
+	StakingProvider  string `json:"staking_provider"`
+	APR              Amount `json:"apr"`
+	ActiveStake      Amount `json:"active_stake"`
+	ClaimableRewards Amount `json:"claimable_rewards"`
+	Unbonding        Amount `json:"unbonding"`
+}
+
+func newWalletResponseV2(holdings service.WalletHoldingsJSON, input CalculateStrategiesRequestPayload) WalletResponseV2 {
+	response := WalletResponseV2{
+		Address:        holdings.Address,
+		EgldBalance:    Amount{Value: holdings.EgldBalance, Unit: UnitEgld},
+		MexBalance:     Amount{Value: holdings.MexBalance, Unit: UnitMex},
+		LockedMex:      Amount{Value: holdings.LockedMexBalance, Unit: UnitMex},
+		LPBalance:      Amount{Value: holdings.LPBalance, Unit: UnitLP},
+		LPValue:        amount(holdings.LPValueInUsd, UnitUsd),
+		Delegations:    make([]WalletDelegationV2, 0, len(holdings.Delegations)),
+		PendingRewards: Amount{Value: holdings.PendingRewardsInEgld, Unit: UnitEgld},
+		Input:          input,
+	}
+	for _, delegation := range holdings.Delegations {
+		response.Delegations = append(response.Delegations, WalletDelegationV2{
+			Contract:         delegation.Contract,
+			StakingProvider:  delegation.StakingProvider,
+			APR:              Amount{Value: formatFloat(delegation.APR), Unit: UnitPercent},
+			ActiveStake:      Amount{Value: delegation.ActiveStakeInEgld, Unit: UnitEgld},
+			ClaimableRewards: Amount{Value: delegation.ClaimableRewardsInEgld, Unit: UnitEgld},
+			Unbonding:        Amount{Value: delegation.UnbondingInEgld, Unit: UnitEgld},
+		})
+	}
+	return response
+}
+
+// formatFloat returns the shortest decimal representation of the value
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func formatInt(value int) string {
+	return strconv.Itoa(value)
+}
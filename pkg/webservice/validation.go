@@ -0,0 +1,288 @@
+package webservice
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/silviutroscot/istari-vision/pkg/fetcher"
+	"github.com/silviutroscot/istari-vision/pkg/service"
+)
+
+// the codes of the validation errors; the parameters of each code are listed in the comments
+const (
+	// ValidationRequired the field is missing or empty
+	ValidationRequired = "required"
+	// ValidationNotANumber the field is not a decimal number
+	ValidationNotANumber = "not_a_number"
+	// ValidationMin the value is lower than 'min'
+	ValidationMin = "min"
+	// ValidationMax the value is greater than 'max'
+	ValidationMax = "max"
+	// ValidationGreaterThan the value is not greater than 'min'
+	ValidationGreaterThan = "greater_than"
+	// ValidationOneOf the value is not one of the comma separated 'values'
+	ValidationOneOf = "one_of"
+	// ValidationLessOrEqualField the value is greater than the value 'other' of the field 'field'
+	ValidationLessOrEqualField = "lte_field"
+	// ValidationSum the values of the comma separated 'fields' don't add up to 'sum'
+	ValidationSum = "sum"
+	// ValidationUnknownStakingProvider the staking provider 'value' is not known
+	ValidationUnknownStakingProvider = "unknown_staking_provider"
+	// ValidationUnknownStrategy the strategy 'value' is not registered
+	ValidationUnknownStrategy = "unknown_strategy"
+	// ValidationInvalidDate the value is not a date formatted as 'format'
+	ValidationInvalidDate = "invalid_date"
+)
+
+// FieldError is the error of a field of a payload, with a code and parameters the message can be localized from
+type FieldError struct {
+	// Field is the JSON name of the field
+	Field   string            `json:"field"`
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Params  map[string]string `json:"params,omitempty"`
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("failed validating field '%s': %s", e.Field, e.Message)
+}
+
+// fieldErrors returns the field errors among the errors, in the same order
+func fieldErrors(errs []error) []*FieldError {
+	var result []*FieldError
+	for _, err := range errs {
+		var fieldErr *FieldError
+		if errors.As(err, &fieldErr) {
+			result = append(result, fieldErr)
+		}
+	}
+	return result
+}
+
+// writeValidationErrors writes the response to a payload with invalid fields; the errors are listed as messages and,
+// for the frontend to localize them, as field errors
+func writeValidationErrors(c *gin.Context, errs []error) {
+	messages := make([]string, len(errs))
+	for i, err := range errs {
+		messages[i] = err.Error()
+	}
+
+	response := gin.H{
+		"errors": messages,
+	}
+	if fieldErrs := fieldErrors(errs); len(fieldErrs) != 0 {
+		response["field_errors"] = fieldErrs
+	}
+
+	c.JSON(http.StatusBadRequest, response)
+}
+
+// validator collects the errors of the fields of a payload; the rules of the fields which are skipped, e.g. because
+// their value is varied by the solver, are not checked
+type validator struct {
+	errs    []error
+	skipped map[string]bool
+}
+
+func newValidator(skippedFields ...string) *validator {
+	v := &validator{skipped: map[string]bool{}}
+	for _, field := range skippedFields {
+		v.skipped[field] = true
+	}
+	return v
+}
+
+// Errors returns the collected errors, nil if all the fields are valid
+func (v *validator) Errors() []error {
+	return v.errs
+}
+
+func (v *validator) fail(field, code, message string, params map[string]string) {
+	v.errs = append(v.errs, &FieldError{Field: field, Code: code, Message: message, Params: params})
+}
+
+// decimalRule checks a decimal value, returning the code, the message and the parameters of the error if it's invalid
+type decimalRule func(value *big.Float) (string, string, map[string]string)
+
+func minDecimal(min float64) decimalRule {
+	return func(value *big.Float) (string, string, map[string]string) {
+		if value.Cmp(big.NewFloat(min)) < 0 {
+			bound := formatFloat(min)
+			return ValidationMin, "the value must be at least " + bound, map[string]string{"min": bound}
+		}
+		return "", "", nil
+	}
+}
+
+func maxDecimal(max float64) decimalRule {
+	return func(value *big.Float) (string, string, map[string]string) {
+		if value.Cmp(big.NewFloat(max)) > 0 {
+			bound := formatFloat(max)
+			return ValidationMax, "the value must be at most " + bound, map[string]string{"max": bound}
+		}
+		return "", "", nil
+	}
+}
+
+func greaterThanDecimal(min float64) decimalRule {
+	return func(value *big.Float) (string, string, map[string]string) {
+		if value.Cmp(big.NewFloat(min)) <= 0 {
+			bound := formatFloat(min)
+			return ValidationGreaterThan, "the value must be greater than " + bound, map[string]string{"min": bound}
+		}
+		return "", "", nil
+	}
+}
+
+// Decimal returns the parsed value of the field, or nil if it is empty or invalid; an empty value is an error if the
+// field is required
+func (v *validator) Decimal(field, value string, required bool, rules ...decimalRule) *big.Float {
+	if v.skipped[field] {
+		return nil
+	}
+
+	if value == "" {
+		if required {
+			v.fail(field, ValidationRequired, "the value is required", nil)
+		}
+		return nil
+	}
+
+	parsed, err := parseBigFloat(value)
+	if err != nil {
+		v.fail(field, ValidationNotANumber, fmt.Sprintf("the value '%s' is not a decimal number", value), map[string]string{"value": value})
+		return nil
+	}
+
+	for _, rule := range rules {
+		if code, message, params := rule(parsed); code != "" {
+			v.fail(field, code, message, params)
+			return nil
+		}
+	}
+
+	return parsed
+}
+
+// IntRange checks that the value of the field is between min and max, both included
+func (v *validator) IntRange(field string, value, min, max int) bool {
+	if v.skipped[field] {
+		return true
+	}
+
+	if value < min {
+		v.fail(field, ValidationMin, fmt.Sprintf("the value must be at least %d", min), map[string]string{"min": strconv.Itoa(min)})
+		return false
+	}
+	if value > max {
+		v.fail(field, ValidationMax, fmt.Sprintf("the value must be at most %d", max), map[string]string{"max": strconv.Itoa(max)})
+		return false
+	}
+	return true
+}
+
+// LessOrEqualField checks that the value of the field is not greater than the value of the other field
+func (v *validator) LessOrEqualField(field string, value int, otherField string, otherValue int) {
+	if v.skipped[field] || v.skipped[otherField] {
+		return
+	}
+
+	if value > otherValue {
+		v.fail(field, ValidationLessOrEqualField, fmt.Sprintf("the value must be at most the value of '%s' (%d)", otherField, otherValue),
+			map[string]string{"field": otherField, "other": strconv.Itoa(otherValue)})
+	}
+}
+
+// OneOf checks that the value of the field is one of the allowed values
+func (v *validator) OneOf(field, value string, allowed ...string) bool {
+	if v.skipped[field] {
+		return true
+	}
+
+	for _, allowedValue := range allowed {
+		if value == allowedValue {
+			return true
+		}
+	}
+
+	values := strings.Join(allowed, ",")
+	v.fail(field, ValidationOneOf, fmt.Sprintf("the value '%s' must be one of %s", value, values),
+		map[string]string{"value": value, "values": values})
+	return false
+}
+
+// Required checks that the value of the field is not empty
+func (v *validator) Required(field, value string) bool {
+	if v.skipped[field] {
+		return true
+	}
+
+	if value == "" {
+		v.fail(field, ValidationRequired, "the value is required", nil)
+		return false
+	}
+	return true
+}
+
+// Date returns the parsed value of the field, formatted as YYYY-MM-DD, or the zero time if it is empty or invalid
+func (v *validator) Date(field, value string) time.Time {
+	if v.skipped[field] || !v.Required(field, value) {
+		return time.Time{}
+	}
+
+	parsed, err := time.Parse(service.DateFormat, value)
+	if err != nil {
+		v.fail(field, ValidationInvalidDate, fmt.Sprintf("the value '%s' is not a date formatted as YYYY-MM-DD", value),
+			map[string]string{"value": value, "format": "YYYY-MM-DD"})
+		return time.Time{}
+	}
+	return parsed
+}
+
+// validateStakingProvider returns a field error if the staking provider is not one of the known ones
+func validateStakingProvider(field, identity string, egldStakingProviders []fetcher.EgldStakingProvider) error {
+	for _, provider := range egldStakingProviders {
+		if provider.Identity == identity {
+			return nil
+		}
+	}
+
+	return &FieldError{
+		Field:   field,
+		Code:    ValidationUnknownStakingProvider,
+		Message: fmt.Sprintf("the staking provider '%s' is not known", identity),
+		Params:  map[string]string{"value": identity},
+	}
+}
+
+// requiredStrategyInputs returns the names of the inputs required by any of the strategies; the unknown strategies
+// are ignored, as they are reported by the calculation
+func requiredStrategyInputs(registry *service.StrategyRegistry, names []string) map[string]bool {
+	required := map[string]bool{}
+
+	strategies := registry.Strategies()
+	if len(names) != 0 {
+		strategies = strategies[:0:0]
+		for _, name := range names {
+			if strategy, ok := registry.Get(name); ok {
+				strategies = append(strategies, strategy)
+			}
+		}
+	}
+
+	for _, strategy := range strategies {
+		for _, field := range strategy.InputSchema() {
+			if field.Required {
+				required[field.Name] = true
+			}
+		}
+	}
+
+	return required
+}
@@ -1,14 +1,12 @@
 package webservice
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
-	"github.com/go-redis/redis/v8"
 	"github.com/silviutroscot/istari-vision/pkg/service"
 
 	"github.com/gin-contrib/cors"
@@ -21,9 +19,13 @@ func init() {
 
 type API struct {
 	Address string
+	// TrustedProxies are the addresses or CIDRs of the proxies whose forwarding headers are used to find the IP
+	// address of the clients; the address of the connection is used if it is empty
+	TrustedProxies []string
 
 	engine  *gin.Engine
 	service *service.Service
+	live    *liveHub
 }
 
 // NewAPI creates a new instance of a WebServer, which encapsulates the router and the dependencies of the WebService
@@ -39,30 +41,7 @@ func NewAPI(service *service.Service) *API {
 	return &API{
 		engine:  engine,
 		service: service,
-	}
-}
-
-// handleRateLimiting is a middleware that handles the rate limiting of the requests by only allowing 'limit'
-// requests to happen, for the given 'duration' starting from the first request (identified by 'ipHeader' http header)
-func handleRateLimiting(keyBase, ipHeader string, limit int64, duration time.Duration, cache *redis.Client) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		ctx, cc := context.WithTimeout(context.Background(), time.Second*5)
-		defer cc()
-
-		ip := c.GetHeader(ipHeader)
-
-		result, err := cache.Incr(ctx, keyBase+":"+ip).Result()
-		if err != nil {
-			_ = c.AbortWithError(http.StatusInternalServerError, err)
-			return
-		}
-
-		if result == 1 {
-			_ = cache.Expire(ctx, keyBase+":"+ip, duration).Err()
-		} else if result > limit {
-			_ = c.AbortWithError(http.StatusTooManyRequests, fmt.Errorf("too many requests"))
-			return
-		}
+		live:    newLiveHub(service.SubscribeMarketUpdates),
 	}
 }
 
@@ -74,20 +53,30 @@ func (api *API) Setup() error {
 		corsOrigins = []string{"*"}
 	}
 
+	if err := api.engine.SetTrustedProxies(api.TrustedProxies); err != nil {
+		return fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
 	api.engine.Use(cors.New(cors.Config{
 		AllowOrigins:     corsOrigins,
 		AllowMethods:     []string{"GET", "PUT", "PATCH", "POST", "DELETE"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Content-Length", scenarioTokenHeader, alertSecretHeader, apiKeyHeader},
+		ExposeHeaders:    []string{"Content-Length", rateLimitLimitHeader, rateLimitRemainingHeader, rateLimitResetHeader, retryAfterHeader},
 		AllowCredentials: true,
 		MaxAge:           1 * time.Minute, // todo: increase time to 12h; this represents for how long it will be cached in browser
 	}))
 
-	apiGroup := api.engine.Group("/api", handleRateLimiting("rate_limit", "X-Client-IP", 200, time.Minute, api.service.Cache))
+	apiGroup := api.engine.Group("/api", api.authenticate)
 	{
-		apiGroup.GET("/egld_staking_providers", api.HandleGetEgldStakingProviders)
-		apiGroup.GET("/prices", api.HandleGetPrices)
-		apiGroup.POST("/calculate_profit", api.HandlePostCalculateProfit)
+		for _, r := range append(api.routes(), api.routesV2()...) {
+			handlers := []gin.HandlerFunc{api.handleRateLimiting(r.RateLimit)}
+			if r.Feature != "" {
+				handlers = append(handlers, requireFeature(r.Feature))
+			}
+			apiGroup.Handle(r.Method, r.Path, append(handlers, r.Handler)...)
+		}
+		apiGroup.GET("/openapi.json", api.handleRateLimiting(0), api.HandleGetOpenAPI)
+		apiGroup.GET("/docs", api.handleRateLimiting(0), api.HandleGetDocs)
 	}
 
 	return nil
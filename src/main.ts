@@ -26,6 +26,16 @@ export interface CalculateResponse {
     }
 }
 
+export interface MarketUpdate {
+    prices: Prices;
+    mex_locked_rewards_apr: string;
+    mex_unlocked_rewards_apr: string;
+    staking_providers: {
+        [identity: string]: number;
+    };
+    updated_at: string;
+}
+
 export interface AppLogicState {
     stakeProviders: StakeProvider[];
     prices: Prices;
@@ -140,6 +150,34 @@ export class AppLogic extends HTMLElement {
             });
     }
 
+    subscribeMarketUpdates() {
+        // the prices and APRs are pushed after each refresh of the API cache, instead of being polled
+        const events = new EventSource("https://istari-api.troscot.com/api/live");
+        events.addEventListener('market', e => {
+            const update = JSON.parse((e as MessageEvent).data) as MarketUpdate;
+
+            this._state.prices = update.prices;
+            this.updatePrices();
+            this.updateStakingProvidersAPR(update.staking_providers);
+        });
+    }
+
+    updateStakingProvidersAPR(aprs: { [identity: string]: number }) {
+        const selectProviderEl = document.querySelector('select[name="egld-staking-provider"]')! as HTMLSelectElement;
+
+        this._state.stakeProviders.forEach(provider => {
+            if (aprs[provider.identity] !== undefined) {
+                provider.apr = aprs[provider.identity];
+            }
+        });
+        Array.from(selectProviderEl.options).forEach(option => {
+            const apr = aprs[option.value];
+            if (apr !== undefined) {
+                option.innerText = `apr[${apr}] - ${option.value}`;
+            }
+        });
+    }
+
     fetchSubmit(request: { [key:string]: any }) {
         const json = JSON.stringify(request);
 
@@ -263,6 +301,7 @@ export class AppLogic extends HTMLElement {
 
         this.fetchPrices();
         this.fetchStakingProviders();
+        this.subscribeMarketUpdates();
 
         const formEl = document.querySelector('form')! as HTMLFormElement;
         formEl.addEventListener('submit', (e) => {